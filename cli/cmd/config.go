@@ -0,0 +1,234 @@
+/*
+ * Copyright (c) 2026, Psiphon Inc.
+ * All rights reserved.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/Psiphon-Inc/conduit/cli/internal/config"
+	"github.com/Psiphon-Labs/psiphon-tunnel-core/psiphon"
+	"github.com/spf13/cobra"
+)
+
+var showEffective bool
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Check and inspect the Psiphon network config",
+}
+
+var configValidateCmd = &cobra.Command{
+	Use:   "validate",
+	Short: "Check the Psiphon config for problems",
+	Long: `Check the Psiphon config: JSON types, the required PropagationChannelId and
+SponsorId, broker specs, keys conduit overrides, and whether tunnel-core
+accepts the merged config. Exits with status 4 if there are errors.`,
+	Args: cobra.NoArgs,
+	RunE: runConfigValidate,
+}
+
+var configShowCmd = &cobra.Command{
+	Use:   "show",
+	Short: "Print the Psiphon config with secrets redacted",
+	Long: `Print the Psiphon config with secrets redacted. With --effective, print the
+config tunnel-core runs with, after conduit's settings are applied.`,
+	Args: cobra.NoArgs,
+	RunE: runConfigShow,
+}
+
+var configDiffCmd = &cobra.Command{
+	Use:   "diff",
+	Short: "Compare the embedded Psiphon config with a config file",
+	Args:  cobra.NoArgs,
+	RunE:  runConfigDiff,
+}
+
+func init() {
+	rootCmd.AddCommand(configCmd)
+	configCmd.AddCommand(configValidateCmd, configShowCmd, configDiffCmd)
+
+	configCmd.PersistentFlags().StringVarP(&psiphonConfigPath, "psiphon-config", "c", "", "path to Psiphon network config file (JSON)")
+	for _, cmd := range []*cobra.Command{configValidateCmd, configShowCmd} {
+		cmd.Flags().IntVarP(&maxClients, "max-clients", "m", config.DefaultMaxClients, "maximum number of proxy clients, as passed to 'conduit start'")
+		cmd.Flags().Float64VarP(&bandwidthMbps, "bandwidth", "b", config.DefaultBandwidthMbps, "total bandwidth limit in Mbps, as passed to 'conduit start'")
+	}
+	configShowCmd.Flags().BoolVar(&showEffective, "effective", false, "apply conduit's settings, as 'conduit start' would")
+}
+
+// psiphonConfigSource reads the Psiphon config and describes where it came from
+func psiphonConfigSource() (data []byte, source string, err error) {
+	path, useEmbedded, err := resolvePsiphonConfig(psiphonConfigPath)
+	if err != nil {
+		return nil, "", err
+	}
+	if useEmbedded {
+		return config.GetEmbeddedPsiphonConfig(), "embedded config", nil
+	}
+	data, err = os.ReadFile(path)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read psiphon config: %w", err)
+	}
+	return data, path, nil
+}
+
+// psiphonOverrides returns the settings 'conduit start' would apply with the
+// same flags. The station key is not loaded.
+func psiphonOverrides(cmd *cobra.Command, data []byte) (map[string]any, error) {
+	maxClientsFromFlag := 0
+	if cmd.Flags().Changed("max-clients") {
+		if maxClients < 1 {
+			return nil, fmt.Errorf("max-clients must be between 1 and %d", config.MaxClientsLimit)
+		}
+		maxClientsFromFlag = maxClients
+	}
+	clients, bandwidth, err := config.ResolveLimits(data, maxClientsFromFlag, bandwidthMbps, cmd.Flags().Changed("bandwidth"))
+	if err != nil {
+		return nil, err
+	}
+	cfg := &config.Config{
+		DataDir:                 GetDataDir(),
+		MaxClients:              clients,
+		BandwidthBytesPerSecond: bandwidth,
+		PrivateKeyBase64:        "REDACTED",
+	}
+	return cfg.PsiphonOverrides(), nil
+}
+
+func runConfigValidate(cmd *cobra.Command, args []string) error {
+	data, source, err := psiphonConfigSource()
+	if err != nil {
+		return err
+	}
+	cmd.SilenceUsage = true
+
+	var overrides map[string]any
+	if _, err := config.ParsePsiphonConfig(data); err == nil {
+		if overrides, err = psiphonOverrides(cmd, data); err != nil {
+			return err
+		}
+	}
+
+	errorCount := 0
+	for _, issue := range config.ValidatePsiphonConfig(data, overrides) {
+		tag := "[WARN]"
+		if issue.Severity == config.SeverityError {
+			tag = "[ERROR]"
+			errorCount++
+		}
+		fmt.Printf("%-7s %s\n", tag, strings.TrimSpace(issue.Key+" "+issue.Message))
+	}
+
+	// Only ask tunnel-core when our own checks pass, its errors are terse
+	if errorCount == 0 {
+		merged, err := config.MergePsiphonConfig(data, overrides)
+		if err == nil {
+			var mergedData []byte
+			if mergedData, err = json.Marshal(merged); err == nil {
+				_, err = psiphon.LoadConfig(mergedData)
+			}
+		}
+		if err != nil {
+			fmt.Printf("%-7s tunnel-core rejects the config: %v\n", "[ERROR]", err)
+			errorCount++
+		}
+	}
+
+	if errorCount > 0 {
+		return withExitCode(ExitCheckFailed, fmt.Errorf("%s: %d error(s)", source, errorCount))
+	}
+	fmt.Printf("[OK] %s is valid\n", source)
+	return nil
+}
+
+func runConfigShow(cmd *cobra.Command, args []string) error {
+	cmd.SilenceUsage = true
+	data, _, err := psiphonConfigSource()
+	if err != nil {
+		return err
+	}
+	psiphonConfig, err := config.ParsePsiphonConfig(data)
+	if err != nil {
+		return fmt.Errorf("failed to parse psiphon config: %w", err)
+	}
+	if showEffective {
+		overrides, err := psiphonOverrides(cmd, data)
+		if err != nil {
+			return err
+		}
+		for key, value := range overrides {
+			psiphonConfig[key] = value
+		}
+	}
+
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(config.RedactPsiphonConfig(psiphonConfig))
+}
+
+func runConfigDiff(cmd *cobra.Command, args []string) error {
+	cmd.SilenceUsage = true
+	if !config.HasEmbeddedConfig() {
+		return fmt.Errorf("this build has no embedded config to compare with")
+	}
+	path, useEmbedded, err := resolvePsiphonConfig(psiphonConfigPath)
+	if err != nil {
+		return err
+	}
+	if useEmbedded {
+		return fmt.Errorf("no config file to compare with: use --psiphon-config")
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read psiphon config: %w", err)
+	}
+
+	embedded, err := config.ParsePsiphonConfig(config.GetEmbeddedPsiphonConfig())
+	if err != nil {
+		return fmt.Errorf("failed to parse embedded psiphon config: %w", err)
+	}
+	file, err := config.ParsePsiphonConfig(data)
+	if err != nil {
+		return fmt.Errorf("failed to parse psiphon config file: %w", err)
+	}
+
+	changes := config.DiffPsiphonConfig(embedded, file)
+	if len(changes) == 0 {
+		fmt.Printf("%s matches the embedded config\n", path)
+		return nil
+	}
+	fmt.Printf("--- embedded config\n+++ %s\n", path)
+	for _, change := range changes {
+		if change.Old != nil {
+			fmt.Printf("- %s: %s\n", change.Key, diffValue(change.Old))
+		}
+		if change.New != nil {
+			fmt.Printf("+ %s: %s\n", change.Key, diffValue(change.New))
+		}
+	}
+	return nil
+}
+
+func diffValue(value any) string {
+	data, _ := json.Marshal(value)
+	return string(data)
+}
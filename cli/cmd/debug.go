@@ -0,0 +1,111 @@
+/*
+ * Copyright (c) 2026, Psiphon Inc.
+ * All rights reserved.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/Psiphon-Inc/conduit/cli/internal/config"
+	"github.com/Psiphon-Inc/conduit/cli/internal/debug"
+	"github.com/spf13/cobra"
+)
+
+var (
+	debugDumpAddr   string
+	debugDumpCA     string
+	debugDumpOutput string
+)
+
+var debugCmd = &cobra.Command{
+	Use:   "debug",
+	Short: "Collect diagnostics from a running station",
+}
+
+var debugDumpCmd = &cobra.Command{
+	Use:   "dump",
+	Short: "Save a diagnostics tarball for bug reports",
+	Long: `Download heap and goroutine profiles, goroutine stacks, runtime info, current
+stats, the configuration (without keys or secrets) and recent notices (with IP
+addresses redacted) from a station started with --debug-addr.
+
+If the station requires a token, set it in $` + config.DebugTokenEnv + `. Use --ca with
+the station's certificate (e.g. debug_tls.crt in its data dir) when it serves
+diagnostics over HTTPS.`,
+	Args: cobra.NoArgs,
+	RunE: runDebugDump,
+}
+
+func init() {
+	rootCmd.AddCommand(debugCmd)
+	debugCmd.AddCommand(debugDumpCmd)
+
+	debugDumpCmd.Flags().StringVar(&debugDumpAddr, "addr", debug.DefaultAddr, "--debug-addr of the running station")
+	debugDumpCmd.Flags().StringVar(&debugDumpCA, "ca", "", "connect over HTTPS, trusting this certificate")
+	debugDumpCmd.Flags().StringVarP(&debugDumpOutput, "output", "o", "", "output file (default: conduit-debug-<time>.tar.gz)")
+}
+
+func runDebugDump(cmd *cobra.Command, args []string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	client, err := debugClient(debugDumpAddr, debugDumpCA)
+	if err != nil {
+		return err
+	}
+	resp, err := client.Get(ctx, "/debug/dump")
+	if err != nil {
+		return fmt.Errorf("failed to get a dump from the station at %s (is it running with --debug-addr?): %w", debugDumpAddr, err)
+	}
+	defer resp.Body.Close()
+
+	output := debugDumpOutput
+	if output == "" {
+		output = debug.DumpName(time.Now())
+	}
+	f, err := os.OpenFile(output, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", output, err)
+	}
+	if _, err := io.Copy(f, resp.Body); err != nil {
+		f.Close()
+		os.Remove(output)
+		return fmt.Errorf("failed to download dump: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("failed to write %s: %w", output, err)
+	}
+
+	fmt.Printf("[OK] Saved diagnostics to %s\n", output)
+	return nil
+}
+
+// debugClient connects to the debug server at addr, with the token in
+// $CONDUIT_DEBUG_TOKEN, and over HTTPS when ca is set
+func debugClient(addr, ca string) (debug.Client, error) {
+	token, err := config.LookupSecret(config.DebugTokenEnv)
+	if err != nil {
+		return debug.Client{}, err
+	}
+	return debug.Client{Addr: addr, Token: token, CA: ca}, nil
+}
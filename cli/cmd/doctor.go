@@ -0,0 +1,101 @@
+/*
+ * Copyright (c) 2026, Psiphon Inc.
+ * All rights reserved.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/Psiphon-Inc/conduit/cli/internal/doctor"
+	"github.com/spf13/cobra"
+)
+
+var (
+	doctorJSON  bool
+	doctorHTTPS []string
+	doctorSTUN  []string
+)
+
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Check why a station might not get clients",
+	Long: `Check the Psiphon config, the data directory and station key, DNS, outbound
+HTTPS, the broker, the clock, and UDP and NAT type with STUN, and suggest fixes.
+Exits with status 4 if a check fails.`,
+	Args: cobra.NoArgs,
+	RunE: runDoctor,
+}
+
+func init() {
+	rootCmd.AddCommand(doctorCmd)
+
+	doctorCmd.Flags().StringVarP(&psiphonConfigPath, "psiphon-config", "c", "", "path to Psiphon network config file (JSON)")
+	doctorCmd.Flags().BoolVar(&doctorJSON, "json", false, "print results as JSON")
+	doctorCmd.Flags().StringSliceVar(&doctorHTTPS, "https", doctor.DefaultHTTPSTargets, "HTTPS URLs to test reachability and clock skew with")
+	doctorCmd.Flags().StringSliceVar(&doctorSTUN, "stun", doctor.DefaultSTUNServers, "STUN servers (host:port) to test UDP and NAT type with")
+}
+
+func runDoctor(cmd *cobra.Command, args []string) error {
+	configData, configErr := readPsiphonConfig()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+	results := doctor.Run(ctx, doctor.Options{
+		DataDir:       GetDataDir(),
+		PsiphonConfig: configData,
+		ConfigError:   configErr,
+		KeyOptions:    keyOptions(),
+		HTTPSTargets:  doctorHTTPS,
+		STUNServers:   doctorSTUN,
+	}, doctor.DefaultProbes())
+
+	failed := 0
+	for _, r := range results {
+		if r.Status == doctor.StatusFail {
+			failed++
+		}
+	}
+
+	if doctorJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(results); err != nil {
+			return err
+		}
+	} else {
+		for _, r := range results {
+			tag := "[" + strings.ToUpper(string(r.Status)) + "]"
+			fmt.Printf("%-6s %s: %s\n", tag, r.Name, r.Message)
+			if r.Fix != "" {
+				fmt.Printf("       Fix: %s\n", r.Fix)
+			}
+		}
+	}
+
+	if failed > 0 {
+		cmd.SilenceUsage = true
+		return withExitCode(ExitCheckFailed, fmt.Errorf("%d check(s) failed", failed))
+	}
+	return nil
+}
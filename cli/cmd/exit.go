@@ -0,0 +1,58 @@
+/*
+ * Copyright (c) 2026, Psiphon Inc.
+ * All rights reserved.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package cmd
+
+import (
+	"errors"
+)
+
+// Process exit codes, for scripts that need to tell failures apart
+const (
+	ExitError       = 1 // Any other error
+	ExitMissingKey  = 3 // The station has no key yet
+	ExitCheckFailed = 4 // 'conduit doctor' or 'conduit config validate' found a problem
+	ExitAborted     = 5 // A confirmation prompt was declined
+)
+
+// errAborted is returned when the user declines a confirmation prompt
+var errAborted = withExitCode(ExitAborted, errors.New("aborted"))
+
+// exitError attaches an exit code to an error returned from a command
+type exitError struct {
+	code int
+	err  error
+}
+
+func (e *exitError) Error() string { return e.err.Error() }
+func (e *exitError) Unwrap() error { return e.err }
+
+// withExitCode returns err with a specific process exit code
+func withExitCode(code int, err error) error {
+	return &exitError{code: code, err: err}
+}
+
+// ExitCode returns the process exit code for an error returned by Execute
+func ExitCode(err error) int {
+	var e *exitError
+	if errors.As(err, &e) {
+		return e.code
+	}
+	return ExitError
+}
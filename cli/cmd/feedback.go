@@ -0,0 +1,197 @@
+/*
+ * Copyright (c) 2026, Psiphon Inc.
+ * All rights reserved.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/Psiphon-Inc/conduit/cli/internal/config"
+	"github.com/Psiphon-Inc/conduit/cli/internal/debug"
+	"github.com/Psiphon-Inc/conduit/cli/internal/feedback"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+var (
+	feedbackMessage   string
+	feedbackUpload    bool
+	feedbackPrint     bool
+	feedbackOutput    string
+	feedbackDebugAddr string
+	feedbackDebugCA   string
+	feedbackStatsFile string
+	feedbackHistory   string
+)
+
+var feedbackCmd = &cobra.Command{
+	Use:   "feedback",
+	Short: "Send diagnostics to Psiphon, or save them encrypted",
+	Long: `Collect a diagnostics report: recent notices, the configuration in effect
+(without keys or secrets), version and build info, stats and stats history,
+and system info. IP addresses are redacted.
+
+Notices and the configuration come from a station started with --debug-addr.
+By default the report is saved to a bundle encrypted with a passphrase
+($` + feedback.PassphraseEnv + ` or prompted). With --upload it is sent to
+Psiphon, encrypted to Psiphon's key, like feedback from the Conduit app.`,
+	Args: cobra.NoArgs,
+	RunE: runFeedback,
+}
+
+var feedbackOpenCmd = &cobra.Command{
+	Use:   "open <bundle>",
+	Short: "Decrypt a saved feedback bundle to stdout",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runFeedbackOpen,
+}
+
+func init() {
+	rootCmd.AddCommand(feedbackCmd)
+	feedbackCmd.AddCommand(feedbackOpenCmd)
+
+	feedbackCmd.Flags().StringVarP(&feedbackMessage, "message", "m", "", "describe the problem")
+	feedbackCmd.Flags().BoolVar(&feedbackUpload, "upload", false, "upload the report to Psiphon instead of saving it")
+	feedbackCmd.Flags().BoolVar(&feedbackPrint, "print", false, "print the report to stdout to review it, without saving or uploading")
+	feedbackCmd.Flags().StringVarP(&feedbackOutput, "output", "o", "", "bundle file (default: conduit-feedback-<id>.json)")
+	feedbackCmd.Flags().StringVar(&feedbackDebugAddr, "debug-addr", debug.DefaultAddr, "--debug-addr of the running station")
+	feedbackCmd.Flags().StringVar(&feedbackDebugCA, "debug-ca", "", "connect to the debug server over HTTPS, trusting this certificate")
+	feedbackCmd.Flags().StringVarP(&feedbackStatsFile, "stats-file", "s", "stats.json", "--stats-file of the station, read when it isn't reachable (relative to the data dir)")
+	feedbackCmd.Flags().StringVar(&feedbackHistory, "stats-history", "", "stats history file to include (relative to the data dir)")
+	feedbackCmd.Flags().StringVarP(&psiphonConfigPath, "psiphon-config", "c", "", "path to Psiphon network config file (JSON), for --upload")
+}
+
+func runFeedback(cmd *cobra.Command, args []string) error {
+	if feedbackUpload && feedbackPrint {
+		return fmt.Errorf("--upload and --print can't be used together")
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	client, err := debugClient(feedbackDebugAddr, feedbackDebugCA)
+	if err != nil {
+		return err
+	}
+	report, err := feedback.Collect(ctx, feedback.Sources{
+		Version:      version,
+		Message:      feedbackMessage,
+		DataDir:      GetDataDir(),
+		Debug:        client,
+		StatsFile:    resolveStatsPath(feedbackStatsFile),
+		StatsHistory: resolveStatsPath(feedbackHistory),
+	})
+	if err != nil {
+		return err
+	}
+	for _, e := range report.DiagnosticInfo.CollectionErrors {
+		fmt.Fprintf(os.Stderr, "[WARN] %s\n", e)
+	}
+	data, err := report.Marshal()
+	if err != nil {
+		return fmt.Errorf("failed to encode report: %w", err)
+	}
+
+	switch {
+	case feedbackPrint:
+		fmt.Println(string(data))
+		return nil
+
+	case feedbackUpload:
+		configData, err := readPsiphonConfig()
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Uploading report %s (%d bytes)...\n", report.Metadata.ID, len(data))
+		if err := feedback.PsiphonUploader(configData)(ctx, data); err != nil {
+			return fmt.Errorf("failed to upload feedback: %w", err)
+		}
+		fmt.Printf("[OK] Sent report %s to Psiphon\n", report.Metadata.ID)
+		return nil
+	}
+
+	passphrase, err := feedbackPassphrase(true)
+	if err != nil {
+		return err
+	}
+	sealed, err := feedback.Seal(data, report.Metadata.ID, passphrase)
+	if err != nil {
+		return fmt.Errorf("failed to encrypt report: %w", err)
+	}
+	output := feedbackOutput
+	if output == "" {
+		output = "conduit-feedback-" + report.Metadata.ID + ".json"
+	}
+	if err := os.WriteFile(output, sealed, 0600); err != nil {
+		return fmt.Errorf("failed to write %s: %w", output, err)
+	}
+	fmt.Printf("[OK] Saved encrypted report to %s (read it with 'conduit feedback open')\n", output)
+	return nil
+}
+
+func runFeedbackOpen(cmd *cobra.Command, args []string) error {
+	data, err := os.ReadFile(args[0])
+	if err != nil {
+		return err
+	}
+	passphrase, err := feedbackPassphrase(false)
+	if err != nil {
+		return err
+	}
+	report, err := feedback.Open(data, passphrase)
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(report))
+	return nil
+}
+
+// feedbackPassphrase returns the bundle passphrase from the environment, or
+// prompts for it (twice for a new bundle)
+func feedbackPassphrase(isNew bool) ([]byte, error) {
+	passphrase, err := config.LookupSecret(feedback.PassphraseEnv)
+	if err != nil || passphrase != "" {
+		return []byte(passphrase), err
+	}
+	if !term.IsTerminal(int(os.Stdin.Fd())) {
+		return nil, fmt.Errorf("no terminal to prompt for a passphrase: set %s or %s_FILE", feedback.PassphraseEnv, feedback.PassphraseEnv)
+	}
+	if isNew {
+		return readNewPassphrase("New bundle passphrase: ")
+	}
+	return readPassphrase("Bundle passphrase: ")
+}
+
+// readPsiphonConfig reads the psiphon config the station would use
+func readPsiphonConfig() ([]byte, error) {
+	path, useEmbedded, err := resolvePsiphonConfig(psiphonConfigPath)
+	if err != nil {
+		return nil, err
+	}
+	if useEmbedded {
+		return config.GetEmbeddedPsiphonConfig(), nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read psiphon config: %w", err)
+	}
+	return data, nil
+}
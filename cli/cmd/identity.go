@@ -0,0 +1,139 @@
+/*
+ * Copyright (c) 2026, Psiphon Inc.
+ * All rights reserved.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image/png"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/Psiphon-Inc/conduit/cli/internal/config"
+	"github.com/Psiphon-Inc/conduit/cli/internal/crypto"
+	"github.com/Psiphon-Inc/conduit/cli/internal/identicon"
+	"github.com/spf13/cobra"
+)
+
+var (
+	identitySetName string
+	identityPNG     string
+	identitySVG     string
+	identitySize    int
+	identityNoIcon  bool
+)
+
+var identityCmd = &cobra.Command{
+	Use:   "identity",
+	Short: "Show the station name, proxy ID and identicon",
+	Long: `Show the station name, proxy ID and identicon, as displayed by the Conduit app.
+
+The station name is cosmetic and stored in the data directory. It is used by
+ryve-claim and included in stats and metrics.
+
+Examples:
+  conduit identity --set-name berlin-1
+  conduit identity --png identicon.png --size 512`,
+	RunE: runIdentity,
+}
+
+func init() {
+	rootCmd.AddCommand(identityCmd)
+
+	identityCmd.Flags().StringVar(&identitySetName, "set-name", "", fmt.Sprintf("save the station name (at most %d characters, empty to clear)", config.MaxStationNameLength))
+	identityCmd.Flags().StringVar(&identityPNG, "png", "", "write the identicon badge as a PNG ('-' for stdout)")
+	identityCmd.Flags().StringVar(&identitySVG, "svg", "", "write the identicon as an SVG, as generated by the app ('-' for stdout)")
+	identityCmd.Flags().IntVar(&identitySize, "size", 256, "PNG and SVG size in pixels")
+	identityCmd.Flags().BoolVar(&identityNoIcon, "no-icon", false, "don't draw the identicon in the terminal")
+}
+
+func runIdentity(cmd *cobra.Command, args []string) error {
+	dataDir := GetDataDir()
+
+	setName := cmd.Flags().Changed("set-name")
+	if setName {
+		if err := config.ValidateStationName(strings.TrimSpace(identitySetName)); err != nil {
+			return err
+		}
+	}
+	if identitySize < 16 || identitySize > 4096 {
+		return fmt.Errorf("size must be between 16 and 4096")
+	}
+
+	kp, _, err := config.LoadKey(dataDir, keyOptions())
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return withExitCode(ExitMissingKey, fmt.Errorf("no key found in %s: start your station first to create a key", dataDir))
+		}
+		return fmt.Errorf("failed to load key: %w", err)
+	}
+	proxyID, err := crypto.KeyPairToCurve25519Base64(kp)
+	if err != nil {
+		return fmt.Errorf("failed to derive proxy id: %w", err)
+	}
+
+	// Save the name only once the invocation is known to be valid
+	if setName {
+		if err := config.SaveStationName(dataDir, identitySetName); err != nil {
+			return err
+		}
+	}
+	name, err := config.LoadStationName(dataDir)
+	if err != nil {
+		return err
+	}
+
+	// The app draws identicons from the proxy ID
+	if identityPNG != "" {
+		var buf bytes.Buffer
+		if err := png.Encode(&buf, identicon.Badge(proxyID, identitySize)); err != nil {
+			return fmt.Errorf("failed to encode PNG: %w", err)
+		}
+		if err := writePrivateFile(identityPNG, buf.Bytes()); err != nil {
+			return fmt.Errorf("failed to write PNG: %w", err)
+		}
+	}
+	if identitySVG != "" {
+		svg := identicon.New(proxyID, identitySize).SVG()
+		if err := writePrivateFile(identitySVG, []byte(svg+"\n")); err != nil {
+			return fmt.Errorf("failed to write SVG: %w", err)
+		}
+	}
+	if identityPNG == "-" || identitySVG == "-" {
+		return nil
+	}
+
+	displayName := name
+	if displayName == "" {
+		displayName = "(not set, use --set-name)"
+	}
+	writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(writer, "Station Name:\t%s\n", displayName)
+	fmt.Fprintf(writer, "Proxy ID:\t%s\n", proxyID)
+	writer.Flush()
+
+	if !identityNoIcon && isTerminal(os.Stdout) {
+		fmt.Println()
+		fmt.Print(identicon.Terminal(proxyID, 16))
+	}
+	return nil
+}
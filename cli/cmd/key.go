@@ -0,0 +1,465 @@
+/*
+ * Copyright (c) 2026, Psiphon Inc.
+ * All rights reserved.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"text/tabwriter"
+
+	"github.com/Psiphon-Inc/conduit/cli/internal/config"
+	"github.com/Psiphon-Inc/conduit/cli/internal/crypto"
+	"github.com/spf13/cobra"
+)
+
+var (
+	keyExportFormat      string
+	keyImportPhrase      string
+	keyImportPrivate     string
+	keyImportPath        string
+	keyForce             bool
+	keyYes               bool
+	keyNewPassphraseFile string
+
+	deriveMnemonicFile string
+	deriveIndex        uint32
+	deriveCount        int
+	deriveName         string
+	deriveOutDir       string
+	deriveManifest     string
+	deriveManifestOnly bool
+)
+
+var keyCmd = &cobra.Command{
+	Use:   "key",
+	Short: "Manage the station key",
+	Long: `Manage the station key stored in the data directory (conduit_key.json).
+
+The Psiphon broker tracks proxy reputation by key, so moving a station to a
+new host means moving its key. Use 'key export' on the old host and
+'key import' on the new one.`,
+}
+
+var keyShowCmd = &cobra.Command{
+	Use:   "show",
+	Short: "Show the station public key and proxy ID",
+	RunE:  runKeyShow,
+}
+
+var keyExportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export the station key as a mnemonic or base64 private key",
+	RunE:  runKeyExport,
+}
+
+var keyImportCmd = &cobra.Command{
+	Use:   "import",
+	Short: "Import a station key from a mnemonic or base64 private key",
+	Long: `Import a station key from a BIP-39 mnemonic or a base64 private key.
+
+Pass '-' to read the value from stdin, which keeps it out of your shell history:
+  conduit key import --mnemonic - < mnemonic.txt
+
+If a different key already exists it is kept unless --force is given, in which
+case it is backed up next to the new key first.`,
+	RunE: runKeyImport,
+}
+
+var keyRotateCmd = &cobra.Command{
+	Use:   "rotate",
+	Short: "Replace the station key with a new one, backing up the old key",
+	RunE:  runKeyRotate,
+}
+
+var keyEncryptCmd = &cobra.Command{
+	Use:   "encrypt",
+	Short: "Encrypt the station key at rest with a passphrase",
+	Long: `Encrypt conduit_key.json with a passphrase (scrypt + XChaCha20-Poly1305).
+Running it on an already encrypted key changes the passphrase.
+
+The new passphrase is read from --new-passphrase-file, or from
+` + config.PassphraseEnv + ` / ` + config.PassphraseFileEnv + ` when encrypting a plaintext key,
+or prompted for on the terminal.
+
+To start an encrypted station, provide the passphrase with one of:
+  - the ` + config.PassphraseEnv + ` environment variable
+  - a file named by ` + config.PassphraseFileEnv + `
+  - the systemd credential conduit-key-passphrase (LoadCredential=)
+  - the interactive prompt, when running in a terminal
+
+Note: older Conduit versions can't read encrypted keys.`,
+	RunE: runKeyEncrypt,
+}
+
+var keyDecryptCmd = &cobra.Command{
+	Use:   "decrypt",
+	Short: "Store the station key without encryption",
+	RunE:  runKeyDecrypt,
+}
+
+var keyDeriveCmd = &cobra.Command{
+	Use:   "derive",
+	Short: "Derive station keys for a fleet from one mnemonic",
+	Long: `Deterministically derive station keys from a single fleet mnemonic, so the
+whole fleet can be recovered from one offline backup.
+
+Station N uses derivation path m/N. The fleet mnemonic itself is never written
+to a station's key file.
+
+Examples:
+  # On a station: install key #3 into its data directory
+  conduit key derive --mnemonic-file - --index 3 --name berlin-1
+
+  # Offline: derive keys for stations 0-9 into per-station directories
+  conduit key derive --mnemonic-file fleet.txt --count 10 --out-dir ./fleet --manifest ./fleet/manifest.json
+
+  # Just record proxy IDs, without writing any keys
+  conduit key derive --mnemonic-file fleet.txt --count 10 --manifest manifest.json --manifest-only
+
+A station can later be rebuilt with:
+  conduit start --restore-mnemonic-file fleet.txt --derivation-path m/3`,
+	RunE: runKeyDerive,
+}
+
+var keyVerifyCmd = &cobra.Command{
+	Use:   "verify",
+	Short: "Check the integrity of the station key file",
+	RunE:  runKeyVerify,
+}
+
+func init() {
+	rootCmd.AddCommand(keyCmd)
+	keyCmd.AddCommand(keyShowCmd, keyExportCmd, keyImportCmd, keyRotateCmd, keyEncryptCmd, keyDecryptCmd, keyDeriveCmd, keyVerifyCmd)
+
+	keyExportCmd.Flags().StringVarP(&keyExportFormat, "format", "f", "mnemonic", "export format: mnemonic or base64")
+	keyExportCmd.Flags().BoolVarP(&keyYes, "yes", "y", false, "skip the confirmation prompt")
+
+	keyImportCmd.Flags().StringVar(&keyImportPhrase, "mnemonic", "", "24-word mnemonic to import ('-' to read from stdin)")
+	keyImportCmd.Flags().StringVar(&keyImportPrivate, "private-key", "", "base64 private key to import ('-' to read from stdin)")
+	keyImportCmd.Flags().StringVar(&keyImportPath, "derivation-path", "", "derivation path used with --mnemonic (default: none, as for keys created by 'conduit start')")
+	keyImportCmd.Flags().BoolVar(&keyForce, "force", false, "replace a different existing key (the old key is backed up)")
+	keyImportCmd.MarkFlagsMutuallyExclusive("mnemonic", "private-key")
+	keyImportCmd.MarkFlagsOneRequired("mnemonic", "private-key")
+
+	keyRotateCmd.Flags().BoolVarP(&keyYes, "yes", "y", false, "skip the confirmation prompt")
+
+	keyDeriveCmd.Flags().StringVar(&deriveMnemonicFile, "mnemonic-file", "", "file holding the fleet mnemonic ('-' for stdin)")
+	keyDeriveCmd.Flags().Uint32Var(&deriveIndex, "index", 0, "index of the (first) station to derive")
+	keyDeriveCmd.Flags().IntVar(&deriveCount, "count", 1, "number of consecutive stations to derive")
+	keyDeriveCmd.Flags().StringVar(&deriveName, "name", "", "station name recorded in the manifest (suffixed with the index when --count > 1)")
+	keyDeriveCmd.Flags().StringVar(&deriveOutDir, "out-dir", "", "write keys to <out-dir>/station-<index> instead of the data directory")
+	keyDeriveCmd.Flags().StringVar(&deriveManifest, "manifest", "", "manifest file to create or update")
+	keyDeriveCmd.Flags().BoolVar(&deriveManifestOnly, "manifest-only", false, "only update the manifest, don't write any keys")
+	keyDeriveCmd.Flags().BoolVar(&keyForce, "force", false, "replace different existing keys (old keys are backed up)")
+	keyDeriveCmd.MarkFlagRequired("mnemonic-file")
+
+	keyEncryptCmd.Flags().StringVar(&keyNewPassphraseFile, "new-passphrase-file", "", "read the new passphrase from this file ('-' for stdin)")
+}
+
+// readKey loads the station key, with a friendly error if it doesn't exist yet
+func readKey(keyOpts config.KeyOptions) (*config.KeyInfo, error) {
+	info, err := config.ReadKey(GetDataDir(), keyOpts)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, withExitCode(ExitMissingKey, fmt.Errorf("no key found in %s: start your station first to create a key", GetDataDir()))
+		}
+		return nil, err
+	}
+	return info, nil
+}
+
+// printKeySummary prints the public identity of a key
+func printKeySummary(info *config.KeyInfo) error {
+	proxyID, err := crypto.KeyPairToCurve25519Base64(info.KeyPair)
+	if err != nil {
+		return fmt.Errorf("failed to derive proxy id: %w", err)
+	}
+	yesNo := func(b bool) string {
+		if b {
+			return "yes"
+		}
+		return "no"
+	}
+
+	writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(writer, "Key File:\t%s\n", info.Path)
+	fmt.Fprintf(writer, "Public Key:\t%s\n", crypto.PublicKeyBase64(info.KeyPair))
+	fmt.Fprintf(writer, "Proxy ID:\t%s\n", proxyID)
+	fmt.Fprintf(writer, "Mnemonic Backup:\t%s\n", yesNo(info.Mnemonic != ""))
+	if info.DerivationPath != "" {
+		fmt.Fprintf(writer, "Derivation Path:\t%s\n", info.DerivationPath)
+	}
+	fmt.Fprintf(writer, "Encrypted:\t%s\n", yesNo(info.Encrypted))
+	return writer.Flush()
+}
+
+func runKeyShow(cmd *cobra.Command, args []string) error {
+	info, err := readKey(keyOptions())
+	if err != nil {
+		return err
+	}
+	return printKeySummary(info)
+}
+
+func runKeyExport(cmd *cobra.Command, args []string) error {
+	if keyExportFormat != "mnemonic" && keyExportFormat != "base64" {
+		return fmt.Errorf("invalid format %q: use mnemonic or base64", keyExportFormat)
+	}
+
+	info, err := readKey(keyOptions())
+	if err != nil {
+		return err
+	}
+	if keyExportFormat == "mnemonic" && info.Mnemonic == "" {
+		return fmt.Errorf("this key was imported without a mnemonic, use --format base64")
+	}
+
+	if !keyYes {
+		ok, err := confirm("This will print your station's private key. Anyone with it can impersonate your station. Continue?")
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return errAborted
+		}
+	}
+
+	if keyExportFormat == "mnemonic" {
+		fmt.Println(info.Mnemonic)
+		return nil
+	}
+	encoded, err := crypto.KeyPairToBase64NoPad(info.KeyPair)
+	if err != nil {
+		return fmt.Errorf("failed to encode key: %w", err)
+	}
+	fmt.Println(encoded)
+	return nil
+}
+
+func runKeyImport(cmd *cobra.Command, args []string) error {
+	value, source := keyImportPhrase, "mnemonic"
+	if keyImportPrivate != "" {
+		value, source = keyImportPrivate, "private key"
+	}
+	if value == "-" {
+		if isTerminal(os.Stdin) {
+			fmt.Fprintf(os.Stderr, "Enter %s (input is hidden): ", source)
+		}
+		var err error
+		if value, err = readSecretInput("-"); err != nil {
+			return err
+		}
+	}
+
+	var info *config.KeyInfo
+	var backupPath string
+	var err error
+	if keyImportPrivate != "" {
+		info, backupPath, err = config.ImportPrivateKey(GetDataDir(), value, keyForce, keyOptions())
+	} else {
+		info, backupPath, err = config.ImportMnemonic(GetDataDir(), value, keyImportPath, keyForce, keyOptions())
+	}
+	if errors.Is(err, config.ErrKeyExists) {
+		return fmt.Errorf("%w in %s: use --force to replace it (the old key will be backed up)", err, GetDataDir())
+	}
+	if err != nil {
+		return fmt.Errorf("failed to import key: %w", err)
+	}
+
+	if backupPath != "" {
+		fmt.Printf("Previous key backed up to %s\n", backupPath)
+	}
+	fmt.Println("Key imported.")
+	return printKeySummary(info)
+}
+
+func runKeyRotate(cmd *cobra.Command, args []string) error {
+	keyOpts := keyOptions()
+	if _, err := readKey(keyOpts); err != nil {
+		return err
+	}
+
+	if !keyYes {
+		ok, err := confirm("Rotating the key resets your station's broker reputation. Continue?")
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return errAborted
+		}
+	}
+
+	info, backupPath, err := config.RotateKey(GetDataDir(), keyOpts)
+	if err != nil {
+		return fmt.Errorf("failed to rotate key: %w", err)
+	}
+
+	fmt.Printf("Previous key backed up to %s\n", backupPath)
+	fmt.Println("New key created. Restart your station to use it.")
+	return printKeySummary(info)
+}
+
+func runKeyEncrypt(cmd *cobra.Command, args []string) error {
+	keyOpts := keyOptions()
+	encrypted, err := config.IsKeyEncrypted(GetDataDir())
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return withExitCode(ExitMissingKey, fmt.Errorf("no key found in %s: start your station first to create a key", GetDataDir()))
+		}
+		return err
+	}
+
+	configured, ok, err := config.LookupPassphrase()
+	if err != nil {
+		return err
+	}
+
+	var newPassphrase []byte
+	if keyNewPassphraseFile != "" {
+		value, err := readSecretInput(keyNewPassphraseFile)
+		if err != nil {
+			return fmt.Errorf("failed to read new passphrase: %w", err)
+		}
+		newPassphrase = []byte(value)
+	} else if ok && !encrypted {
+		newPassphrase = configured
+	} else {
+		if newPassphrase, err = readNewPassphrase("New key passphrase: "); err != nil {
+			return err
+		}
+	}
+
+	info, err := config.EncryptKey(GetDataDir(), keyOpts, newPassphrase)
+	if err != nil {
+		return fmt.Errorf("failed to encrypt key: %w", err)
+	}
+	if encrypted {
+		fmt.Println("Key passphrase changed.")
+	} else {
+		fmt.Println("Key encrypted.")
+	}
+	return printKeySummary(info)
+}
+
+func runKeyDecrypt(cmd *cobra.Command, args []string) error {
+	info, err := config.DecryptKey(GetDataDir(), keyOptions())
+	if err != nil {
+		return fmt.Errorf("failed to decrypt key: %w", err)
+	}
+	fmt.Println("Key is now stored without encryption.")
+	return printKeySummary(info)
+}
+
+func runKeyDerive(cmd *cobra.Command, args []string) error {
+	if deriveCount < 1 {
+		return fmt.Errorf("count must be at least 1")
+	}
+	if deriveCount > 1 && deriveOutDir == "" && !deriveManifestOnly {
+		return fmt.Errorf("--count > 1 requires --out-dir or --manifest-only")
+	}
+	if deriveManifestOnly && deriveManifest == "" {
+		return fmt.Errorf("--manifest-only requires --manifest")
+	}
+
+	if deriveMnemonicFile == "-" && isTerminal(os.Stdin) {
+		fmt.Fprint(os.Stderr, "Enter the fleet mnemonic: ")
+	}
+	mnemonic, err := readSecretInput(deriveMnemonicFile)
+	if err != nil {
+		return fmt.Errorf("failed to read mnemonic: %w", err)
+	}
+
+	var manifest *config.Manifest
+	if deriveManifest != "" {
+		if manifest, err = config.LoadManifest(deriveManifest); err != nil {
+			return err
+		}
+	}
+
+	keyOpts := keyOptions()
+	writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(writer, "INDEX\tPATH\tNAME\tPROXY ID\tKEY FILE")
+
+	for i := 0; i < deriveCount; i++ {
+		index := deriveIndex + uint32(i)
+		stationName := deriveName
+		if deriveCount > 1 && deriveName != "" {
+			stationName = fmt.Sprintf("%s-%d", deriveName, index)
+		}
+
+		var entry config.ManifestEntry
+		keyFile := "-"
+		if deriveManifestOnly {
+			if _, entry, err = config.DeriveStation(mnemonic, index, stationName); err != nil {
+				return err
+			}
+		} else {
+			stationDir := GetDataDir()
+			if deriveOutDir != "" {
+				stationDir = filepath.Join(deriveOutDir, fmt.Sprintf("station-%d", index))
+			}
+			var backupPath string
+			entry, backupPath, err = config.WriteStationKey(stationDir, mnemonic, index, stationName, keyForce, keyOpts)
+			if errors.Is(err, config.ErrKeyExists) {
+				return fmt.Errorf("station %d: %w in %s: use --force to replace it (the old key will be backed up)", index, err, stationDir)
+			}
+			if err != nil {
+				return fmt.Errorf("station %d: %w", index, err)
+			}
+			if backupPath != "" {
+				fmt.Fprintf(os.Stderr, "Station %d: previous key backed up to %s\n", index, backupPath)
+			}
+			keyFile = config.KeyFilePath(stationDir)
+		}
+
+		if manifest != nil {
+			if err := manifest.Upsert(entry); err != nil {
+				return err
+			}
+		}
+		fmt.Fprintf(writer, "%d\t%s\t%s\t%s\t%s\n", entry.Index, entry.DerivationPath, entry.Name, entry.ProxyID, keyFile)
+	}
+	writer.Flush()
+
+	if manifest != nil {
+		if err := manifest.Save(deriveManifest); err != nil {
+			return err
+		}
+		fmt.Printf("Manifest written to %s\n", deriveManifest)
+	}
+	return nil
+}
+
+func runKeyVerify(cmd *cobra.Command, args []string) error {
+	info, err := config.VerifyKey(GetDataDir(), keyOptions())
+	if err != nil {
+		return fmt.Errorf("key verification failed: %w", err)
+	}
+	fmt.Println("[OK] Key file is valid")
+	return printKeySummary(info)
+}
+
+// isTerminal reports whether f is an interactive terminal
+func isTerminal(f *os.File) bool {
+	fi, err := f.Stat()
+	return err == nil && fi.Mode()&os.ModeCharDevice != 0
+}
@@ -0,0 +1,131 @@
+/*
+ * Copyright (c) 2026, Psiphon Inc.
+ * All rights reserved.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package cmd
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/Psiphon-Inc/conduit/cli/internal/config"
+	"golang.org/x/term"
+)
+
+var stdinReader = bufio.NewReader(os.Stdin)
+
+// confirm asks a y/n question on stdin and reports whether the answer was yes
+func confirm(prompt string) (bool, error) {
+	fmt.Fprint(os.Stderr, prompt+" (y/n) ")
+	response, err := stdinReader.ReadString('\n')
+	if err != nil {
+		return false, fmt.Errorf("failed to read confirmation: %w", err)
+	}
+	response = strings.TrimSpace(strings.ToLower(response))
+	return response == "y" || response == "yes", nil
+}
+
+// readSecretInput reads a value from a file, or from stdin when path is "-".
+// A value typed on the terminal is not echoed.
+func readSecretInput(path string) (string, error) {
+	if path == "-" && term.IsTerminal(int(os.Stdin.Fd())) {
+		data, err := term.ReadPassword(int(os.Stdin.Fd()))
+		fmt.Fprintln(os.Stderr)
+		if err != nil {
+			return "", fmt.Errorf("failed to read from terminal: %w", err)
+		}
+		return strings.TrimSpace(string(data)), nil
+	}
+	if path == "-" {
+		data, err := stdinReader.ReadString('\n')
+		if err != nil && data == "" {
+			return "", fmt.Errorf("failed to read from stdin: %w", err)
+		}
+		return strings.TrimSpace(data), nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(data)), nil
+}
+
+// readPassphrase prompts for a passphrase on the terminal without echoing it
+func readPassphrase(prompt string) ([]byte, error) {
+	if !term.IsTerminal(int(os.Stdin.Fd())) {
+		return nil, fmt.Errorf("no terminal to prompt for a passphrase: set %s or %s", config.PassphraseEnv, config.PassphraseFileEnv)
+	}
+	fmt.Fprint(os.Stderr, prompt)
+	passphrase, err := term.ReadPassword(int(os.Stdin.Fd()))
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read passphrase: %w", err)
+	}
+	return passphrase, nil
+}
+
+// readNewPassphrase prompts twice for a new passphrase
+func readNewPassphrase(prompt string) ([]byte, error) {
+	passphrase, err := readPassphrase(prompt)
+	if err != nil {
+		return nil, err
+	}
+	if len(passphrase) == 0 {
+		return nil, errors.New("passphrase is empty")
+	}
+	again, err := readPassphrase("Repeat passphrase: ")
+	if err != nil {
+		return nil, err
+	}
+	if string(passphrase) != string(again) {
+		return nil, errors.New("passphrases do not match")
+	}
+	return passphrase, nil
+}
+
+// keyOptions returns the options for reading and writing the station key.
+// The passphrase for an encrypted key comes from the environment or a systemd
+// credential, and is otherwise prompted for on the terminal. New keys are only
+// encrypted when a passphrase is configured non-interactively.
+func keyOptions() config.KeyOptions {
+	configured, isConfigured, lookupErr := config.LookupPassphrase()
+	var prompted []byte
+
+	return config.KeyOptions{
+		Passphrase: func() ([]byte, error) {
+			if lookupErr != nil {
+				return nil, lookupErr
+			}
+			if isConfigured {
+				return configured, nil
+			}
+			if prompted == nil {
+				passphrase, err := readPassphrase("Key passphrase: ")
+				if err != nil {
+					return nil, err
+				}
+				prompted = passphrase
+			}
+			return prompted, nil
+		},
+		EncryptNew: isConfigured,
+	}
+}
@@ -23,6 +23,7 @@ import (
 	"fmt"
 	"os"
 
+	"github.com/Psiphon-Inc/conduit/cli/internal/update"
 	"github.com/spf13/cobra"
 )
 
@@ -42,8 +43,18 @@ Run 'conduit start' to begin relaying traffic.`,
 	Version: version,
 }
 
+// restartExecutable is set when the command should be replaced by an
+// updated binary once it has returned and cleaned up
+var restartExecutable string
+
 func Execute() error {
-	return rootCmd.Execute()
+	if err := rootCmd.Execute(); err != nil {
+		return err
+	}
+	if restartExecutable != "" {
+		return update.Restart(restartExecutable, os.Args)
+	}
+	return nil
 }
 
 func init() {
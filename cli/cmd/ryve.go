@@ -1,37 +1,68 @@
 package cmd
 
 import (
-	"bufio"
-	"encoding/base64"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"text/tabwriter"
+	"time"
 
 	"github.com/Psiphon-Inc/conduit/cli/internal/config"
 	"github.com/Psiphon-Inc/conduit/cli/internal/crypto"
-
-	"github.com/skip2/go-qrcode"
+	"github.com/Psiphon-Inc/conduit/cli/internal/ryve"
 	"github.com/spf13/cobra"
 )
 
 var ryveClaimCmd = &cobra.Command{
 	Use:   "ryve-claim",
 	Short: "Output Conduit claim data for Ryve",
-	Long:  `Show Ryve Claim Qr-code in both terminal and PNG format.`,
-	RunE:  runRyveClaim,
+	Long: `Show Ryve Claim Qr-code in both terminal and PNG format.
+
+With --signed, the claim contains only the station public key, name and a
+timestamp, signed with the station key, so the private key is never revealed.
+Without it, the claim contains the station private key.
+
+For scripts, --format prints only the claim as a URI, JSON, a PNG or SVG
+image, or a terminal QR code, to --output (default: stdout). Files are written
+with 0600 permissions. Exit status is 3 when the station has no key yet.
+
+Examples:
+  conduit ryve-claim --signed --format uri
+  conduit ryve-claim --yes --format png --output claim.png
+  for d in /srv/conduit/*; do conduit ryve-claim -d "$d" --signed --format json; done`,
+	RunE: runRyveClaim,
+	// Keep stderr clean for scripts: main prints the error once
+	SilenceUsage:  true,
+	SilenceErrors: true,
 }
 
 var (
 	name                    string
-	pngOutput               string
+	claimOutput             string
+	claimFormat             string
+	claimYes                bool
+	signedClaim             bool
+	claimChallenge          string
 	defaultName             string
 	defaultNameFromHostname bool
 )
 
+// claimFormats are the values accepted by ryve-claim --format
+var claimFormats = []string{"uri", "json", "png", "svg", "ansi"}
+
+// claimJSON is the output of ryve-claim --format json
+type claimJSON struct {
+	Name      string `json:"name"`
+	ProxyID   string `json:"proxyId"`
+	PublicKey string `json:"publicKey"`
+	Signed    bool   `json:"signed"`
+	URI       string `json:"uri"`
+}
+
 func init() {
 	defaultName = "unnamed"
 	defaultNameFromHostname = false
@@ -44,60 +75,46 @@ func init() {
 	rootCmd.AddCommand(ryveClaimCmd)
 
 	ryveClaimCmd.Flags().StringVarP(&name, "name", "n", defaultName, "Name for Ryve association")
-	ryveClaimCmd.Flags().StringVarP(&pngOutput, "output", "o", "", "PNG output file path (optional)")
+	ryveClaimCmd.Flags().StringVarP(&claimOutput, "output", "o", "", "output file path, '-' for stdout (optional)")
+	ryveClaimCmd.Flags().StringVarP(&claimFormat, "format", "f", "", "print only the claim: "+strings.Join(claimFormats, ", "))
+	ryveClaimCmd.Flags().BoolVarP(&claimYes, "yes", "y", false, "skip the confirmation prompt")
+	ryveClaimCmd.Flags().BoolVar(&signedClaim, "signed", false, "create a signed claim that doesn't reveal the private key")
+	ryveClaimCmd.Flags().StringVar(&claimChallenge, "challenge", "", "challenge from Ryve to include in a signed claim")
 
 }
 
-func generateQrCode(uri string) (string, error) {
-	q, err := qrcode.New(uri, qrcode.Low)
-
-	if err != nil {
-		return "", fmt.Errorf("failed to generate QR code: %s", err)
+func runRyveClaim(cmd *cobra.Command, args []string) error {
+	if claimFormat != "" && !slices.Contains(claimFormats, claimFormat) {
+		return fmt.Errorf("unknown format %q: expected one of %s", claimFormat, strings.Join(claimFormats, ", "))
 	}
 
-	terminalOutput := q.ToSmallString(false)
-	if pngOutput != "" {
-		if err := q.WriteFile(300, pngOutput); err != nil {
-			return "", err
+	if !signedClaim && !claimYes {
+		ok, err := confirm("This command will reveal your station's private key to terminal output. Please only reveal in a secure location. Continue?")
+		if err != nil {
+			return fmt.Errorf("%w (use --yes to skip the prompt)", err)
+		}
+		if !ok {
+			return errAborted
 		}
-	}
-
-	return terminalOutput, nil
-
-}
-
-func runRyveClaim(cmd *cobra.Command, args []string) error {
-
-	reader := bufio.NewReader(os.Stdin)
-	fmt.Print("This command will reveal your station's private key to terminal output. Please only reveal in a secure location. Continue? (y/n) ")
-	response, err := reader.ReadString('\n')
-	if err != nil {
-		return fmt.Errorf("failed to read confirmation: %w", err)
-	}
-	response = strings.TrimSpace(strings.ToLower(response))
-	if response != "y" && response != "yes" {
-		fmt.Println("Aborted.")
-		return nil
 	}
 
 	datadir := GetDataDir()
 
-	kp, _, err := config.LoadKey(datadir)
+	kp, _, err := config.LoadKey(datadir, keyOptions())
 	if err != nil {
 		if errors.Is(err, os.ErrNotExist) {
-			fmt.Println("Start your station first to create a key")
-			return errors.New("missing key")
+			return withExitCode(ExitMissingKey, fmt.Errorf("no key found in %s: start your station first to create a key", datadir))
 		}
 		return fmt.Errorf("failed to load key: %w", err)
 	}
 
-	keyData, err := crypto.KeyPairToBase64NoPad(kp)
+	// Prefer the saved station name over the hostname
+	savedName, err := config.LoadStationName(datadir)
 	if err != nil {
-		return fmt.Errorf("failed to get keypair data: %w", err)
+		return err
 	}
-	nameValue := name
-	if defaultNameFromHostname && !cmd.Flags().Changed("name") {
-		nameValue += " (use --name to explicitly set)"
+	if !cmd.Flags().Changed("name") && savedName != "" {
+		name = savedName
 	}
 
 	proxyID, err := crypto.KeyPairToCurve25519Base64(kp)
@@ -105,37 +122,99 @@ func runRyveClaim(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("failed to derive proxy id: %w", err)
 	}
 
-	payload := map[string]any{
-		"version": 1,
-		"data": map[string]any{
-			"name": name,
-			"key":  keyData,
-		},
+	var payloadJson []byte
+	if signedClaim {
+		payloadJson, err = ryve.NewSignedClaim(kp, name, claimChallenge, time.Now())
+	} else {
+		payloadJson, err = ryve.NewKeyClaim(kp, name)
+	}
+	if err != nil {
+		return fmt.Errorf("unexpected: failed to build claim: %w", err)
 	}
 
-	payloadJson, err := json.Marshal(payload)
+	uri := ryve.URI(payloadJson)
+
+	qr, err := ryve.NewQRCode(uri)
 	if err != nil {
-		fmt.Println("Error:", err)
-		return fmt.Errorf("unexpected: failed to marshal payload: %s", err)
+		return err
 	}
 
-	claim := base64.URLEncoding.EncodeToString(payloadJson)
-	uri := "network.ryve.app://(app)/conduits?claim=" + claim
+	if claimFormat != "" {
+		return writeClaim(qr, claimJSON{
+			Name:      name,
+			ProxyID:   proxyID,
+			PublicKey: crypto.PublicKeyBase64(kp),
+			Signed:    signedClaim,
+			URI:       uri,
+		})
+	}
 
-	if pngOutput == "" {
-		pngOutput = filepath.Join(datadir, "ryve-claim-qr.png")
+	// A v1 claim contains the private key, so only write it to disk when asked to
+	if claimOutput == "" && signedClaim {
+		claimOutput = filepath.Join(datadir, "ryve-claim-qr.png")
+	}
+	if claimOutput != "" && claimOutput != "-" {
+		data, err := qr.PNG(300)
+		if err != nil {
+			return fmt.Errorf("failed to generate QR code: %w", err)
+		}
+		if err := writePrivateFile(claimOutput, data); err != nil {
+			return fmt.Errorf("failed to write QR code: %w", err)
+		}
 	}
 
-	qrOutput, err := generateQrCode(uri)
-	if err != nil {
-		return fmt.Errorf("failed to generate QR code: %w", err)
+	nameValue := name
+	if !cmd.Flags().Changed("name") && savedName == "" && defaultNameFromHostname {
+		nameValue += " (use --name or 'conduit identity --set-name' to explicitly set)"
 	}
+
 	writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
 	fmt.Fprintf(writer, "Station Name:\t%s\n", nameValue)
 	fmt.Fprintf(writer, "Proxy ID:\t%s\n", proxyID)
 	writer.Flush()
-	fmt.Printf("claim QR code created at %s, scan this to claim this station in Ryve\n", pngOutput)
-	fmt.Println(qrOutput)
+	if claimOutput != "" && claimOutput != "-" {
+		fmt.Printf("claim QR code created at %s, scan this to claim this station in Ryve\n", claimOutput)
+	} else {
+		fmt.Println("Scan this QR code to claim this station in Ryve")
+	}
+	fmt.Println(qr.Terminal())
 
 	return nil
 }
+
+// writeClaim writes the claim in the --format representation to --output
+func writeClaim(qr *ryve.QRCode, claim claimJSON) error {
+	var data []byte
+	switch claimFormat {
+	case "uri":
+		data = []byte(claim.URI + "\n")
+	case "json":
+		encoded, err := json.Marshal(claim)
+		if err != nil {
+			return fmt.Errorf("failed to encode claim: %w", err)
+		}
+		data = append(encoded, '\n')
+	case "png":
+		if claimOutput == "" && isTerminal(os.Stdout) {
+			return errors.New("refusing to write a PNG to a terminal: use --output")
+		}
+		png, err := qr.PNG(300)
+		if err != nil {
+			return fmt.Errorf("failed to generate QR code: %w", err)
+		}
+		data = png
+	case "svg":
+		data = qr.SVG()
+	case "ansi":
+		data = []byte(qr.Terminal())
+	}
+
+	output := claimOutput
+	if output == "" {
+		output = "-"
+	}
+	if err := writePrivateFile(output, data); err != nil {
+		return fmt.Errorf("failed to write claim: %w", err)
+	}
+	return nil
+}
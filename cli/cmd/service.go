@@ -0,0 +1,149 @@
+/*
+ * Copyright (c) 2026, Psiphon Inc.
+ * All rights reserved.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/Psiphon-Inc/conduit/cli/internal/systemd"
+	"github.com/spf13/cobra"
+)
+
+var (
+	unitPath        string
+	unitUser        string
+	unitBinary      string
+	unitConfigPath  string
+	unitKeyPath     string
+	unitPassphrase  string
+	unitWatchdogSec int
+	unitPrint       bool
+	unitForce       bool
+)
+
+var serviceCmd = &cobra.Command{
+	Use:   "service",
+	Short: "Manage Conduit as a system service",
+}
+
+var serviceInstallCmd = &cobra.Command{
+	Use:   "install [-- start flags...]",
+	Short: "Write a hardened systemd unit file for Conduit",
+	Long: `Write a systemd unit that runs 'conduit start' with Type=notify, a watchdog,
+and a hardened sandbox. State is kept in ` + systemd.DataDir + ` (StateDirectory=).
+
+The Psiphon config and an existing key can be passed to the service with
+LoadCredential= so they never need to be readable by the service user.
+Any arguments after '--' are appended to 'conduit start'.
+
+Example:
+  conduit service install --psiphon-config /etc/conduit/psiphon_config.json -- --max-clients 200
+  systemctl daemon-reload && systemctl enable --now conduit`,
+	RunE: runServiceInstall,
+}
+
+func init() {
+	rootCmd.AddCommand(serviceCmd)
+	serviceCmd.AddCommand(serviceInstallCmd)
+
+	serviceInstallCmd.Flags().StringVar(&unitPath, "unit-path", systemd.DefaultUnitPath, "where to write the unit file")
+	serviceInstallCmd.Flags().StringVar(&unitUser, "user", "", "run the service as this user (default: systemd DynamicUser)")
+	serviceInstallCmd.Flags().StringVar(&unitBinary, "binary", "", "path to the conduit binary (default: this executable)")
+	serviceInstallCmd.Flags().StringVarP(&unitConfigPath, "psiphon-config", "c", "", "Psiphon config file to pass as a credential (default: embedded config)")
+	serviceInstallCmd.Flags().StringVar(&unitKeyPath, "key-file", "", "existing conduit_key.json to pass as a credential (default: key in state directory)")
+	serviceInstallCmd.Flags().StringVar(&unitPassphrase, "key-passphrase-file", "", "file holding the passphrase of an encrypted key, passed as a credential")
+	serviceInstallCmd.Flags().IntVar(&unitWatchdogSec, "watchdog-sec", 120, "systemd watchdog timeout in seconds (0 to disable)")
+	serviceInstallCmd.Flags().BoolVar(&unitPrint, "print", false, "print the unit to stdout instead of writing it")
+	serviceInstallCmd.Flags().BoolVar(&unitForce, "force", false, "overwrite an existing unit file")
+}
+
+func runServiceInstall(cmd *cobra.Command, args []string) error {
+	binary := unitBinary
+	if binary == "" {
+		exe, err := os.Executable()
+		if err != nil {
+			return fmt.Errorf("failed to determine executable path (use --binary): %w", err)
+		}
+		binary = exe
+	}
+
+	// LoadCredential= and ExecStart= need absolute paths
+	resolve := func(path string) (string, error) {
+		if path == "" {
+			return "", nil
+		}
+		abs, err := filepath.Abs(path)
+		if err != nil {
+			return "", err
+		}
+		if _, err := os.Stat(abs); err != nil {
+			return "", err
+		}
+		return abs, nil
+	}
+
+	var err error
+	if binary, err = resolve(binary); err != nil {
+		return fmt.Errorf("invalid binary path: %w", err)
+	}
+	configPath, err := resolve(unitConfigPath)
+	if err != nil {
+		return fmt.Errorf("invalid psiphon config path: %w", err)
+	}
+	keyPath, err := resolve(unitKeyPath)
+	if err != nil {
+		return fmt.Errorf("invalid key file path: %w", err)
+	}
+	passphrasePath, err := resolve(unitPassphrase)
+	if err != nil {
+		return fmt.Errorf("invalid key passphrase file path: %w", err)
+	}
+
+	unit, err := systemd.RenderUnit(systemd.UnitOptions{
+		BinaryPath:        binary,
+		User:              unitUser,
+		PsiphonConfigPath: configPath,
+		KeyPath:           keyPath,
+		KeyPassphrasePath: passphrasePath,
+		ExtraArgs:         args,
+		WatchdogSec:       unitWatchdogSec,
+	})
+	if err != nil {
+		return err
+	}
+
+	if unitPrint {
+		fmt.Print(unit)
+		return nil
+	}
+
+	if _, err := os.Stat(unitPath); err == nil && !unitForce {
+		return fmt.Errorf("unit file already exists: %s (use --force to overwrite)", unitPath)
+	}
+	if err := os.WriteFile(unitPath, []byte(unit), 0644); err != nil {
+		return fmt.Errorf("failed to write unit file: %w", err)
+	}
+
+	fmt.Printf("Unit file written to %s\n", unitPath)
+	fmt.Printf("Enable and start it with:\n  systemctl daemon-reload && systemctl enable --now %s\n", filepath.Base(unitPath))
+	return nil
+}
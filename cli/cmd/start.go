@@ -26,22 +26,75 @@ import (
 	"os"
 	"os/signal"
 	"path/filepath"
+	"strings"
+	"sync/atomic"
 	"syscall"
 	"time"
 
+	"github.com/Psiphon-Inc/conduit/cli/internal/alert"
 	"github.com/Psiphon-Inc/conduit/cli/internal/conduit"
 	"github.com/Psiphon-Inc/conduit/cli/internal/config"
+	"github.com/Psiphon-Inc/conduit/cli/internal/crypto"
+	"github.com/Psiphon-Inc/conduit/cli/internal/debug"
+	"github.com/Psiphon-Inc/conduit/cli/internal/events"
+	"github.com/Psiphon-Inc/conduit/cli/internal/httpserver"
+	"github.com/Psiphon-Inc/conduit/cli/internal/metrics"
+	"github.com/Psiphon-Inc/conduit/cli/internal/push"
+	"github.com/Psiphon-Inc/conduit/cli/internal/remoteconfig"
+	"github.com/Psiphon-Inc/conduit/cli/internal/systemd"
+	"github.com/Psiphon-Inc/conduit/cli/internal/tui"
+	"github.com/Psiphon-Inc/conduit/cli/internal/update"
 	"github.com/spf13/cobra"
 )
 
 var (
-	maxClients        int
-	bandwidthMbps     float64
-	psiphonConfigPath string
-	statsFilePath     string
-	geoEnabled        bool
-	metricsAddr       string
-	idleRestart       string
+	maxClients          int
+	bandwidthMbps       float64
+	psiphonConfigPath   string
+	statsFilePath       string
+	statsInterval       time.Duration
+	statsHistoryPath    string
+	statsHistoryFmt     string
+	statsHistoryMaxMB   int
+	statsHistoryKeep    int
+	statsTextfilePath   string
+	geoEnabled          bool
+	metricsAddr         string
+	metricsTLSCert      string
+	metricsTLSKey       string
+	metricsSelfSigned   bool
+	metricsClientCA     string
+	metricsUser         string
+	metricsAllow        []string
+	pushgatewayURL      string
+	remoteWriteURL      string
+	metricsPushEvery    time.Duration
+	dashboardAddr       string
+	dashboardTLSCert    string
+	dashboardTLSKey     string
+	dashboardSelfSigned bool
+	dashboardUser       string
+	dashboardAllow      []string
+	debugAddr           string
+	debugTLSCert        string
+	debugTLSKey         string
+	debugSelfSigned     bool
+	debugAllow          []string
+	idleRestart         string
+	restoreMnemonic     string
+	derivationPath      string
+	stationName         string
+	tuiEnabled          bool
+	webhooksPath        string
+	pushTarget          string
+	pushInterval        time.Duration
+	pushPrefix          string
+	configURL           string
+	configKey           string
+	configRefresh       time.Duration
+	autoUpdate          bool
+	updateInterval      time.Duration
+	updateDrain         time.Duration
 )
 
 var startCmd = &cobra.Command{
@@ -68,34 +121,156 @@ func init() {
 	startCmd.Flags().Float64VarP(&bandwidthMbps, "bandwidth", "b", config.DefaultBandwidthMbps, "total bandwidth limit in Mbps (-1 for unlimited)")
 	startCmd.Flags().StringVarP(&statsFilePath, "stats-file", "s", "", "persist stats to JSON file (default: stats.json in data dir if flag used without value)")
 	startCmd.Flags().Lookup("stats-file").NoOptDefVal = "stats.json"
+	startCmd.Flags().StringVar(&statsHistoryPath, "stats-history", "", "append stats snapshots to a JSONL or CSV file (relative to the data dir)")
+	startCmd.Flags().StringVar(&statsHistoryFmt, "stats-history-format", "", "stats history format: jsonl or csv (default: from the file extension)")
+	startCmd.Flags().IntVar(&statsHistoryMaxMB, "stats-history-max-size", 10, "rotate the stats history at this size in MB (0 to never rotate)")
+	startCmd.Flags().IntVar(&statsHistoryKeep, "stats-history-keep", 5, "number of rotated stats history files to keep")
+	startCmd.Flags().StringVar(&statsTextfilePath, "stats-textfile", "", "write stats as Prometheus metrics for the node_exporter textfile collector (e.g. /var/lib/node_exporter/conduit.prom)")
+	startCmd.Flags().DurationVar(&statsInterval, "stats-interval", config.DefaultStatsInterval, "how often to rewrite the stats file (also rewritten when client counts change)")
 	startCmd.Flags().BoolVar(&geoEnabled, "geo", false, "enable client location tracking (requires tcpdump, geoip-bin)")
 	startCmd.Flags().StringVar(&metricsAddr, "metrics-addr", "", "address for Prometheus metrics endpoint (e.g., :9090 or 127.0.0.1:9090)")
+	startCmd.Flags().StringVar(&metricsTLSCert, "metrics-tls-cert", "", "serve metrics over HTTPS with this certificate file")
+	startCmd.Flags().StringVar(&metricsTLSKey, "metrics-tls-key", "", "private key file for --metrics-tls-cert")
+	startCmd.Flags().BoolVar(&metricsSelfSigned, "metrics-tls-self-signed", false, "serve metrics over HTTPS with a self-signed certificate kept in the data dir")
+	startCmd.Flags().StringVar(&metricsClientCA, "metrics-client-ca", "", "require metrics clients to present a certificate signed by this CA (mTLS)")
+	startCmd.Flags().StringVar(&metricsUser, "metrics-user", "", "require basic auth with this user (password from $"+config.MetricsPasswordEnv+")")
+	startCmd.Flags().StringSliceVar(&metricsAllow, "metrics-allow", nil, "only serve metrics to these addresses or CIDRs (comma-separated or repeated)")
+	startCmd.Flags().StringVar(&pushgatewayURL, "metrics-pushgateway", "", "push Prometheus metrics to this Pushgateway URL")
+	startCmd.Flags().StringVar(&remoteWriteURL, "metrics-remote-write", "", "push Prometheus metrics to this remote-write URL (e.g., https://prometheus.example.com/api/v1/write)")
+	startCmd.Flags().DurationVar(&metricsPushEvery, "metrics-push-interval", metrics.DefaultPushInterval, "how often to push Prometheus metrics")
+	startCmd.Flags().StringVar(&dashboardAddr, "dashboard-addr", "", "address for the web dashboard (e.g., 127.0.0.1:8080)")
+	startCmd.Flags().StringVar(&dashboardTLSCert, "dashboard-tls-cert", "", "serve the dashboard over HTTPS with this certificate file")
+	startCmd.Flags().StringVar(&dashboardTLSKey, "dashboard-tls-key", "", "private key file for --dashboard-tls-cert")
+	startCmd.Flags().BoolVar(&dashboardSelfSigned, "dashboard-tls-self-signed", false, "serve the dashboard over HTTPS with a self-signed certificate kept in the data dir")
+	startCmd.Flags().StringVar(&dashboardUser, "dashboard-user", "", "require basic auth with this user (password from $"+config.DashboardPasswordEnv+")")
+	startCmd.Flags().StringSliceVar(&dashboardAllow, "dashboard-allow", nil, "only serve the dashboard to these addresses or CIDRs (comma-separated or repeated)")
+	startCmd.Flags().StringVar(&debugAddr, "debug-addr", "", "serve pprof and diagnostics for 'conduit debug dump' (default "+debug.DefaultAddr+" if flag used without value)")
+	startCmd.Flags().Lookup("debug-addr").NoOptDefVal = debug.DefaultAddr
+	startCmd.Flags().StringVar(&debugTLSCert, "debug-tls-cert", "", "serve diagnostics over HTTPS with this certificate file")
+	startCmd.Flags().StringVar(&debugTLSKey, "debug-tls-key", "", "private key file for --debug-tls-cert")
+	startCmd.Flags().BoolVar(&debugSelfSigned, "debug-tls-self-signed", false, "serve diagnostics over HTTPS with a self-signed certificate kept in the data dir")
+	startCmd.Flags().StringSliceVar(&debugAllow, "debug-allow", nil, "only serve diagnostics to these addresses or CIDRs (comma-separated or repeated)")
 	startCmd.Flags().StringVarP(&psiphonConfigPath, "psiphon-config", "c", "", "path to Psiphon network config file (JSON)")
+	startCmd.Flags().StringVar(&configURL, "psiphon-config-url", "", "fetch the Psiphon config from this URL and refresh it periodically (requires --psiphon-config-key)")
+	startCmd.Flags().StringVar(&configKey, "psiphon-config-key", "", "base64 Ed25519 public key that signs the config at --psiphon-config-url")
+	startCmd.Flags().DurationVar(&configRefresh, "psiphon-config-refresh", remoteconfig.DefaultInterval, "how often to refresh the config from --psiphon-config-url")
+	startCmd.Flags().BoolVar(&autoUpdate, "auto-update", false, "install new releases and restart into them once clients disconnect")
+	startCmd.Flags().DurationVar(&updateInterval, "update-interval", update.DefaultInterval, "how often --auto-update checks for a release")
+	startCmd.Flags().DurationVar(&updateDrain, "update-drain", update.DefaultDrain, "how long --auto-update waits for clients to disconnect before restarting")
+	addReleaseFlags(startCmd)
 	startCmd.Flags().StringVar(&idleRestart, "idle-restart", "", "restart service after idle duration (e.g., 30m, 1h, 2h)")
+	startCmd.Flags().StringVar(&restoreMnemonic, "restore-mnemonic-file", "", "restore the station key from a mnemonic backup file ('-' to enter it interactively)")
+	startCmd.Flags().StringVar(&derivationPath, "derivation-path", "", "derivation path used with --restore-mnemonic-file (default: none)")
+	startCmd.Flags().BoolVar(&tuiEnabled, "tui", false, "full-screen terminal UI (falls back to line output when not in a terminal)")
+	startCmd.Flags().StringVar(&stationName, "name", "", "set and save the station name shown in stats, metrics and Ryve")
+	startCmd.Flags().StringVar(&webhooksPath, "webhooks", "", "send alerts to the webhooks in this JSON config file")
+	startCmd.Flags().StringVar(&pushTarget, "push-to", "", "push stats to statsd://host:port, dogstatsd://host:port or an InfluxDB write URL")
+	startCmd.Flags().DurationVar(&pushInterval, "push-interval", push.DefaultInterval, "how often to push stats")
+	startCmd.Flags().StringVar(&pushPrefix, "push-prefix", push.DefaultPrefix, "metric name prefix for StatsD")
 }
 
 func runStart(cmd *cobra.Command, args []string) error {
-	// Determine psiphon config source: flag > embedded > error
-	effectiveConfigPath := psiphonConfigPath
-	useEmbedded := false
+	remote, err := remoteConfigSource(cmd)
+	if err != nil {
+		return err
+	}
 
-	if psiphonConfigPath != "" {
-		// User provided a config path - validate it exists
-		if _, err := os.Stat(psiphonConfigPath); os.IsNotExist(err) {
-			return fmt.Errorf("psiphon config file not found: %s", psiphonConfigPath)
+	// With a remote config, a local config is only a fallback
+	effectiveConfigPath, useEmbedded, err := resolvePsiphonConfig(psiphonConfigPath)
+	if err != nil && (remote == nil || psiphonConfigPath != "") {
+		return err
+	}
+
+	// A key passed by systemd via LoadCredential= takes precedence over the data dir
+	keyFile, _ := systemd.CredentialPath(systemd.KeyCredential)
+
+	// Read the mnemonic to restore the key from, if requested
+	mnemonic := ""
+	if restoreMnemonic != "" {
+		if restoreMnemonic == "-" && isTerminal(os.Stdin) {
+			fmt.Fprint(os.Stderr, "Enter your 24-word mnemonic (input is hidden): ")
 		}
-	} else if config.HasEmbeddedConfig() {
-		// No flag provided, but we have embedded config
-		useEmbedded = true
-	} else {
-		// No flag and no embedded config
-		return fmt.Errorf("psiphon config required: use --psiphon-config flag or build with embedded config")
+		var err error
+		if mnemonic, err = readSecretInput(restoreMnemonic); err != nil {
+			return fmt.Errorf("failed to read mnemonic: %w", err)
+		}
+	} else if derivationPath != "" {
+		return fmt.Errorf("--derivation-path requires --restore-mnemonic-file")
+	}
+
+	// Load the webhook config before starting, so mistakes fail fast
+	var alertConfig *alert.Config
+	if webhooksPath != "" {
+		var err error
+		if alertConfig, err = alert.LoadConfig(webhooksPath); err != nil {
+			return err
+		}
+	}
+
+	metricsServer, err := metricsServerOptions(cmd)
+	if err != nil {
+		return err
+	}
+	dashboardServer, err := dashboardServerOptions(cmd)
+	if err != nil {
+		return err
+	}
+	debugServer, err := debugServerOptions(cmd)
+	if err != nil {
+		return err
+	}
+
+	metricsPush := config.MetricsPush{
+		Pushgateway: pushgatewayURL,
+		RemoteWrite: remoteWriteURL,
+		Interval:    metricsPushEvery,
+		Password:    os.Getenv(metrics.PushPasswordEnv),
+		BearerToken: os.Getenv(metrics.PushTokenEnv),
+	}
+	if metricsPush.Enabled() && metricsPushEvery < time.Second {
+		return fmt.Errorf("metrics-push-interval must be at least 1s")
+	}
+
+	var pusher *push.Pusher
+	if pushTarget != "" {
+		if pushInterval < time.Second {
+			return fmt.Errorf("push-interval must be at least 1s")
+		}
+		var err error
+		pusher, err = push.New(pushTarget, push.Options{
+			Interval: pushInterval,
+			Prefix:   pushPrefix,
+			Token:    os.Getenv(push.TokenEnv),
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	// Resolve stats file paths - if relative, place in data dir
+	resolvedStatsFile := resolveStatsPath(statsFilePath)
+
+	if statsInterval < time.Second {
+		return fmt.Errorf("stats-interval must be at least 1s")
 	}
 
-	// Resolve stats file path - if relative, place in data dir
-	resolvedStatsFile := statsFilePath
-	if resolvedStatsFile != "" && !filepath.IsAbs(resolvedStatsFile) {
-		resolvedStatsFile = filepath.Join(GetDataDir(), resolvedStatsFile)
+	statsHistory := config.StatsHistory{
+		File:     resolveStatsPath(statsHistoryPath),
+		Format:   statsHistoryFmt,
+		MaxBytes: int64(statsHistoryMaxMB) * 1000 * 1000,
+		Keep:     statsHistoryKeep,
+	}
+	if statsHistory.Format == "" {
+		statsHistory.Format = conduit.HistoryJSONL
+		if strings.EqualFold(filepath.Ext(statsHistoryPath), ".csv") {
+			statsHistory.Format = conduit.HistoryCSV
+		}
+	}
+	if statsHistory.Format != conduit.HistoryJSONL && statsHistory.Format != conduit.HistoryCSV {
+		return fmt.Errorf("stats-history-format must be jsonl or csv")
+	}
+	if statsHistoryMaxMB < 0 || statsHistoryKeep < 0 {
+		return fmt.Errorf("stats-history-max-size and stats-history-keep must not be negative")
 	}
 
 	maxClientsFromFlag := 0
@@ -139,18 +314,60 @@ func runStart(cmd *cobra.Command, args []string) error {
 		BandwidthSet:      bandwidthFromFlagSet,
 		Verbosity:         Verbosity(),
 		StatsFile:         resolvedStatsFile,
+		StatsInterval:     statsInterval,
+		StatsHistory:      statsHistory,
+		StatsTextfile:     resolveStatsPath(statsTextfilePath),
 		GeoEnabled:        geoEnabled,
 		MetricsAddr:       metricsAddr,
+		MetricsServer:     metricsServer,
+		MetricsPush:       metricsPush,
+		DashboardAddr:     dashboardAddr,
+		DashboardServer:   dashboardServer,
 		IdleRestart:       idleRestartDuration,
+		KeyFile:           keyFile,
+		Key:               keyOptions(),
+		RestoreMnemonic:   mnemonic,
+		DerivationPath:    derivationPath,
+		StationName:       stationName,
 	})
 	if err != nil {
 		return fmt.Errorf("failed to load configuration: %w", err)
 	}
+	cfg.Version = version
 
 	// Setup context with cancellation
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
+	var configUpdated <-chan struct{}
+	if remote != nil {
+		if err := loadRemoteConfig(ctx, remote, cfg); err != nil {
+			return err
+		}
+		remote.Start(ctx)
+		configUpdated = remote.Updated()
+	}
+
+	// Report readiness and status to systemd when run with Type=notify
+	notifier := systemd.NewNotifier()
+
+	// Live events outlive service restarts, so stream clients can resume
+	bus := events.NewBus(events.DefaultHistory)
+
+	if alertConfig != nil {
+		proxyID, err := crypto.KeyPairToCurve25519Base64(cfg.KeyPair)
+		if err != nil {
+			return fmt.Errorf("failed to derive proxy id: %w", err)
+		}
+		alert.New(alertConfig, cfg.StationName, proxyID).Start(ctx, bus)
+		fmt.Printf("[INFO] Sending alerts to %d webhook(s)\n", len(alertConfig.Webhooks))
+	}
+
+	updatePath, updateInstalled, err := startAutoUpdate(ctx, cmd, bus)
+	if err != nil {
+		return err
+	}
+
 	// Handle shutdown signals
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
@@ -158,22 +375,149 @@ func runStart(cmd *cobra.Command, args []string) error {
 	go func() {
 		<-sigChan
 		fmt.Println("\nShutting down...")
+		notifier.Stopping()
 		cancel()
 	}()
 
-	// Run the service (with restart loop if idle-restart is enabled)
+	// Full-screen UI, when requested and running in a terminal
+	var ui *tui.UI
+	var current atomic.Pointer[conduit.Service]
+	if tuiEnabled {
+		if tui.Supported(os.Stdin, os.Stdout) {
+			var restore func()
+			ui, restore, err = startTUI(cancel, cfg, &current)
+			if err != nil {
+				return err
+			}
+			defer restore()
+		} else {
+			fmt.Println("[WARN] --tui requires a terminal, using line output")
+		}
+	}
+	// Diagnostics outlive service restarts, like the event bus
+	notices := debug.NewNotices(debug.DefaultNotices)
+	if debugAddr != "" {
+		server, err := debug.Start(debugAddr, debug.Sources{
+			Version: version,
+			Started: time.Now(),
+			Stats: func() (any, bool) {
+				if s := current.Load(); s != nil {
+					return s.State().Stats, true
+				}
+				return nil, false
+			},
+			Config:  cfg.Effective(),
+			Notices: notices,
+		}, debugServer)
+		if err != nil {
+			return fmt.Errorf("failed to start debug server: %w", err)
+		}
+		defer func() {
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			server.Shutdown(ctx)
+		}()
+		scheme := "http"
+		if debugServer.TLS() {
+			scheme = "https"
+		}
+		fmt.Printf("Debug server available at %s://%s/debug/pprof/\n", scheme, debugAddr)
+	}
+
+	if pusher != nil {
+		pusher.Start(ctx, func() (conduit.StatsJSON, bool) {
+			if s := current.Load(); s != nil {
+				return s.State().Stats, true
+			}
+			return conduit.StatsJSON{}, false
+		})
+		fmt.Printf("[INFO] Pushing stats to %s every %s\n", pusher, pushInterval)
+	}
+
+	var commands <-chan tui.Command
+	if ui != nil {
+		commands = ui.Commands()
+	}
+
+	// Run the service (with restart loop if idle-restart is enabled, when
+	// the UI pauses it or changes limits, or when the remote config changes)
 	for {
+		if remote != nil && remote.Config() != nil {
+			cfg.PsiphonConfigData = remote.Config()
+			cfg.PsiphonConfigURL = remote.String()
+		}
+
 		// Create conduit service
 		service, err := conduit.New(cfg)
 		if err != nil {
 			return fmt.Errorf("failed to create conduit service: %w", err)
 		}
+		service.SetNotifier(notifier)
+		service.SetEvents(bus)
+		service.SetNotices(notices)
+
+		// Stop the service when the UI sends a command
+		runCtx, stopService := context.WithCancel(ctx)
+		var command *tui.Command
+		configChanged := false
+		installedVersion := ""
+		commandDone := make(chan struct{})
+		go func() {
+			defer close(commandDone)
+			select {
+			case c := <-commands:
+				command = &c
+				stopService()
+			case <-configUpdated:
+				configChanged = true
+				stopService()
+			case v := <-updateInstalled:
+				installedVersion = v
+				fmt.Printf("[INFO] Installed conduit %s, taking no new clients and restarting once clients disconnect (at most %s)\n", v, updateDrain)
+				service.StopAnnouncing()
+				update.Drain(runCtx, func() int { return service.State().Stats.ConnectedClients }, updateDrain, 5*time.Second)
+				stopService()
+			case <-runCtx.Done():
+			}
+		}()
 
 		// Run the service
-		err = service.Run(ctx)
+		current.Store(service)
+		err = service.Run(runCtx)
+		current.Store(nil)
+		stopService()
+		<-commandDone
+
+		if command != nil && ctx.Err() == nil {
+			if !handleTUICommand(ctx, cfg, *command, commands) {
+				break
+			}
+			reason := "limits"
+			if command.Action == tui.ActionPause {
+				reason = "resume"
+			}
+			bus.Publish(events.TypeRestart, events.Restart{Reason: reason})
+			continue
+		}
+
+		if installedVersion != "" && ctx.Err() == nil {
+			fmt.Printf("[INFO] Restarting into conduit %s\n", installedVersion)
+			notifier.Status("Restarting into conduit " + installedVersion)
+			bus.Publish(events.TypeRestart, events.Restart{Reason: "update"})
+			restartExecutable = updatePath
+			break
+		}
+
+		if configChanged && ctx.Err() == nil {
+			fmt.Printf("[INFO] Psiphon config changed at %s, restarting\n", remote)
+			notifier.Status("Restarting with the updated Psiphon config")
+			bus.Publish(events.TypeRestart, events.Restart{Reason: "config"})
+			continue
+		}
 
 		// Check if we should restart due to idle timeout
 		if errors.Is(err, conduit.ErrIdleRestart) {
+			notifier.Status("Restarting after idle timeout")
 			// Brief pause before restarting
 			select {
 			case <-ctx.Done():
@@ -195,3 +539,347 @@ func runStart(cmd *cobra.Command, args []string) error {
 	fmt.Println("Stopped.")
 	return nil
 }
+
+// metricsServerOptions builds the TLS, auth and allowlist options of the
+// metrics endpoint from flags and the environment
+func metricsServerOptions(cmd *cobra.Command) (config.ServerOptions, error) {
+	var opts config.ServerOptions
+	for _, name := range []string{"metrics-tls-cert", "metrics-tls-key", "metrics-tls-self-signed", "metrics-client-ca", "metrics-user", "metrics-allow"} {
+		if cmd.Flags().Changed(name) && metricsAddr == "" {
+			return opts, fmt.Errorf("--%s requires --metrics-addr", name)
+		}
+	}
+
+	opts.TLSCert, opts.TLSKey = metricsTLSCert, metricsTLSKey
+	if metricsSelfSigned {
+		if metricsTLSCert != "" || metricsTLSKey != "" {
+			return opts, fmt.Errorf("--metrics-tls-self-signed can't be used with --metrics-tls-cert")
+		}
+		opts.TLSSelfSigned = true
+		opts.TLSCert = filepath.Join(GetDataDir(), "metrics_tls.crt")
+		opts.TLSKey = filepath.Join(GetDataDir(), "metrics_tls.key")
+	}
+	opts.ClientCA = metricsClientCA
+	opts.Username = metricsUser
+
+	var err error
+	if opts.BearerToken, err = config.LookupSecret(config.MetricsTokenEnv); err != nil {
+		return opts, err
+	}
+	if opts.Username != "" {
+		if opts.Password, err = config.LookupSecret(config.MetricsPasswordEnv); err != nil {
+			return opts, err
+		}
+		if opts.Password == "" {
+			return opts, fmt.Errorf("--metrics-user requires a password in $%s or $%s_FILE", config.MetricsPasswordEnv, config.MetricsPasswordEnv)
+		}
+	}
+	if opts.Allow, err = httpserver.ParseAllow(metricsAllow); err != nil {
+		return opts, fmt.Errorf("invalid --metrics-allow: %w", err)
+	}
+	if err := httpserver.Options(opts).Validate(metricsAddr); err != nil {
+		return opts, fmt.Errorf("invalid metrics endpoint options: %w", err)
+	}
+	return opts, nil
+}
+
+// dashboardServerOptions builds the TLS, auth and allowlist options of the web
+// dashboard. It shows the station's configuration and clients, so it's only
+// served off loopback to authenticated or allowlisted clients.
+func dashboardServerOptions(cmd *cobra.Command) (config.ServerOptions, error) {
+	var opts config.ServerOptions
+	for _, name := range []string{"dashboard-tls-cert", "dashboard-tls-key", "dashboard-tls-self-signed", "dashboard-user", "dashboard-allow"} {
+		if cmd.Flags().Changed(name) && dashboardAddr == "" {
+			return opts, fmt.Errorf("--%s requires --dashboard-addr", name)
+		}
+	}
+	if dashboardAddr == "" {
+		return opts, nil
+	}
+
+	opts.TLSCert, opts.TLSKey = dashboardTLSCert, dashboardTLSKey
+	if dashboardSelfSigned {
+		if dashboardTLSCert != "" || dashboardTLSKey != "" {
+			return opts, fmt.Errorf("--dashboard-tls-self-signed can't be used with --dashboard-tls-cert")
+		}
+		opts.TLSSelfSigned = true
+		opts.TLSCert = filepath.Join(GetDataDir(), "dashboard_tls.crt")
+		opts.TLSKey = filepath.Join(GetDataDir(), "dashboard_tls.key")
+	}
+	opts.Username = dashboardUser
+
+	var err error
+	if opts.BearerToken, err = config.LookupSecret(config.DashboardTokenEnv); err != nil {
+		return opts, err
+	}
+	if opts.Username != "" {
+		if opts.Password, err = config.LookupSecret(config.DashboardPasswordEnv); err != nil {
+			return opts, err
+		}
+		if opts.Password == "" {
+			return opts, fmt.Errorf("--dashboard-user requires a password in $%s or $%s_FILE", config.DashboardPasswordEnv, config.DashboardPasswordEnv)
+		}
+	}
+	if opts.Allow, err = httpserver.ParseAllow(dashboardAllow); err != nil {
+		return opts, fmt.Errorf("invalid --dashboard-allow: %w", err)
+	}
+	server := httpserver.Options(opts)
+	if !httpserver.Loopback(dashboardAddr) && !server.Authenticated() && len(opts.Allow) == 0 {
+		return opts, fmt.Errorf("--dashboard-addr %s is not a loopback address: set --dashboard-user or --dashboard-allow, or listen on 127.0.0.1", dashboardAddr)
+	}
+	if err := server.Validate(dashboardAddr); err != nil {
+		return opts, fmt.Errorf("invalid dashboard options: %w", err)
+	}
+	return opts, nil
+}
+
+// debugServerOptions builds the TLS, auth and allowlist options of the debug
+// server. Diagnostics include profiles, the configuration and notices, so
+// they're only served off loopback to clients with the bearer token.
+func debugServerOptions(cmd *cobra.Command) (httpserver.Options, error) {
+	var opts httpserver.Options
+	for _, name := range []string{"debug-tls-cert", "debug-tls-key", "debug-tls-self-signed", "debug-allow"} {
+		if cmd.Flags().Changed(name) && debugAddr == "" {
+			return opts, fmt.Errorf("--%s requires --debug-addr", name)
+		}
+	}
+	if debugAddr == "" {
+		return opts, nil
+	}
+
+	opts.TLSCert, opts.TLSKey = debugTLSCert, debugTLSKey
+	if debugSelfSigned {
+		if debugTLSCert != "" || debugTLSKey != "" {
+			return opts, fmt.Errorf("--debug-tls-self-signed can't be used with --debug-tls-cert")
+		}
+		opts.TLSSelfSigned = true
+		opts.TLSCert = filepath.Join(GetDataDir(), "debug_tls.crt")
+		opts.TLSKey = filepath.Join(GetDataDir(), "debug_tls.key")
+	}
+
+	var err error
+	if opts.BearerToken, err = config.LookupSecret(config.DebugTokenEnv); err != nil {
+		return opts, err
+	}
+	if opts.Allow, err = httpserver.ParseAllow(debugAllow); err != nil {
+		return opts, fmt.Errorf("invalid --debug-allow: %w", err)
+	}
+	if !httpserver.Loopback(debugAddr) && !opts.Authenticated() {
+		return opts, fmt.Errorf("--debug-addr %s is not a loopback address: set a token in $%s and enable TLS, or listen on 127.0.0.1", debugAddr, config.DebugTokenEnv)
+	}
+	if err := opts.Validate(debugAddr); err != nil {
+		return opts, fmt.Errorf("invalid debug server options: %w", err)
+	}
+	return opts, nil
+}
+
+// resolvePsiphonConfig determines the psiphon config source:
+// flag > systemd credential > embedded > error
+func resolvePsiphonConfig(path string) (effectivePath string, useEmbedded bool, err error) {
+	if path != "" {
+		// User provided a config path - validate it exists
+		if _, err := os.Stat(path); os.IsNotExist(err) {
+			return "", false, fmt.Errorf("psiphon config file not found: %s", path)
+		}
+		return path, false, nil
+	}
+	if credPath, ok := systemd.CredentialPath(systemd.PsiphonConfigCredential); ok {
+		// Passed by systemd via LoadCredential=
+		return credPath, false, nil
+	}
+	if config.HasEmbeddedConfig() {
+		// No flag provided, but we have embedded config
+		return "", true, nil
+	}
+	// No flag and no embedded config
+	return "", false, fmt.Errorf("psiphon config required: use --psiphon-config flag or build with embedded config")
+}
+
+// startAutoUpdate starts checking for releases with --auto-update, and
+// checks at once when the broker says an upgrade is required. It returns
+// the binary to restart into and the channel of installed versions.
+func startAutoUpdate(ctx context.Context, cmd *cobra.Command, bus *events.Bus) (string, <-chan string, error) {
+	if !autoUpdate {
+		for _, name := range []string{"update-interval", "update-drain", "release-url", "release-key"} {
+			if cmd.Flags().Changed(name) {
+				return "", nil, fmt.Errorf("--%s requires --auto-update", name)
+			}
+		}
+		return "", nil, nil
+	}
+	if !update.IsRelease(version) {
+		return "", nil, fmt.Errorf("--auto-update requires a release build, not %s", version)
+	}
+	updater, err := newUpdater()
+	if err != nil {
+		return "", nil, err
+	}
+	path, err := update.Executable()
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to find this binary: %w", err)
+	}
+	if err := update.Writable(path); err != nil {
+		return "", nil, fmt.Errorf("--auto-update: %w", err)
+	}
+
+	// Check at start, and when the broker requires an upgrade
+	trigger := make(chan struct{}, 1)
+	trigger <- struct{}{}
+	sub := bus.Subscribe(0)
+	go func() {
+		defer func() { sub.Close() }()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case event, ok := <-sub.Events():
+				if !ok {
+					sub = bus.Subscribe(0)
+					continue
+				}
+				if event.Type != events.TypeUpgrade {
+					continue
+				}
+				select {
+				case trigger <- struct{}{}:
+				default:
+				}
+			}
+		}
+	}()
+
+	fmt.Printf("[INFO] Auto-update: checking %s every %s\n", releaseURL, updateInterval)
+	return path, updater.Watch(ctx, version, path, updateInterval, trigger), nil
+}
+
+// remoteConfigSource returns the remote Psiphon config source, or nil when
+// --psiphon-config-url is not set
+func remoteConfigSource(cmd *cobra.Command) (*remoteconfig.Source, error) {
+	if configURL == "" {
+		for _, name := range []string{"psiphon-config-key", "psiphon-config-refresh"} {
+			if cmd.Flags().Changed(name) {
+				return nil, fmt.Errorf("--%s requires --psiphon-config-url", name)
+			}
+		}
+		return nil, nil
+	}
+	if configKey == "" {
+		return nil, fmt.Errorf("--psiphon-config-url requires --psiphon-config-key")
+	}
+	key, err := crypto.ParseEd25519PublicKey(configKey)
+	if err != nil {
+		return nil, err
+	}
+	return remoteconfig.New(remoteconfig.Options{
+		URL:       configURL,
+		PublicKey: key,
+		Interval:  configRefresh,
+		DataDir:   GetDataDir(),
+	})
+}
+
+// loadRemoteConfig fetches the remote config, falling back to the cached
+// config and then to the local one
+func loadRemoteConfig(ctx context.Context, remote *remoteconfig.Source, cfg *config.Config) error {
+	data, fromCache, err := remote.Load(ctx)
+	if data != nil {
+		cfg.PsiphonConfigData, cfg.PsiphonConfigURL = data, remote.String()
+	}
+	switch {
+	case err == nil:
+		fmt.Printf("[INFO] Using Psiphon config from %s\n", remote)
+	case fromCache:
+		fmt.Printf("[WARN] %v\n", err)
+		fmt.Printf("[WARN] Using the last good Psiphon config from %s\n", remote.CachePath())
+	case len(cfg.PsiphonConfigData) > 0 || cfg.PsiphonConfigPath != "":
+		fmt.Printf("[WARN] %v\n", err)
+		fmt.Println("[WARN] Using the local Psiphon config until the remote config is available")
+	default:
+		return fmt.Errorf("failed to load psiphon config: %w", err)
+	}
+	return nil
+}
+
+// resolveStatsPath places a relative stats output path in the data dir
+func resolveStatsPath(path string) string {
+	if path != "" && !filepath.IsAbs(path) {
+		return filepath.Join(GetDataDir(), path)
+	}
+	return path
+}
+
+// startTUI starts the terminal UI, capturing stdout into its events panel.
+// The returned function stops the UI and restores the terminal and stdout.
+func startTUI(quit context.CancelFunc, cfg *config.Config, current *atomic.Pointer[conduit.Service]) (*tui.UI, func(), error) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to start terminal UI: %w", err)
+	}
+
+	terminal := os.Stdout
+	ui := tui.New(os.Stdin, terminal, func() (conduit.DashboardState, bool) {
+		if s := current.Load(); s != nil {
+			return s.State(), true
+		}
+		return conduit.DashboardState{}, false
+	}, quit, cfg.MaxClients, cfg.BandwidthBytesPerSecond)
+
+	// Service output goes to the events panel instead of the screen
+	os.Stdout = w
+	captured := make(chan struct{})
+	go func() {
+		ui.CaptureLines(r)
+		close(captured)
+	}()
+
+	// The UI keeps running while the service shuts down, until restored
+	uiCtx, stopUI := context.WithCancel(context.Background())
+	uiDone := make(chan error, 1)
+	go func() {
+		uiDone <- ui.Run(uiCtx)
+		// Shut down if the UI fails, rather than running blind
+		quit()
+	}()
+
+	return ui, func() {
+		stopUI()
+		uiErr := <-uiDone
+		os.Stdout = terminal
+		w.Close()
+		<-captured
+		r.Close()
+		if uiErr != nil {
+			fmt.Printf("[ERROR] %v\n", uiErr)
+		}
+	}, nil
+}
+
+// handleTUICommand applies a command from the UI after the service stopped.
+// It returns false if the service should not be restarted.
+func handleTUICommand(ctx context.Context, cfg *config.Config, command tui.Command, commands <-chan tui.Command) bool {
+	switch command.Action {
+	case tui.ActionPause:
+		fmt.Println("[INFO] Paused")
+		// Wait for resume; limits changed while paused apply on resume
+		for {
+			select {
+			case <-ctx.Done():
+				return false
+			case c := <-commands:
+				switch c.Action {
+				case tui.ActionResume:
+					fmt.Println("[INFO] Resuming")
+					return true
+				case tui.ActionSetLimits:
+					cfg.MaxClients = c.MaxClients
+					cfg.BandwidthBytesPerSecond = c.BandwidthBytesPerSecond
+				}
+			}
+		}
+	case tui.ActionSetLimits:
+		cfg.MaxClients = command.MaxClients
+		cfg.BandwidthBytesPerSecond = command.BandwidthBytesPerSecond
+		fmt.Printf("[INFO] Restarting with max clients %d\n", cfg.MaxClients)
+	}
+	return true
+}
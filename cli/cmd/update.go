@@ -0,0 +1,133 @@
+/*
+ * Copyright (c) 2026, Psiphon Inc.
+ * All rights reserved.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Psiphon-Inc/conduit/cli/internal/crypto"
+	"github.com/Psiphon-Inc/conduit/cli/internal/update"
+	"github.com/spf13/cobra"
+)
+
+// releaseKey is the pinned base64 Ed25519 key that signs release checksums,
+// set at build time with -ldflags "-X .../cmd.releaseKey=..."
+var releaseKey string
+
+var (
+	releaseURL     string
+	releaseKeyFlag string
+	updateCheck    bool
+	updateForce    bool
+)
+
+var updateCmd = &cobra.Command{
+	Use:   "update",
+	Short: "Update conduit to the latest release",
+	Long: `Download the latest release for this platform, verify it against the
+release signing key pinned in this build, and replace this binary.
+Running stations keep the old version until they are restarted; see
+'conduit start --auto-update' to update and restart automatically.`,
+	Args: cobra.NoArgs,
+	RunE: runUpdate,
+}
+
+func init() {
+	rootCmd.AddCommand(updateCmd)
+
+	updateCmd.Flags().BoolVar(&updateCheck, "check", false, "only report whether an update is available")
+	updateCmd.Flags().BoolVar(&updateForce, "force", false, "install the latest release even if it isn't newer, or over a development build")
+	addReleaseFlags(updateCmd)
+}
+
+// addReleaseFlags registers the release source flags shared by update and
+// start --auto-update
+func addReleaseFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&releaseURL, "release-url", update.DefaultFeedURL, "release feed (GitHub releases API format)")
+	cmd.Flags().StringVar(&releaseKeyFlag, "release-key", "", "base64 Ed25519 public key that signs release checksums (default: the key pinned in this build)")
+}
+
+func newUpdater() (*update.Updater, error) {
+	key := releaseKey
+	if releaseKeyFlag != "" {
+		key = releaseKeyFlag
+	}
+	if key == "" {
+		return update.New(update.Options{FeedURL: releaseURL})
+	}
+	publicKey, err := crypto.ParseEd25519PublicKey(key)
+	if err != nil {
+		return nil, fmt.Errorf("release key: %w", err)
+	}
+	return update.New(update.Options{FeedURL: releaseURL, PublicKey: publicKey})
+}
+
+func runUpdate(cmd *cobra.Command, args []string) error {
+	updater, err := newUpdater()
+	if err != nil {
+		return err
+	}
+	cmd.SilenceUsage = true
+
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Minute)
+	defer cancel()
+	release, err := updater.Latest(ctx)
+	if err != nil {
+		return err
+	}
+
+	newer := update.Newer(release.Version, version)
+	switch {
+	case !update.IsRelease(version):
+		fmt.Printf("conduit %s is a development build; the latest release is %s\n", version, release.Version)
+	case newer:
+		fmt.Printf("Update available: %s -> %s\n", version, release.Version)
+	default:
+		fmt.Printf("[OK] conduit %s is up to date\n", version)
+	}
+	if updateCheck {
+		return nil
+	}
+	if !updateForce {
+		if !update.IsRelease(version) {
+			return fmt.Errorf("use --force to replace a development build")
+		}
+		if !newer {
+			return nil
+		}
+	}
+
+	path, err := update.Executable()
+	if err != nil {
+		return fmt.Errorf("failed to find this binary: %w", err)
+	}
+	binary, err := updater.Download(ctx, release)
+	if err != nil {
+		return err
+	}
+	if err := update.Install(binary, path, release.Version); err != nil {
+		return fmt.Errorf("failed to install update: %w", err)
+	}
+	fmt.Printf("[OK] Updated %s to %s\n", path, release.Version)
+	fmt.Println("Restart running stations to use it, e.g. 'sudo systemctl restart conduit'")
+	return nil
+}
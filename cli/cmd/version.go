@@ -0,0 +1,100 @@
+/*
+ * Copyright (c) 2026, Psiphon Inc.
+ * All rights reserved.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"runtime"
+
+	"github.com/Psiphon-Inc/conduit/cli/internal/config"
+	"github.com/Psiphon-Labs/psiphon-tunnel-core/psiphon/common/buildinfo"
+	"github.com/spf13/cobra"
+)
+
+var versionJSON bool
+
+var versionCmd = &cobra.Command{
+	Use:   "version",
+	Short: "Print the version",
+	Long: `Print the version. With --verbose, also print tunnel-core's build info and
+the embedded Psiphon config's fingerprint, PropagationChannelId, SponsorId
+and ConfigVersion.`,
+	Args: cobra.NoArgs,
+	RunE: runVersion,
+}
+
+func init() {
+	rootCmd.AddCommand(versionCmd)
+
+	versionCmd.Flags().BoolVar(&versionJSON, "json", false, "print all version details as JSON")
+}
+
+// versionInfo is the output of 'conduit version --json'
+type versionInfo struct {
+	Version        string                    `json:"version"`
+	GoVersion      string                    `json:"goVersion"`
+	Platform       string                    `json:"platform"`
+	TunnelCore     *buildinfo.BuildInfo      `json:"tunnelCore"`
+	EmbeddedConfig *config.PsiphonConfigInfo `json:"embeddedConfig"` // null without an embedded config
+}
+
+func runVersion(cmd *cobra.Command, args []string) error {
+	info := versionInfo{
+		Version:    version,
+		GoVersion:  runtime.Version(),
+		Platform:   runtime.GOOS + "/" + runtime.GOARCH,
+		TunnelCore: buildinfo.GetBuildInfo(),
+	}
+	if embedded, ok := config.EmbeddedPsiphonConfigInfo(); ok {
+		info.EmbeddedConfig = &embedded
+	}
+
+	if versionJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(info)
+	}
+
+	fmt.Printf("conduit %s\n", info.Version)
+	if Verbosity() == 0 {
+		return nil
+	}
+	fmt.Printf("Go:          %s %s\n", info.GoVersion, info.Platform)
+	if tc := info.TunnelCore; tc.BuildRev != "" {
+		fmt.Printf("Tunnel-core: %s@%s (built %s, values %s)\n", tc.BuildRepo, tc.BuildRev, tc.BuildDate, tc.ValuesRev)
+	} else {
+		fmt.Println("Tunnel-core: unknown (not a release build)")
+	}
+	if info.EmbeddedConfig == nil {
+		fmt.Println("Embedded config: none")
+		return nil
+	}
+	c := info.EmbeddedConfig
+	fmt.Println("Embedded config:")
+	fmt.Printf("  Fingerprint:          sha256:%s\n", c.Fingerprint)
+	fmt.Printf("  PropagationChannelId: %s\n", c.PropagationChannelId)
+	fmt.Printf("  SponsorId:            %s\n", c.SponsorId)
+	if c.Version != "" {
+		fmt.Printf("  ConfigVersion:        %s\n", c.Version)
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+/*
+ * Copyright (c) 2026, Psiphon Inc.
+ * All rights reserved.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Psiphon-Inc/conduit/cli/internal/alert"
+	"github.com/Psiphon-Inc/conduit/cli/internal/config"
+	"github.com/spf13/cobra"
+)
+
+var webhooksCmd = &cobra.Command{
+	Use:   "webhooks",
+	Short: "Manage alert webhooks",
+}
+
+var webhooksTestCmd = &cobra.Command{
+	Use:   "test <config.json>",
+	Short: "Send a test alert to every configured webhook",
+	Long: `Validate a webhook config file and send a test alert to every webhook in it,
+without retries or rate limits. Use the same file with 'conduit start --webhooks'.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runWebhooksTest,
+}
+
+func init() {
+	rootCmd.AddCommand(webhooksCmd)
+	webhooksCmd.AddCommand(webhooksTestCmd)
+}
+
+func runWebhooksTest(cmd *cobra.Command, args []string) error {
+	c, err := alert.LoadConfig(args[0])
+	if err != nil {
+		return err
+	}
+	for i := range c.Webhooks {
+		c.Webhooks[i].Retries = -1
+	}
+
+	name, err := config.LoadStationName(GetDataDir())
+	if err != nil {
+		return err
+	}
+	err = alert.New(c, name, "").Send(context.Background(), alert.Alert{
+		Kind:    alert.KindTest,
+		Title:   "Test alert",
+		Message: "Webhook alerts from this Conduit station are working.",
+	})
+	if err != nil {
+		return fmt.Errorf("failed to send test alert: %w", err)
+	}
+	fmt.Printf("[OK] Test alert sent to %d webhook(s)\n", len(c.Webhooks))
+	return nil
+}
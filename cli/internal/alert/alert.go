@@ -0,0 +1,369 @@
+/*
+ * Copyright (c) 2026, Psiphon Inc.
+ * All rights reserved.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+// Package alert sends webhook notifications for station events
+package alert
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Psiphon-Inc/conduit/cli/internal/conduit"
+	"github.com/Psiphon-Inc/conduit/cli/internal/events"
+)
+
+// Kind identifies an alert
+type Kind string
+
+const (
+	KindBrokerDown Kind = "broker_down" // broker disconnected for longer than brokerDownAfter
+	KindBrokerUp   Kind = "broker_up"   // broker reconnected after a broker_down alert
+	KindUpgrade    Kind = "upgrade"     // a newer version of Conduit is required
+	KindQuota      Kind = "quota"       // the day's traffic reached dailyQuotaGB
+	KindRestarts   Kind = "restarts"    // repeated restarts within restartWindow
+	KindSummary    Kind = "summary"     // daily summary
+	KindTest       Kind = "test"        // sent by 'conduit webhooks test'
+)
+
+func (k Kind) valid() bool {
+	switch k {
+	case KindBrokerDown, KindBrokerUp, KindUpgrade, KindQuota, KindRestarts, KindSummary, KindTest:
+		return true
+	}
+	return false
+}
+
+// upgradeCooldown limits upgrade alerts, which are repeated on every restart
+const upgradeCooldown = 24 * time.Hour
+
+// checkInterval is how often time-based alerts are checked
+const checkInterval = 15 * time.Second
+
+// Alert is a notification sent to webhooks
+type Alert struct {
+	Kind    Kind           `json:"alert"`
+	Title   string         `json:"title"`
+	Message string         `json:"message"`
+	Station string         `json:"station,omitempty"`
+	ProxyID string         `json:"proxyId,omitempty"`
+	Time    time.Time      `json:"time"`
+	Data    map[string]any `json:"data,omitempty"`
+}
+
+// Summary is the activity since the previous daily summary
+type Summary struct {
+	Connections     int            `json:"connections"`
+	BytesUp         int64          `json:"bytesUp"`
+	BytesDown       int64          `json:"bytesDown"`
+	PeakClients     int            `json:"peakClients"`
+	Restarts        int            `json:"restarts"`
+	Errors          int            `json:"errors"`
+	BrokerDownCount int            `json:"brokerDownCount"`
+	Countries       map[string]int `json:"countries,omitempty"`
+}
+
+// Alerter turns station events into alerts
+type Alerter struct {
+	config  *Config
+	station string
+	proxyID string
+	hooks   []*webhook
+
+	brokerConnected bool
+	brokerDownSince time.Time
+	brokerAlerted   bool
+	lastUpgrade     time.Time
+	restarts        []time.Time
+	lastRestarts    time.Time
+	day             string
+	dayBytes        int64
+	quotaAlerted    bool
+	summaryDay      string
+	summary         Summary
+}
+
+// New creates an alerter for a station
+func New(c *Config, station, proxyID string) *Alerter {
+	a := &Alerter{config: c, station: station, proxyID: proxyID}
+	for _, hc := range c.Webhooks {
+		a.hooks = append(a.hooks, newWebhook(hc))
+	}
+	return a
+}
+
+// Start sends alerts for events published to bus in the background, until
+// ctx is done
+func (a *Alerter) Start(ctx context.Context, bus *events.Bus) {
+	for _, h := range a.hooks {
+		go h.run(ctx)
+	}
+	a.start(time.Now())
+	go a.run(ctx, bus, bus.Subscribe(0))
+}
+
+func (a *Alerter) run(ctx context.Context, bus *events.Bus, sub *events.Subscription) {
+	ticker := time.NewTicker(checkInterval)
+	defer ticker.Stop()
+
+	var lastID uint64
+	defer func() { sub.Close() }()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			a.send(a.check(now))
+		case event, ok := <-sub.Events():
+			if !ok {
+				// Fell behind: resume from the last event handled
+				sub = bus.Subscribe(lastID)
+				continue
+			}
+			lastID = event.ID
+			a.send(a.handle(event, time.Now()))
+		}
+	}
+}
+
+// Send delivers an alert to all webhooks that accept it and waits for the
+// deliveries to finish. Used to test the configuration.
+func (a *Alerter) Send(ctx context.Context, alert Alert) error {
+	a.stamp(&alert, time.Now())
+	var errs []string
+	for _, h := range a.hooks {
+		if !h.accepts(alert.Kind) {
+			continue
+		}
+		if err := h.deliver(ctx, alert); err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %v", redactURL(h.config.URL), err))
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("%s", strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+func (a *Alerter) send(alerts []Alert) {
+	for _, alert := range alerts {
+		for _, h := range a.hooks {
+			if h.accepts(alert.Kind) {
+				h.enqueue(alert)
+			}
+		}
+	}
+}
+
+func (a *Alerter) stamp(alert *Alert, now time.Time) {
+	alert.Station = a.station
+	alert.ProxyID = a.proxyID
+	alert.Time = now.UTC()
+	if a.station != "" {
+		alert.Title = "[" + a.station + "] " + alert.Title
+	}
+}
+
+func (a *Alerter) alert(now time.Time, kind Kind, title, message string, data map[string]any) Alert {
+	alert := Alert{Kind: kind, Title: title, Message: message, Data: data}
+	a.stamp(&alert, now)
+	return alert
+}
+
+// start initializes the alert state. The broker counts as down until the
+// first connection, so a station that never connects is also reported.
+func (a *Alerter) start(now time.Time) {
+	a.brokerDownSince = now
+	a.day = now.Format(time.DateOnly)
+	a.summaryDay = a.day
+	if a.config.SummaryAt != "" && !a.summaryDue(now) {
+		// The first summary is today's, if its time hasn't passed yet
+		a.summaryDay = now.AddDate(0, 0, -1).Format(time.DateOnly)
+	}
+}
+
+// handle updates the state for an event and returns the alerts it triggers
+func (a *Alerter) handle(event events.Event, now time.Time) []Alert {
+	var alerts []Alert
+	switch event.Type {
+	case events.TypeBroker:
+		broker, _ := event.Data.(events.Broker)
+		if broker.Connected == a.brokerConnected {
+			break
+		}
+		a.brokerConnected = broker.Connected
+		if !broker.Connected {
+			a.brokerDownSince = now
+			break
+		}
+		if a.brokerAlerted {
+			a.brokerAlerted = false
+			down := now.Sub(a.brokerDownSince).Round(time.Second)
+			alerts = append(alerts, a.alert(now, KindBrokerUp, "Broker reconnected",
+				fmt.Sprintf("Connected to the Psiphon broker again after %s.", down),
+				map[string]any{"downSeconds": int64(down.Seconds())}))
+		}
+
+	case events.TypeUpgrade:
+		if a.lastUpgrade.IsZero() || now.Sub(a.lastUpgrade) >= upgradeCooldown {
+			a.lastUpgrade = now
+			alerts = append(alerts, a.alert(now, KindUpgrade, "Upgrade required",
+				"The broker requires a newer version of Conduit. The station won't serve clients until it is upgraded.", nil))
+		}
+
+	case events.TypeRestart:
+		a.summary.Restarts++
+		a.restarts = append(a.restarts, now)
+		var recent []time.Time
+		for _, t := range a.restarts {
+			if now.Sub(t) < a.config.restartWindow {
+				recent = append(recent, t)
+			}
+		}
+		a.restarts = recent
+		if len(recent) >= a.config.Restarts && (a.lastRestarts.IsZero() || now.Sub(a.lastRestarts) >= a.config.restartWindow) {
+			a.lastRestarts = now
+			restart, _ := event.Data.(events.Restart)
+			alerts = append(alerts, a.alert(now, KindRestarts, "Repeated restarts",
+				fmt.Sprintf("Restarted %d times in %s (last reason: %s).", len(recent), a.config.restartWindow, restart.Reason),
+				map[string]any{"restarts": len(recent), "reason": restart.Reason}))
+		}
+
+	case events.TypeError:
+		a.summary.Errors++
+
+	case events.TypeStats:
+		if stats, ok := event.Data.(conduit.StatsJSON); ok && stats.ConnectedClients > a.summary.PeakClients {
+			a.summary.PeakClients = stats.ConnectedClients
+		}
+
+	case events.TypeConnectionEstablished:
+		a.summary.Connections++
+		if conn, _ := event.Data.(events.Connection); conn.Country != "" {
+			if a.summary.Countries == nil {
+				a.summary.Countries = make(map[string]int)
+			}
+			a.summary.Countries[conn.Country]++
+		}
+
+	case events.TypeConnectionClosed:
+		conn, _ := event.Data.(events.Connection)
+		a.summary.BytesUp += conn.BytesUp
+		a.summary.BytesDown += conn.BytesDown
+		alerts = append(alerts, a.addTraffic(now, conn.BytesUp+conn.BytesDown)...)
+	}
+	return alerts
+}
+
+// addTraffic counts traffic towards the daily quota
+func (a *Alerter) addTraffic(now time.Time, bytes int64) []Alert {
+	a.rollDay(now)
+	a.dayBytes += bytes
+	quota := int64(a.config.DailyQuotaGB * 1e9)
+	if quota <= 0 || a.quotaAlerted || a.dayBytes < quota {
+		return nil
+	}
+	a.quotaAlerted = true
+	return []Alert{a.alert(now, KindQuota, "Daily traffic quota reached",
+		fmt.Sprintf("Relayed %.1f GB today, reaching the quota of %g GB.", float64(a.dayBytes)/1e9, a.config.DailyQuotaGB),
+		map[string]any{"bytes": a.dayBytes, "quotaBytes": quota})}
+}
+
+func (a *Alerter) rollDay(now time.Time) {
+	if day := now.Format(time.DateOnly); day != a.day {
+		a.day = day
+		a.dayBytes = 0
+		a.quotaAlerted = false
+	}
+}
+
+// check returns the time-based alerts due at now
+func (a *Alerter) check(now time.Time) []Alert {
+	var alerts []Alert
+	a.rollDay(now)
+
+	if !a.brokerConnected && !a.brokerAlerted && now.Sub(a.brokerDownSince) >= a.config.brokerDownAfter {
+		a.brokerAlerted = true
+		a.summary.BrokerDownCount++
+		down := now.Sub(a.brokerDownSince).Round(time.Second)
+		alerts = append(alerts, a.alert(now, KindBrokerDown, "Broker disconnected",
+			fmt.Sprintf("Not connected to the Psiphon broker for %s. The station is not serving clients.", down),
+			map[string]any{"downSeconds": int64(down.Seconds())}))
+	}
+
+	if a.config.SummaryAt != "" && a.summaryDue(now) && a.summaryDay != now.Format(time.DateOnly) {
+		a.summaryDay = now.Format(time.DateOnly)
+		alerts = append(alerts, a.alert(now, KindSummary, "Daily summary", a.summary.String(),
+			map[string]any{"summary": a.summary}))
+		a.summary = Summary{}
+	}
+	return alerts
+}
+
+// summaryDue reports whether today's summary time has passed
+func (a *Alerter) summaryDue(now time.Time) bool {
+	at := time.Date(now.Year(), now.Month(), now.Day(), a.config.summaryHour, a.config.summaryMinute, 0, 0, now.Location())
+	return !now.Before(at)
+}
+
+// String formats the summary as a message
+func (s Summary) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Connections: %d (peak %d connected)\n", s.Connections, s.PeakClients)
+	fmt.Fprintf(&b, "Traffic: %s up, %s down\n", formatBytes(s.BytesUp), formatBytes(s.BytesDown))
+	fmt.Fprintf(&b, "Restarts: %d | Broker outages: %d | Errors: %d", s.Restarts, s.BrokerDownCount, s.Errors)
+
+	codes := make([]string, 0, len(s.Countries))
+	for code := range s.Countries {
+		codes = append(codes, code)
+	}
+	sort.Slice(codes, func(i, j int) bool {
+		if s.Countries[codes[i]] != s.Countries[codes[j]] {
+			return s.Countries[codes[i]] > s.Countries[codes[j]]
+		}
+		return codes[i] < codes[j]
+	})
+	if len(codes) > 5 {
+		codes = codes[:5]
+	}
+	for i, code := range codes {
+		if i == 0 {
+			b.WriteString("\nTop countries: ")
+		} else {
+			b.WriteString(", ")
+		}
+		fmt.Fprintf(&b, "%s %d", code, s.Countries[code])
+	}
+	return b.String()
+}
+
+func formatBytes(bytes int64) string {
+	const unit = 1024
+	if bytes < unit {
+		return fmt.Sprintf("%d B", bytes)
+	}
+	div, exp := int64(unit), 0
+	for n := bytes / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(bytes)/float64(div), "KMGTPE"[exp])
+}
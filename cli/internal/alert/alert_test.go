@@ -0,0 +1,268 @@
+/*
+ * Copyright (c) 2026, Psiphon Inc.
+ * All rights reserved.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package alert
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Psiphon-Inc/conduit/cli/internal/conduit"
+	"github.com/Psiphon-Inc/conduit/cli/internal/events"
+)
+
+func TestConfigValidate(t *testing.T) {
+	tests := []struct {
+		config string
+		valid  bool
+	}{
+		{`{"webhooks":[{"url":"https://example.com/hook"}]}`, true},
+		{`{"webhooks":[]}`, false},
+		{`{"webhooks":[{"url":"ftp://example.com"}]}`, false},
+		{`{"webhooks":[{"url":"https://example.com","format":"teams"}]}`, false},
+		{`{"webhooks":[{"url":"https://matrix.org","format":"matrix"}]}`, false},
+		{`{"webhooks":[{"url":"https://matrix.org","format":"matrix","room":"!r:matrix.org","token":"t"}]}`, true},
+		{`{"webhooks":[{"url":"https://example.com","alerts":["upgrade","nope"]}]}`, false},
+		{`{"webhooks":[{"url":"https://example.com"}],"brokerDownAfter":"soon"}`, false},
+		{`{"webhooks":[{"url":"https://example.com"}],"summaryAt":"25:00"}`, false},
+		{`{"webhooks":[{"url":"https://example.com"}],"summaryAt":"09:30","dailyQuotaGB":50}`, true},
+	}
+	for _, tt := range tests {
+		var c Config
+		if err := json.Unmarshal([]byte(tt.config), &c); err != nil {
+			t.Fatalf("unmarshal %s: %v", tt.config, err)
+		}
+		if err := c.Validate(); (err == nil) != tt.valid {
+			t.Fatalf("Validate(%s) = %v, expected valid=%v", tt.config, err, tt.valid)
+		}
+	}
+}
+
+func newTestAlerter(t *testing.T, config string, now time.Time) *Alerter {
+	var c Config
+	if err := json.Unmarshal([]byte(config), &c); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if err := c.Validate(); err != nil {
+		t.Fatalf("Validate: %v", err)
+	}
+	a := New(&c, "berlin-1", "proxy-id")
+	a.start(now)
+	return a
+}
+
+func kinds(alerts []Alert) string {
+	var s []string
+	for _, a := range alerts {
+		s = append(s, string(a.Kind))
+	}
+	return strings.Join(s, ",")
+}
+
+func TestAlerter(t *testing.T) {
+	start := time.Date(2026, 3, 1, 8, 0, 0, 0, time.Local)
+	a := newTestAlerter(t, `{
+		"webhooks": [{"url": "https://example.com"}],
+		"brokerDownAfter": "5m",
+		"restarts": 2, "restartWindow": "1h",
+		"dailyQuotaGB": 1,
+		"summaryAt": "09:00"
+	}`, start)
+	at := func(d time.Duration) time.Time { return start.Add(d) }
+	event := func(t events.Type, data any) events.Event { return events.Event{Type: t, Data: data} }
+
+	steps := []struct {
+		name     string
+		alerts   func() []Alert
+		expected string
+	}{
+		{"never connected", func() []Alert { return a.check(at(5 * time.Minute)) }, "broker_down"},
+		{"alerted once", func() []Alert { return a.check(at(6 * time.Minute)) }, ""},
+		{"reconnected", func() []Alert {
+			return a.handle(event(events.TypeBroker, events.Broker{Connected: true}), at(7*time.Minute))
+		}, "broker_up"},
+		{"short outage", func() []Alert {
+			a.handle(event(events.TypeBroker, events.Broker{Connected: false}), at(8*time.Minute))
+			a.handle(event(events.TypeBroker, events.Broker{Connected: true}), at(10*time.Minute))
+			return a.check(at(20 * time.Minute))
+		}, ""},
+		{"upgrade", func() []Alert { return a.handle(event(events.TypeUpgrade, events.Upgrade{}), at(21*time.Minute)) }, "upgrade"},
+		{"upgrade cooldown", func() []Alert { return a.handle(event(events.TypeUpgrade, events.Upgrade{}), at(22*time.Minute)) }, ""},
+		{"first restart", func() []Alert {
+			return a.handle(event(events.TypeRestart, events.Restart{Reason: "idle"}), at(23*time.Minute))
+		}, ""},
+		{"repeated restarts", func() []Alert {
+			return a.handle(event(events.TypeRestart, events.Restart{Reason: "idle"}), at(24*time.Minute))
+		}, "restarts"},
+		{"traffic under quota", func() []Alert {
+			a.handle(event(events.TypeStats, conduit.StatsJSON{ConnectedClients: 12}), at(25*time.Minute))
+			a.handle(event(events.TypeConnectionEstablished, events.Connection{Country: "IR"}), at(25*time.Minute))
+			return a.handle(event(events.TypeConnectionClosed, events.Connection{BytesUp: 4e8, BytesDown: 4e8}), at(26*time.Minute))
+		}, ""},
+		{"quota reached", func() []Alert {
+			return a.handle(event(events.TypeConnectionClosed, events.Connection{BytesUp: 1e8, BytesDown: 1e8}), at(27*time.Minute))
+		}, "quota"},
+		{"quota alerted once", func() []Alert {
+			return a.handle(event(events.TypeConnectionClosed, events.Connection{BytesUp: 1e8}), at(28*time.Minute))
+		}, ""},
+		{"daily summary", func() []Alert { return a.check(at(time.Hour)) }, "summary"},
+		{"summary sent once", func() []Alert { return a.check(at(2 * time.Hour)) }, ""},
+		{"next summary", func() []Alert { return a.check(at(25 * time.Hour)) }, "summary"},
+	}
+	var summary Summary
+	for _, step := range steps {
+		alerts := step.alerts()
+		if got := kinds(alerts); got != step.expected {
+			t.Fatalf("%s: got alerts %q, expected %q", step.name, got, step.expected)
+		}
+		for _, alert := range alerts {
+			if alert.Station != "berlin-1" || !strings.HasPrefix(alert.Title, "[berlin-1] ") {
+				t.Fatalf("%s: alert not stamped with the station: %+v", step.name, alert)
+			}
+			if alert.Kind == KindSummary && summary.Connections == 0 {
+				summary = alert.Data["summary"].(Summary)
+			}
+		}
+	}
+	if summary.Connections != 1 || summary.PeakClients != 12 || summary.Restarts != 2 ||
+		summary.BrokerDownCount != 1 || summary.BytesUp != 6e8 || summary.Countries["IR"] != 1 {
+		t.Fatalf("unexpected summary %+v", summary)
+	}
+}
+
+// A long-running station loses and regains the broker mid-run
+func TestBrokerOutage(t *testing.T) {
+	start := time.Date(2026, 3, 1, 8, 0, 0, 0, time.Local)
+	a := newTestAlerter(t, `{"webhooks": [{"url": "https://example.com"}], "brokerDownAfter": "5m"}`, start)
+	at := func(d time.Duration) time.Time { return start.Add(d) }
+	broker := func(connected bool, d time.Duration) []Alert {
+		return a.handle(events.Event{Type: events.TypeBroker, Data: events.Broker{Connected: connected}}, at(d))
+	}
+
+	steps := []struct {
+		name     string
+		alerts   func() []Alert
+		expected string
+	}{
+		{"connected", func() []Alert { return broker(true, time.Minute) }, ""},
+		{"running for a day", func() []Alert { return a.check(at(26 * time.Hour)) }, ""},
+		{"disconnected", func() []Alert { return broker(false, 26*time.Hour) }, ""},
+		{"within brokerDownAfter", func() []Alert { return a.check(at(26*time.Hour + 4*time.Minute)) }, ""},
+		{"down", func() []Alert { return a.check(at(26*time.Hour + 5*time.Minute)) }, "broker_down"},
+		{"alerted once", func() []Alert { return a.check(at(26*time.Hour + 8*time.Minute)) }, ""},
+		{"reconnected", func() []Alert { return broker(true, 26*time.Hour+9*time.Minute) }, "broker_up"},
+		{"still connected", func() []Alert { return broker(true, 27*time.Hour) }, ""},
+		{"second outage", func() []Alert {
+			broker(false, 30*time.Hour)
+			return a.check(at(30*time.Hour + 6*time.Minute))
+		}, "broker_down"},
+		{"second reconnect", func() []Alert { return broker(true, 30*time.Hour+7*time.Minute) }, "broker_up"},
+	}
+	for _, step := range steps {
+		alerts := step.alerts()
+		if got := kinds(alerts); got != step.expected {
+			t.Fatalf("%s: got alerts %q, expected %q", step.name, got, step.expected)
+		}
+		if step.name == "reconnected" && alerts[0].Data["downSeconds"] != int64(540) {
+			t.Fatalf("reconnected: unexpected data %v", alerts[0].Data)
+		}
+	}
+}
+
+func TestWebhookFormats(t *testing.T) {
+	var last *http.Request
+	var lastBody string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		last, lastBody = r, string(body)
+	}))
+	defer server.Close()
+
+	alert := Alert{Kind: KindUpgrade, Title: "Upgrade required", Message: "Please upgrade."}
+	tests := []struct {
+		config WebhookConfig
+		check  func() bool
+	}{
+		{WebhookConfig{URL: server.URL, Secret: "s3cret"}, func() bool {
+			return last.Method == "POST" && strings.Contains(lastBody, `"alert":"upgrade"`) &&
+				last.Header.Get(SignatureHeader) == Sign("s3cret", []byte(lastBody))
+		}},
+		{WebhookConfig{URL: server.URL, Format: FormatSlack}, func() bool {
+			return lastBody == `{"text":"*Upgrade required*\nPlease upgrade."}` && last.Header.Get(SignatureHeader) == ""
+		}},
+		{WebhookConfig{URL: server.URL, Format: FormatDiscord}, func() bool {
+			return lastBody == `{"content":"**Upgrade required**\nPlease upgrade."}`
+		}},
+		{WebhookConfig{URL: server.URL + "/topic", Format: FormatNtfy, Token: "tk"}, func() bool {
+			return lastBody == "Please upgrade." && last.Header.Get("Title") == "Upgrade required" &&
+				last.Header.Get("Priority") == "high" && last.Header.Get("Authorization") == "Bearer tk"
+		}},
+		{WebhookConfig{URL: server.URL, Format: FormatMatrix, Room: "!room:example.org", Token: "tk"}, func() bool {
+			return last.Method == "PUT" && strings.HasPrefix(last.URL.EscapedPath(), "/_matrix/client/v3/rooms/%21room:example.org/send/m.room.message/conduit-") &&
+				lastBody == `{"body":"Upgrade required\nPlease upgrade.","msgtype":"m.text"}`
+		}},
+	}
+	for _, tt := range tests {
+		if err := tt.config.validate(); err != nil {
+			t.Fatalf("validate: %v", err)
+		}
+		if err := newWebhook(tt.config).deliver(context.Background(), alert); err != nil {
+			t.Fatalf("%s: deliver: %v", tt.config.Format, err)
+		}
+		if !tt.check() {
+			t.Fatalf("%s: unexpected request %s %s %v %q", tt.config.Format, last.Method, last.URL, last.Header, lastBody)
+		}
+	}
+}
+
+func TestWebhookRetries(t *testing.T) {
+	retryBackoff = time.Millisecond
+	var requests atomic.Int32
+	status := http.StatusServiceUnavailable
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if requests.Add(1) < 3 {
+			w.WriteHeader(status)
+		}
+	}))
+	defer server.Close()
+
+	h := newWebhook(WebhookConfig{URL: server.URL, Retries: 3, MaxPerHour: 2})
+	if err := h.deliver(context.Background(), Alert{Kind: KindTest}); err != nil || requests.Load() != 3 {
+		t.Fatalf("deliver after transient errors = %v with %d requests", err, requests.Load())
+	}
+
+	// Client errors are not retried
+	requests.Store(0)
+	status = http.StatusBadRequest
+	if err := h.deliver(context.Background(), Alert{Kind: KindTest}); err == nil || requests.Load() != 1 {
+		t.Fatalf("deliver with a client error = %v with %d requests", err, requests.Load())
+	}
+
+	now := time.Now()
+	if !h.allow(now) || !h.allow(now) || h.allow(now) || !h.allow(now.Add(time.Hour)) {
+		t.Fatalf("rate limit of 2 per hour not applied")
+	}
+}
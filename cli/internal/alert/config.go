@@ -0,0 +1,176 @@
+/*
+ * Copyright (c) 2026, Psiphon Inc.
+ * All rights reserved.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package alert
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"time"
+)
+
+// Webhook formats
+const (
+	FormatGeneric = "generic"
+	FormatSlack   = "slack"
+	FormatDiscord = "discord"
+	FormatNtfy    = "ntfy"
+	FormatMatrix  = "matrix"
+)
+
+// Defaults for unset config values
+const (
+	DefaultBrokerDownAfter = 5 * time.Minute
+	DefaultRestarts        = 3
+	DefaultRestartWindow   = time.Hour
+	DefaultMaxPerHour      = 20
+	DefaultRetries         = 3
+)
+
+// Config is the webhook configuration file
+type Config struct {
+	Webhooks []WebhookConfig `json:"webhooks"`
+
+	BrokerDownAfter string  `json:"brokerDownAfter,omitempty"` // alert after the broker is down this long
+	Restarts        int     `json:"restarts,omitempty"`        // alert after this many restarts...
+	RestartWindow   string  `json:"restartWindow,omitempty"`   // ...within this window
+	DailyQuotaGB    float64 `json:"dailyQuotaGB,omitempty"`    // alert when a day's traffic reaches this (0 = disabled)
+	SummaryAt       string  `json:"summaryAt,omitempty"`       // local time of the daily summary, e.g. "09:00" (empty = disabled)
+
+	brokerDownAfter time.Duration
+	restartWindow   time.Duration
+	summaryHour     int
+	summaryMinute   int
+}
+
+// WebhookConfig is a single webhook
+type WebhookConfig struct {
+	URL     string            `json:"url"`
+	Format  string            `json:"format,omitempty"`  // generic (default), slack, discord, ntfy or matrix
+	Secret  string            `json:"secret,omitempty"`  // HMAC-SHA256 key for the X-Conduit-Signature header
+	Token   string            `json:"token,omitempty"`   // bearer token (ntfy, Matrix access token)
+	Room    string            `json:"room,omitempty"`    // Matrix room ID
+	Headers map[string]string `json:"headers,omitempty"` // extra request headers
+	Alerts  []Kind            `json:"alerts,omitempty"`  // alerts to send (empty = all)
+
+	MaxPerHour int `json:"maxPerHour,omitempty"` // rate limit; further alerts are dropped
+	Retries    int `json:"retries,omitempty"`    // retries of a failed delivery (-1 = none)
+}
+
+// LoadConfig reads and validates a webhook configuration file
+func LoadConfig(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read webhook config: %w", err)
+	}
+	var c Config
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, fmt.Errorf("failed to parse webhook config: %w", err)
+	}
+	if err := c.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid webhook config: %w", err)
+	}
+	return &c, nil
+}
+
+// Validate checks the config and applies defaults
+func (c *Config) Validate() error {
+	if len(c.Webhooks) == 0 {
+		return fmt.Errorf("no webhooks configured")
+	}
+	for i := range c.Webhooks {
+		if err := c.Webhooks[i].validate(); err != nil {
+			return fmt.Errorf("webhook %d: %w", i+1, err)
+		}
+	}
+
+	var err error
+	if c.brokerDownAfter, err = parseDuration(c.BrokerDownAfter, DefaultBrokerDownAfter); err != nil {
+		return fmt.Errorf("brokerDownAfter: %w", err)
+	}
+	if c.restartWindow, err = parseDuration(c.RestartWindow, DefaultRestartWindow); err != nil {
+		return fmt.Errorf("restartWindow: %w", err)
+	}
+	if c.Restarts == 0 {
+		c.Restarts = DefaultRestarts
+	}
+	if c.Restarts < 1 {
+		return fmt.Errorf("restarts must be at least 1")
+	}
+	if c.DailyQuotaGB < 0 {
+		return fmt.Errorf("dailyQuotaGB must not be negative")
+	}
+	if c.SummaryAt != "" {
+		t, err := time.Parse("15:04", c.SummaryAt)
+		if err != nil {
+			return fmt.Errorf("summaryAt must be a time like 09:00")
+		}
+		c.summaryHour, c.summaryMinute = t.Hour(), t.Minute()
+	}
+	return nil
+}
+
+func (w *WebhookConfig) validate() error {
+	u, err := url.Parse(w.URL)
+	if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+		return fmt.Errorf("invalid url %q", w.URL)
+	}
+	switch w.Format {
+	case "":
+		w.Format = FormatGeneric
+	case FormatGeneric, FormatSlack, FormatDiscord, FormatNtfy:
+	case FormatMatrix:
+		if w.Room == "" || w.Token == "" {
+			return fmt.Errorf("matrix webhooks require room and token")
+		}
+	default:
+		return fmt.Errorf("unknown format %q", w.Format)
+	}
+	for _, kind := range w.Alerts {
+		if !kind.valid() {
+			return fmt.Errorf("unknown alert %q", kind)
+		}
+	}
+	if w.MaxPerHour == 0 {
+		w.MaxPerHour = DefaultMaxPerHour
+	}
+	if w.Retries == 0 {
+		w.Retries = DefaultRetries
+	}
+	if w.MaxPerHour < 1 || w.Retries < -1 {
+		return fmt.Errorf("maxPerHour must be at least 1 and retries at least -1")
+	}
+	return nil
+}
+
+func parseDuration(s string, def time.Duration) (time.Duration, error) {
+	if s == "" {
+		return def, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, err
+	}
+	if d <= 0 {
+		return 0, fmt.Errorf("must be positive")
+	}
+	return d, nil
+}
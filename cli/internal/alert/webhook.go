@@ -0,0 +1,237 @@
+/*
+ * Copyright (c) 2026, Psiphon Inc.
+ * All rights reserved.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package alert
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Psiphon-Inc/conduit/cli/internal/config"
+)
+
+const (
+	// SignatureHeader holds "sha256=" and the hex HMAC-SHA256 of the request body
+	SignatureHeader = "X-Conduit-Signature"
+
+	queueSize      = 32
+	requestTimeout = 10 * time.Second
+	maxBackoff     = time.Minute
+)
+
+// retryBackoff is the delay before the first retry, doubled for each retry
+var retryBackoff = 2 * time.Second
+
+// webhook delivers alerts to a single URL in the background
+type webhook struct {
+	config WebhookConfig
+	client *http.Client
+	queue  chan Alert
+	sent   []time.Time // delivery times in the last hour, for rate limiting
+}
+
+func newWebhook(c WebhookConfig) *webhook {
+	return &webhook{
+		config: c,
+		client: &http.Client{Timeout: requestTimeout},
+		queue:  make(chan Alert, queueSize),
+	}
+}
+
+func (h *webhook) accepts(kind Kind) bool {
+	return kind == KindTest || len(h.config.Alerts) == 0 || slices.Contains(h.config.Alerts, kind)
+}
+
+// enqueue queues an alert without blocking, dropping it if the queue is full
+func (h *webhook) enqueue(alert Alert) {
+	select {
+	case h.queue <- alert:
+	default:
+		fmt.Printf("[WARN] Webhook queue full, dropped %s alert for %s\n", alert.Kind, redactURL(h.config.URL))
+	}
+}
+
+func (h *webhook) run(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case alert := <-h.queue:
+			if !h.allow(time.Now()) {
+				fmt.Printf("[WARN] Webhook rate limit reached, dropped %s alert for %s\n", alert.Kind, redactURL(h.config.URL))
+				continue
+			}
+			if err := h.deliver(ctx, alert); err != nil && ctx.Err() == nil {
+				fmt.Printf("[ERROR] Webhook %s: %v\n", redactURL(h.config.URL), err)
+			}
+		}
+	}
+}
+
+// allow reports whether another alert may be sent within the hourly limit
+func (h *webhook) allow(now time.Time) bool {
+	recent := h.sent[:0]
+	for _, t := range h.sent {
+		if now.Sub(t) < time.Hour {
+			recent = append(recent, t)
+		}
+	}
+	h.sent = recent
+	if len(h.sent) >= h.config.MaxPerHour {
+		return false
+	}
+	h.sent = append(h.sent, now)
+	return true
+}
+
+// deliver sends an alert, retrying network errors, 429 and 5xx responses
+// with exponential backoff
+func (h *webhook) deliver(ctx context.Context, alert Alert) error {
+	method, target, body, header, err := h.request(alert)
+	if err != nil {
+		return err
+	}
+
+	backoff := retryBackoff
+	for attempt := 0; ; attempt++ {
+		retryAfter, err := h.post(ctx, method, target, body, header)
+		if err == nil {
+			return nil
+		}
+		if retryAfter < 0 || attempt >= h.config.Retries {
+			return err
+		}
+		wait := backoff
+		if retryAfter > wait {
+			wait = min(retryAfter, maxBackoff)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(wait):
+		}
+		backoff = min(backoff*2, maxBackoff)
+	}
+}
+
+// post makes a single request. A negative retryAfter means the error is permanent.
+func (h *webhook) post(ctx context.Context, method, target string, body []byte, header http.Header) (retryAfter time.Duration, err error) {
+	req, err := http.NewRequestWithContext(ctx, method, target, bytes.NewReader(body))
+	if err != nil {
+		return -1, err
+	}
+	req.Header = header.Clone()
+
+	resp, err := h.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+
+	switch {
+	case resp.StatusCode < 300:
+		return 0, nil
+	case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500:
+		seconds, _ := strconv.Atoi(resp.Header.Get("Retry-After"))
+		return time.Duration(seconds) * time.Second, fmt.Errorf("server returned %s", resp.Status)
+	default:
+		return -1, fmt.Errorf("server returned %s", resp.Status)
+	}
+}
+
+// request builds the request for an alert in the webhook's format
+func (h *webhook) request(alert Alert) (method, target string, body []byte, header http.Header, err error) {
+	method, target = http.MethodPost, h.config.URL
+	header = http.Header{}
+	header.Set("User-Agent", "conduit")
+	header.Set("Content-Type", "application/json")
+	text := alert.Title + "\n" + alert.Message
+
+	switch h.config.Format {
+	case FormatSlack:
+		body, err = json.Marshal(map[string]string{"text": "*" + alert.Title + "*\n" + alert.Message})
+	case FormatDiscord:
+		body, err = json.Marshal(map[string]string{"content": "**" + alert.Title + "**\n" + alert.Message})
+	case FormatNtfy:
+		body = []byte(alert.Message)
+		header.Set("Content-Type", "text/plain; charset=utf-8")
+		header.Set("Title", alert.Title)
+		header.Set("Tags", string(alert.Kind))
+		if alert.Kind == KindBrokerDown || alert.Kind == KindUpgrade {
+			header.Set("Priority", "high")
+		}
+	case FormatMatrix:
+		// The transaction ID makes retries idempotent
+		var txn [8]byte
+		rand.Read(txn[:])
+		method = http.MethodPut
+		target = strings.TrimSuffix(h.config.URL, "/") + "/_matrix/client/v3/rooms/" +
+			url.PathEscape(h.config.Room) + "/send/m.room.message/conduit-" + hex.EncodeToString(txn[:])
+		body, err = json.Marshal(map[string]string{"msgtype": "m.text", "body": text})
+	default:
+		body, err = json.Marshal(alert)
+		header.Set("X-Conduit-Alert", string(alert.Kind))
+	}
+	if err != nil {
+		return "", "", nil, nil, fmt.Errorf("failed to encode alert: %w", err)
+	}
+
+	if h.config.Token != "" {
+		header.Set("Authorization", "Bearer "+h.config.Token)
+	}
+	if h.config.Secret != "" {
+		header.Set(SignatureHeader, Sign(h.config.Secret, body))
+	}
+	for name, value := range h.config.Headers {
+		header.Set(name, value)
+	}
+	return method, target, body, header, nil
+}
+
+// Sign returns the signature header value of body
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// redactURL also hides the path of webhook URLs in logs, which is often the
+// secret
+func redactURL(s string) string {
+	u, err := url.Parse(config.RedactURL(s))
+	if err != nil {
+		return "webhook"
+	}
+	u.Path, u.RawPath = "", ""
+	return u.String()
+}
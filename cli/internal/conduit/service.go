@@ -31,10 +31,19 @@ import (
 	"time"
 
 	"github.com/Psiphon-Inc/conduit/cli/internal/config"
+	"github.com/Psiphon-Inc/conduit/cli/internal/crypto"
+	"github.com/Psiphon-Inc/conduit/cli/internal/dashboard"
+	"github.com/Psiphon-Inc/conduit/cli/internal/debug"
+	"github.com/Psiphon-Inc/conduit/cli/internal/events"
 	"github.com/Psiphon-Inc/conduit/cli/internal/geo"
+	"github.com/Psiphon-Inc/conduit/cli/internal/httpserver"
 	"github.com/Psiphon-Inc/conduit/cli/internal/metrics"
+	"github.com/Psiphon-Inc/conduit/cli/internal/systemd"
 	"github.com/Psiphon-Labs/psiphon-tunnel-core/psiphon"
 	"github.com/Psiphon-Labs/psiphon-tunnel-core/psiphon/common/inproxy"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // ErrIdleRestart is returned when the service should restart due to idle timeout
@@ -42,12 +51,25 @@ var ErrIdleRestart = errors.New("idle restart triggered")
 
 // Service represents the Conduit inproxy service
 type Service struct {
-	config       *config.Config
-	controller   *psiphon.Controller
-	stats        *Stats
-	geoCollector *geo.Collector
-	metrics      *metrics.Metrics
-	mu           sync.RWMutex
+	config        *config.Config
+	proxyID       string
+	controller    *psiphon.Controller
+	stats         *Stats
+	geoCollector  *geo.Collector
+	metrics       *metrics.Metrics
+	dashboard     *dashboard.Server
+	notifier      *systemd.Notifier
+	events        *events.Bus
+	notices       *debug.Notices
+	statsWriter   *statsWriter
+	otlp          bool // export metrics and traces with OTLP
+	tracer        trace.Tracer
+	traceCtx      context.Context // carries the controller span
+	announcements []trace.Span
+	lastNotice    time.Time // when the controller last emitted a notice, for the watchdog
+	brokerErrors  int       // consecutive failed broker round trips
+	draining      bool      // announcements stopped by StopAnnouncing
+	mu            sync.RWMutex
 }
 
 // Stats tracks proxy activity statistics
@@ -63,6 +85,8 @@ type Stats struct {
 
 // StatsJSON represents the JSON structure for persisted stats
 type StatsJSON struct {
+	StationName       string       `json:"stationName,omitempty"`
+	ProxyID           string       `json:"proxyId,omitempty"`
 	ConnectingClients int          `json:"connectingClients"`
 	ConnectedClients  int          `json:"connectedClients"`
 	TotalBytesUp      int64        `json:"totalBytesUp"`
@@ -76,24 +100,91 @@ type StatsJSON struct {
 
 // New creates a new Conduit service
 func New(cfg *config.Config) (*Service, error) {
+	proxyID, err := crypto.KeyPairToCurve25519Base64(cfg.KeyPair)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive proxy id: %w", err)
+	}
+
 	s := &Service{
-		config: cfg,
+		config:  cfg,
+		proxyID: proxyID,
 		stats: &Stats{
 			StartTime: time.Now(),
 		},
+		tracer: (*metrics.OTLP)(nil).Tracer(),
+	}
+
+	// Fail early rather than silently exporting nothing
+	otlpConfigured, err := metrics.OTLPConfigured()
+	if err != nil {
+		return nil, err
 	}
+	s.otlp = otlpConfigured
 
-	if cfg.MetricsAddr != "" {
+	if cfg.MetricsAddr != "" || cfg.MetricsPush.Enabled() || s.otlp {
 		s.metrics = metrics.New(metrics.GaugeFuncs{
 			GetUptimeSeconds: s.getUptimeSeconds,
 			GetIdleSeconds:   s.getIdleSecondsFloat,
 		})
 		s.metrics.SetConfig(cfg.MaxClients, cfg.BandwidthBytesPerSecond)
+		s.metrics.SetStationInfo(proxyID, cfg.StationName)
+		labels := metrics.BuildLabels{Version: cfg.Version}
+		if info, err := cfg.PsiphonConfigInfo(); err == nil {
+			labels.ConfigSource = info.Source
+			labels.ConfigFingerprint = info.Fingerprint
+			labels.PropagationChannelID = info.PropagationChannelId
+			labels.SponsorID = info.SponsorId
+			labels.ConfigVersion = info.Version
+		}
+		s.metrics.SetBuildInfo(labels)
+	}
+
+	var outputs []statsOutput
+	if cfg.StatsFile != "" {
+		outputs = append(outputs, jsonFileOutput{path: cfg.StatsFile})
+	}
+	if h := cfg.StatsHistory; h.File != "" {
+		outputs = append(outputs, historyOutput{path: h.File, format: h.Format, maxBytes: h.MaxBytes, keep: h.Keep})
+	}
+	if cfg.StatsTextfile != "" {
+		outputs = append(outputs, textfileOutput{path: cfg.StatsTextfile})
+	}
+	if len(outputs) > 0 {
+		s.statsWriter = newStatsWriter(outputs, cfg.StatsInterval, func() StatsJSON {
+			s.mu.Lock()
+			defer s.mu.Unlock()
+			return s.statsSnapshot()
+		}, cfg.Verbosity >= 1)
+	}
+
+	if cfg.DashboardAddr != "" {
+		s.dashboard = dashboard.New(func() any { return s.State() })
 	}
 
 	return s, nil
 }
 
+// SetNotifier sets the systemd notifier used to report readiness, status and
+// watchdog pings. Must be called before Run.
+func (s *Service) SetNotifier(n *systemd.Notifier) {
+	s.notifier = n
+}
+
+// SetEvents sets the bus that live events are published to and, if the
+// dashboard is enabled, streamed from. Must be called before Run.
+func (s *Service) SetEvents(bus *events.Bus) {
+	s.events = bus
+	if s.dashboard != nil {
+		s.dashboard.SetEvents(bus)
+	}
+}
+
+// SetNotices sets the buffer that recent psiphon notices are kept in for
+// debug dumps. Must be called before Run.
+func (s *Service) SetNotices(n *debug.Notices) {
+	s.notices = n
+}
+
 // Run starts the Conduit inproxy service and blocks until context is cancelled
 // Returns ErrIdleRestart if the service should be restarted due to idle timeout
 func (s *Service) Run(ctx context.Context) error {
@@ -109,11 +200,15 @@ func (s *Service) Run(ctx context.Context) error {
 	}
 
 	if s.metrics != nil && s.config.MetricsAddr != "" {
-		if err := s.metrics.StartServer(s.config.MetricsAddr); err != nil {
+		if err := s.metrics.StartServer(s.config.MetricsAddr, httpserver.Options(s.config.MetricsServer)); err != nil {
 			return fmt.Errorf("failed to start metrics server: %w", err)
 		}
 
-		fmt.Printf("Prometheus metrics available at http://%s/metrics\n", s.config.MetricsAddr)
+		scheme := "http"
+		if s.config.MetricsServer.TLS() {
+			scheme = "https"
+		}
+		fmt.Printf("Prometheus metrics available at %s://%s/metrics\n", scheme, s.config.MetricsAddr)
 
 		// Ensure metrics server is shut down when we're done
 		defer func() {
@@ -126,6 +221,77 @@ func (s *Service) Run(ctx context.Context) error {
 		}()
 	}
 
+	if s.otlp {
+		otlp, err := s.metrics.StartOTLP(ctx,
+			attribute.String("conduit.proxy_id", s.proxyID),
+			attribute.String("conduit.station.name", s.config.StationName),
+		)
+		if err != nil {
+			return fmt.Errorf("failed to start OTLP export: %w", err)
+		}
+		s.tracer = otlp.Tracer()
+
+		fmt.Println("[INFO] Exporting metrics and traces with OTLP")
+
+		// Flush pending metrics and spans when we're done
+		defer func() {
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+
+			if err := otlp.Shutdown(ctx); err != nil {
+				fmt.Printf("[ERROR] Failed to shutdown OTLP export: %v\n", err)
+			}
+		}()
+	}
+
+	if p := s.config.MetricsPush; p.Enabled() {
+		push, err := s.metrics.StartPush(metrics.PushOptions{
+			Pushgateway: p.Pushgateway,
+			RemoteWrite: p.RemoteWrite,
+			Interval:    p.Interval,
+			Labels:      map[string]string{"station": s.config.StationName, "proxy_id": s.proxyID},
+			Username:    p.Username,
+			Password:    p.Password,
+			BearerToken: p.BearerToken,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to start metrics push: %w", err)
+		}
+
+		fmt.Println("[INFO] Pushing Prometheus metrics")
+
+		// Push the final values when we're done
+		defer func() {
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+
+			if err := push.Shutdown(ctx); err != nil {
+				fmt.Printf("[ERROR] Failed to push final metrics: %v\n", err)
+			}
+		}()
+	}
+
+	if s.dashboard != nil {
+		if err := s.dashboard.StartServer(s.config.DashboardAddr, httpserver.Options(s.config.DashboardServer)); err != nil {
+			return fmt.Errorf("failed to start dashboard server: %w", err)
+		}
+
+		scheme := "http"
+		if s.config.DashboardServer.TLS() {
+			scheme = "https"
+		}
+		fmt.Printf("Dashboard available at %s://%s/\n", scheme, s.config.DashboardAddr)
+
+		defer func() {
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+
+			if err := s.dashboard.Shutdown(ctx); err != nil {
+				fmt.Printf("[ERROR] Failed to shutdown dashboard server: %v\n", err)
+			}
+		}()
+	}
+
 	// Set up notice handling FIRST - before any psiphon calls
 	if err := psiphon.SetNoticeWriter(psiphon.NewNoticeReceiver(
 		func(notice []byte) {
@@ -156,15 +322,53 @@ func (s *Service) Run(ctx context.Context) error {
 	}
 	defer psiphon.CloseDataStore()
 
+	// Trace the controller lifecycle
+	ctx, span := s.tracer.Start(ctx, "conduit.controller", trace.WithAttributes(
+		attribute.Int("conduit.max_clients", s.config.MaxClients),
+		attribute.Int("conduit.bandwidth_bytes_per_second", s.config.BandwidthBytesPerSecond),
+	))
+	s.mu.Lock()
+	s.traceCtx = ctx
+	s.mu.Unlock()
+	defer func() {
+		s.endAnnouncements("stopped")
+		span.End()
+	}()
+
 	// Create and run controller
 	s.controller, err = psiphon.NewController(psiphonConfig)
 	if err != nil {
+		span.SetStatus(codes.Error, err.Error())
 		return fmt.Errorf("failed to create controller: %w", err)
 	}
 
+	// Startup is complete once the controller is created. Connecting to the
+	// broker can take arbitrarily long on a blocked network, and is reported
+	// in the status line instead.
+	s.notifier.Ready()
+	s.notifier.Status("Connecting to Psiphon network")
+
+	// Write the stats file periodically, and a final time once stopped
+	stopStatsWriter := s.statsWriter.start()
+	defer stopStatsWriter()
+
+	// Ping the systemd watchdog while the controller keeps emitting notices
+	stopWatchdog := s.startWatchdog()
+	defer stopWatchdog()
+
+	// The broker connection starts and ends with the controller
+	s.mu.Lock()
+	s.brokerErrors = 0
+	s.mu.Unlock()
+	defer s.setLive(false)
+
 	// If idle restart is enabled, run the controller with idle monitoring
 	if s.config.IdleRestart > 0 {
-		return s.runWithIdleMonitoring(ctx)
+		err := s.runWithIdleMonitoring(ctx)
+		if errors.Is(err, ErrIdleRestart) {
+			span.AddEvent("idle_restart")
+		}
+		return err
 	}
 
 	// Run the controller (blocks until context is cancelled)
@@ -175,56 +379,26 @@ func (s *Service) Run(ctx context.Context) error {
 
 // createPsiphonConfig creates the Psiphon tunnel-core configuration
 func (s *Service) createPsiphonConfig() (*psiphon.Config, error) {
-	configJSON := make(map[string]interface{})
-
 	// Load base config from psiphon config file or embedded data
+	var baseData []byte
 	if len(s.config.PsiphonConfigData) > 0 {
-		// Use embedded config data
-		if err := json.Unmarshal(s.config.PsiphonConfigData, &configJSON); err != nil {
-			return nil, fmt.Errorf("failed to parse embedded psiphon config: %w", err)
-		}
+		baseData = s.config.PsiphonConfigData
 	} else if s.config.PsiphonConfigPath != "" {
-		// Load from file
 		data, err := os.ReadFile(s.config.PsiphonConfigPath)
 		if err != nil {
 			return nil, fmt.Errorf("failed to read psiphon config file: %w", err)
 		}
-		if err := json.Unmarshal(data, &configJSON); err != nil {
-			return nil, fmt.Errorf("failed to parse psiphon config file: %w", err)
-		}
+		baseData = data
 	} else {
 		return nil, fmt.Errorf("no psiphon config available")
 	}
 
-	// Override with our data directory
-	configJSON["DataRootDirectory"] = s.config.DataDir
-
-	// Client version - used by broker for compatibility
-	configJSON["ClientVersion"] = "1"
-
-	// Inproxy mode settings - these override any values in the base config
-	configJSON["InproxyEnableProxy"] = true
-	configJSON["InproxyMaxClients"] = s.config.MaxClients
-	// Only set bandwidth limits if not unlimited (0 means unlimited)
-	if s.config.BandwidthBytesPerSecond > 0 {
-		configJSON["InproxyLimitUpstreamBytesPerSecond"] = s.config.BandwidthBytesPerSecond
-		configJSON["InproxyLimitDownstreamBytesPerSecond"] = s.config.BandwidthBytesPerSecond
+	// Our data directory and inproxy mode settings override any values in the
+	// base config
+	configJSON, err := config.MergePsiphonConfig(baseData, s.config.PsiphonOverrides())
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse psiphon config: %w", err)
 	}
-	configJSON["InproxyProxySessionPrivateKey"] = s.config.PrivateKeyBase64
-
-	// Disable regular tunnel functionality - we're just a proxy
-	configJSON["DisableTunnels"] = true
-
-	// Disable local proxies (not needed for inproxy mode)
-	configJSON["DisableLocalHTTPProxy"] = true
-	configJSON["DisableLocalSocksProxy"] = true
-
-	// Enable activity notices for stats
-	configJSON["EmitInproxyProxyActivity"] = true
-
-	// Keep diagnostic notices enabled (we filter in handleNotice)
-	// This is needed to get the broker connection status
-	configJSON["EmitDiagnosticNotices"] = true
 
 	// Serialize config
 	configData, err := json.Marshal(configJSON)
@@ -243,33 +417,74 @@ func (s *Service) createPsiphonConfig() (*psiphon.Config, error) {
 		return nil, fmt.Errorf("failed to commit config: %w", err)
 	}
 
-	// Set up geo tracking callback if enabled
-	if s.geoCollector != nil {
-		psiphonConfig.OnInproxyConnectionEstablished = func(local, remote inproxy.ConnectionStats) {
-			if remote.IP == "" {
-				return
-			}
+	// Gate announcements, so StopAnnouncing can drain the station
+	psiphonConfig.NetworkConnectivityChecker = announceGate{s}
+
+	// Track connections for geo stats and live events. Events are anonymized:
+	// they carry the country, never the client IP.
+	psiphonConfig.OnInproxyConnectionEstablished = func(local, remote inproxy.ConnectionStats) {
+		s.endAnnouncement("matched", "")
+		s.brokerRoundTrip(true)
+		event := events.Connection{CandidateType: remote.CandidateType}
+		if s.geoCollector != nil && remote.IP != "" {
 			if remote.CandidateType == "relay" {
 				s.geoCollector.ConnectRelay(remote.IP)
 			} else {
-				s.geoCollector.ConnectIP(remote.IP)
+				event.Country = s.geoCollector.ConnectIP(remote.IP)
 			}
 		}
-		psiphonConfig.OnInproxyConnectionClosed = func(remote *inproxy.ConnectionStats, bw *inproxy.BandwidthStats) {
-			if remote == nil || remote.IP == "" || bw == nil {
-				return
-			}
+		s.events.Publish(events.TypeConnectionEstablished, event)
+	}
+	psiphonConfig.OnInproxyConnectionClosed = func(remote *inproxy.ConnectionStats, bw *inproxy.BandwidthStats) {
+		var event events.Connection
+		if remote != nil {
+			event.CandidateType = remote.CandidateType
+		}
+		if bw != nil {
+			event.BytesUp = bw.BytesUp
+			event.BytesDown = bw.BytesDown
+		}
+		if s.geoCollector != nil && remote != nil && remote.IP != "" && bw != nil {
 			if remote.CandidateType == "relay" {
 				s.geoCollector.DisconnectRelay(remote.IP, bw.BytesUp, bw.BytesDown)
 			} else {
-				s.geoCollector.DisconnectIP(remote.IP, bw.BytesUp, bw.BytesDown)
+				event.Country = s.geoCollector.DisconnectIP(remote.IP, bw.BytesUp, bw.BytesDown)
 			}
 		}
+		s.events.Publish(events.TypeConnectionClosed, event)
 	}
 
 	return psiphonConfig, nil
 }
 
+// StopAnnouncing stops announcing to the broker, so the station takes no new
+// clients while connected clients keep being relayed. It can't be undone:
+// the service is meant to be stopped once its clients are gone.
+func (s *Service) StopAnnouncing() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.draining = true
+}
+
+// announceGate holds back the proxy's announcements while draining. The
+// proxy waits for network connectivity before every announcement, and
+// established client connections don't depend on it.
+type announceGate struct {
+	s *Service
+}
+
+func (g announceGate) HasNetworkConnectivity() int {
+	g.s.mu.Lock()
+	defer g.s.mu.Unlock()
+	if !g.s.draining {
+		return 1
+	}
+	// The proxy polling the gate shows the controller isn't wedged, even
+	// though it no longer emits announcement notices
+	g.s.lastNotice = time.Now()
+	return 0
+}
+
 // updateMetrics updates the metrics from the stats
 func (s *Service) updateMetrics() {
 	if s.metrics == nil {
@@ -309,6 +524,12 @@ func (s *Service) calcIdleSeconds() float64 {
 
 // handleNotice processes notices from psiphon-tunnel-core
 func (s *Service) handleNotice(notice []byte) {
+	s.notices.Add(notice)
+
+	s.mu.Lock()
+	s.lastNotice = time.Now()
+	s.mu.Unlock()
+
 	var noticeData struct {
 		NoticeType string                 `json:"noticeType"`
 		Data       map[string]interface{} `json:"data"`
@@ -386,18 +607,11 @@ func (s *Service) handleNotice(notice []byte) {
 	case "Info":
 		// Check for broker connection status
 		if msg, ok := noticeData.Data["message"].(string); ok {
+			if msg == "announcement request" {
+				s.startAnnouncement()
+			}
 			if strings.HasPrefix(msg, "inproxy: selected broker ") {
-				s.mu.Lock()
-				if !s.stats.IsLive {
-					s.stats.IsLive = true
-					if s.metrics != nil {
-						s.metrics.SetIsLive(true)
-					}
-					s.mu.Unlock()
-					fmt.Println("[OK] Connected to Psiphon network")
-				} else {
-					s.mu.Unlock()
-				}
+				s.brokerSelected()
 				if s.config.Verbosity >= 2 {
 					fmt.Printf("[DEBUG] Info: %v\n", noticeData.Data)
 				}
@@ -413,9 +627,19 @@ func (s *Service) handleNotice(notice []byte) {
 		}
 
 	case "InproxyMustUpgrade":
-		fmt.Println("\nWARNING: A newer version of Conduit is required. Please upgrade.")
+		fmt.Println("\nWARNING: A newer version of Conduit is required. Please upgrade with 'conduit update'.")
+		s.events.Publish(events.TypeUpgrade, events.Upgrade{Message: "A newer version of Conduit is required"})
 
 	case "Error":
+		if errMsg, ok := noticeData.Data["error"].(string); ok {
+			if outcome, spanErr, ok := announcementOutcome(errMsg); ok {
+				s.endAnnouncement(outcome, spanErr)
+				s.brokerRoundTrip(outcome != "error")
+			}
+			if !isNoisyError(errMsg) {
+				s.events.Publish(events.TypeError, events.Error{Message: errMsg})
+			}
+		}
 		// Handle errors based on verbosity
 		if s.config.Verbosity >= 1 {
 			if errMsg, ok := noticeData.Data["error"].(string); ok {
@@ -444,6 +668,69 @@ func (s *Service) handleNotice(notice []byte) {
 	}
 }
 
+// brokerFailureThreshold is how many broker round trips in a row must fail
+// before the station counts as disconnected from the broker
+const brokerFailureThreshold = 3
+
+// brokerSelected marks the station live when the controller selects a broker.
+// While round trips are failing, the broker is only reselected, so the
+// station stays down until a round trip succeeds (thread-safe).
+func (s *Service) brokerSelected() {
+	s.mu.RLock()
+	failing := s.brokerErrors > 0
+	s.mu.RUnlock()
+	if !failing && s.setLive(true) {
+		fmt.Println("[OK] Connected to Psiphon network")
+		s.notifier.Status("Connected to Psiphon network, waiting for clients")
+	}
+}
+
+// brokerRoundTrip records the outcome of an announcement round trip with the
+// broker: a success marks the station live, and brokerFailureThreshold
+// failures in a row mark it down (thread-safe)
+func (s *Service) brokerRoundTrip(ok bool) {
+	s.mu.Lock()
+	if ok {
+		s.brokerErrors = 0
+	} else {
+		s.brokerErrors++
+	}
+	failures := s.brokerErrors
+	s.mu.Unlock()
+
+	switch {
+	case ok && s.setLive(true):
+		fmt.Println("[OK] Connected to Psiphon network")
+		s.notifier.Status("Connected to Psiphon network, waiting for clients")
+	case failures >= brokerFailureThreshold && s.setLive(false):
+		fmt.Println("[WARN] Lost connection to the Psiphon broker, reconnecting...")
+		s.notifier.Status("Lost connection to the Psiphon broker, reconnecting")
+	}
+}
+
+// setLive updates the broker connection state, publishing an event on every
+// transition. Returns whether the state changed (thread-safe).
+func (s *Service) setLive(live bool) bool {
+	s.mu.Lock()
+	if s.stats.IsLive == live {
+		s.mu.Unlock()
+		return false
+	}
+	s.stats.IsLive = live
+	if s.metrics != nil {
+		s.metrics.SetIsLive(live)
+	}
+	s.mu.Unlock()
+
+	s.events.Publish(events.TypeBroker, events.Broker{Connected: live})
+	if live {
+		s.addControllerEvent("broker_connected")
+	} else {
+		s.addControllerEvent("broker_disconnected")
+	}
+	return true
+}
+
 // isNoisyError returns true for errors that occur frequently during normal operation
 func isNoisyError(errMsg string) bool {
 	// These errors happen during normal operation and will auto-retry:
@@ -473,40 +760,65 @@ func (s *Service) logStats() {
 		formatBytes(s.stats.TotalBytesDown),
 		formatDuration(uptime),
 	)
+	s.notifier.Status(fmt.Sprintf("Connecting: %d | Connected: %d | Up: %s | Down: %s",
+		s.stats.ConnectingClients,
+		s.stats.ConnectedClients,
+		formatBytes(s.stats.TotalBytesUp),
+		formatBytes(s.stats.TotalBytesDown),
+	))
 
-	// Write stats to file if configured (copy data while locked, write async)
-	if s.config.StatsFile != "" {
-		statsJSON := StatsJSON{
-			ConnectingClients: s.stats.ConnectingClients,
-			ConnectedClients:  s.stats.ConnectedClients,
-			TotalBytesUp:      s.stats.TotalBytesUp,
-			TotalBytesDown:    s.stats.TotalBytesDown,
-			UptimeSeconds:     int64(time.Since(s.stats.StartTime).Seconds()),
-			IdleSeconds:       int64(s.calcIdleSeconds()),
-			IsLive:            s.stats.IsLive,
-			Timestamp:         time.Now().Format(time.RFC3339),
-		}
-		if s.geoCollector != nil {
-			statsJSON.Geo = s.geoCollector.GetResults()
-		}
-		go s.writeStatsToFile(statsJSON)
-	}
+	s.events.Publish(events.TypeStats, s.statsSnapshot())
+	s.statsWriter.Changed()
 }
 
-// writeStatsToFile writes stats to the configured JSON file asynchronously
-func (s *Service) writeStatsToFile(statsJSON StatsJSON) {
-	data, err := json.MarshalIndent(statsJSON, "", "  ")
-	if err != nil {
-		if s.config.Verbosity >= 1 {
-			fmt.Printf("[ERROR] Failed to marshal stats: %v\n", err)
-		}
-		return
+// statsSnapshot returns the current stats. Must be called with lock held.
+func (s *Service) statsSnapshot() StatsJSON {
+	statsJSON := StatsJSON{
+		StationName:       s.config.StationName,
+		ProxyID:           s.proxyID,
+		ConnectingClients: s.stats.ConnectingClients,
+		ConnectedClients:  s.stats.ConnectedClients,
+		TotalBytesUp:      s.stats.TotalBytesUp,
+		TotalBytesDown:    s.stats.TotalBytesDown,
+		UptimeSeconds:     int64(time.Since(s.stats.StartTime).Seconds()),
+		IdleSeconds:       int64(s.calcIdleSeconds()),
+		IsLive:            s.stats.IsLive,
+		Timestamp:         time.Now().Format(time.RFC3339),
 	}
+	if s.geoCollector != nil {
+		statsJSON.Geo = s.geoCollector.GetResults()
+	}
+	return statsJSON
+}
 
-	if err := os.WriteFile(s.config.StatsFile, data, 0644); err != nil {
-		if s.config.Verbosity >= 1 {
-			fmt.Printf("[ERROR] Failed to write stats file: %v\n", err)
-		}
+// DashboardConfig is the configuration shown by the web dashboard
+type DashboardConfig struct {
+	MaxClients              int   `json:"maxClients"`
+	BandwidthBytesPerSecond int   `json:"bandwidthBytesPerSecond"`
+	IdleRestartSeconds      int64 `json:"idleRestartSeconds"`
+	GeoEnabled              bool  `json:"geoEnabled"`
+	MetricsEnabled          bool  `json:"metricsEnabled"`
+}
+
+// DashboardState is the state served to the web dashboard
+type DashboardState struct {
+	Stats  StatsJSON       `json:"stats"`
+	Config DashboardConfig `json:"config"`
+}
+
+// State returns the current stats and configuration (thread-safe)
+func (s *Service) State() DashboardState {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return DashboardState{
+		Stats: s.statsSnapshot(),
+		Config: DashboardConfig{
+			MaxClients:              s.config.MaxClients,
+			BandwidthBytesPerSecond: s.config.BandwidthBytesPerSecond,
+			IdleRestartSeconds:      int64(s.config.IdleRestart.Seconds()),
+			GeoEnabled:              s.config.GeoEnabled,
+			MetricsEnabled:          s.config.MetricsAddr != "",
+		},
 	}
 }
 
@@ -584,8 +896,58 @@ func (s *Service) runWithIdleMonitoring(ctx context.Context) error {
 					formatDuration(time.Duration(idleSeconds)*time.Second))
 				cancelController()
 				<-controllerDone
+				s.events.Publish(events.TypeRestart, events.Restart{Reason: "idle"})
 				return ErrIdleRestart
 			}
 		}
 	}
 }
+
+// watchdogStallTimeout is how long the controller may go without emitting a
+// notice before it is considered wedged. Proxy activity notices are emitted
+// continuously, and announcements keep failing loudly while the broker is
+// unreachable, so a healthy controller is never quiet for this long.
+const watchdogStallTimeout = 5 * time.Minute
+
+// startWatchdog pings the systemd watchdog until the returned stop function is
+// called. Pings are only sent while the controller makes progress: once no
+// notice has been received for watchdogStallTimeout, or the controller exits,
+// the pings stop and systemd restarts the service.
+func (s *Service) startWatchdog() (stop func()) {
+	interval := s.notifier.WatchdogInterval()
+	if interval == 0 {
+		return func() {}
+	}
+
+	s.mu.Lock()
+	s.lastNotice = time.Now()
+	s.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		stalled := false
+		for {
+			s.mu.RLock()
+			quiet := time.Since(s.lastNotice)
+			s.mu.RUnlock()
+			if quiet < watchdogStallTimeout {
+				stalled = false
+				s.notifier.Watchdog()
+			} else if !stalled {
+				stalled = true
+				fmt.Printf("[WARN] No notices from the Psiphon controller for %s, stopping watchdog pings\n",
+					formatDuration(quiet.Truncate(time.Second)))
+			}
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() { once.Do(func() { close(done) }) }
+}
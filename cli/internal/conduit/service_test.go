@@ -0,0 +1,87 @@
+/*
+ * Copyright (c) 2026, Psiphon Inc.
+ * All rights reserved.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package conduit
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/Psiphon-Inc/conduit/cli/internal/config"
+	"github.com/Psiphon-Inc/conduit/cli/internal/events"
+)
+
+func TestBrokerTransitions(t *testing.T) {
+	bus := events.NewBus(0)
+	sub := bus.Subscribe(0)
+	defer sub.Close()
+	s := &Service{config: &config.Config{}, stats: &Stats{}, events: bus}
+
+	info := func(msg string) string { return `{"noticeType":"Info","data":{"message":"` + msg + `"}}` }
+	errorNotice := func(msg string) string { return `{"noticeType":"Error","data":{"error":"` + msg + `"}}` }
+	const failed = "inproxy: announcement failed: status code 503"
+
+	steps := []struct {
+		name     string
+		notices  []string
+		expected string // published broker states
+		live     bool
+	}{
+		{"connected", []string{info("inproxy: selected broker example.com")}, "true", true},
+		{"unmatched announcements", []string{errorNotice("inproxy: announcement returned no match")}, "", true},
+		{"transient failures", []string{errorNotice(failed), errorNotice(failed)}, "", true},
+		{"recovered", []string{errorNotice("inproxy: announcement returned no match")}, "", true},
+		{"broker lost", []string{errorNotice(failed), errorNotice(failed), errorNotice(failed)}, "false", false},
+		{"broker reselected", []string{info("inproxy: selected broker example.com"), errorNotice(failed)}, "", false},
+		{"broker regained", []string{errorNotice("inproxy: announcement limited")}, "true", true},
+		{"lost again", []string{errorNotice(failed), errorNotice(failed), errorNotice(failed)}, "false", false},
+		{"regained again", []string{errorNotice("inproxy: announcement returned no match")}, "true", true},
+	}
+	for _, step := range steps {
+		for _, notice := range step.notices {
+			s.handleNotice([]byte(notice))
+		}
+		var published string
+		for len(sub.Events()) > 0 {
+			event := <-sub.Events()
+			if event.Type != events.TypeBroker {
+				continue
+			}
+			published += fmt.Sprint(event.Data.(events.Broker).Connected)
+		}
+		if published != step.expected || s.stats.IsLive != step.live {
+			t.Fatalf("%s: published %q, live %v, expected %q, live %v", step.name, published, s.stats.IsLive, step.expected, step.live)
+		}
+	}
+}
+
+func TestStopAnnouncing(t *testing.T) {
+	s := &Service{config: &config.Config{}, stats: &Stats{}}
+	gate := announceGate{s}
+	if gate.HasNetworkConnectivity() != 1 {
+		t.Fatalf("gate closed before StopAnnouncing")
+	}
+	s.StopAnnouncing()
+	if gate.HasNetworkConnectivity() != 0 {
+		t.Fatalf("gate open after StopAnnouncing")
+	}
+	if s.lastNotice.IsZero() {
+		t.Fatalf("polling the closed gate didn't count as controller progress")
+	}
+}
@@ -0,0 +1,201 @@
+/*
+ * Copyright (c) 2026, Psiphon Inc.
+ * All rights reserved.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package conduit
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/Psiphon-Inc/conduit/cli/internal/config"
+	"github.com/Psiphon-Inc/conduit/cli/internal/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// History formats
+const (
+	HistoryJSONL = "jsonl"
+	HistoryCSV   = "csv"
+)
+
+// statsOutput is a destination of the stats writer
+type statsOutput interface {
+	writeStats(stats StatsJSON) error
+}
+
+// jsonFileOutput overwrites a JSON file with the latest stats
+type jsonFileOutput struct {
+	path string
+}
+
+func (o jsonFileOutput) writeStats(stats StatsJSON) error {
+	data, err := json.MarshalIndent(stats, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal stats: %w", err)
+	}
+	if err := config.WriteFileAtomic(o.path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write stats file: %w", err)
+	}
+	return nil
+}
+
+// historyOutput appends every snapshot to a JSONL or CSV file. When the file
+// would grow past maxBytes it is rotated to path.1, path.2, ... keeping keep
+// old files.
+type historyOutput struct {
+	path     string
+	format   string
+	maxBytes int64
+	keep     int
+}
+
+// csvHeader lists the CSV history columns. Geo stats are only in JSONL.
+var csvHeader = []string{
+	"timestamp", "stationName", "proxyId", "connectingClients", "connectedClients",
+	"totalBytesUp", "totalBytesDown", "uptimeSeconds", "idleSeconds", "isLive",
+}
+
+func (o historyOutput) writeStats(stats StatsJSON) error {
+	var line []byte
+	if o.format == HistoryCSV {
+		line = csvLine([]string{
+			stats.Timestamp, stats.StationName, stats.ProxyID,
+			strconv.Itoa(stats.ConnectingClients), strconv.Itoa(stats.ConnectedClients),
+			strconv.FormatInt(stats.TotalBytesUp, 10), strconv.FormatInt(stats.TotalBytesDown, 10),
+			strconv.FormatInt(stats.UptimeSeconds, 10), strconv.FormatInt(stats.IdleSeconds, 10),
+			strconv.FormatBool(stats.IsLive),
+		})
+	} else {
+		data, err := json.Marshal(stats)
+		if err != nil {
+			return fmt.Errorf("failed to marshal stats: %w", err)
+		}
+		line = append(data, '\n')
+	}
+
+	if err := o.rotate(int64(len(line))); err != nil {
+		return fmt.Errorf("failed to rotate stats history: %w", err)
+	}
+
+	f, err := os.OpenFile(o.path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open stats history: %w", err)
+	}
+	defer f.Close()
+
+	if o.format == HistoryCSV {
+		if info, err := f.Stat(); err == nil && info.Size() == 0 {
+			line = append(csvLine(csvHeader), line...)
+		}
+	}
+	if _, err := f.Write(line); err != nil {
+		return fmt.Errorf("failed to write stats history: %w", err)
+	}
+	return nil
+}
+
+// rotate moves the history aside if appending n bytes would exceed maxBytes
+func (o historyOutput) rotate(n int64) error {
+	info, err := os.Stat(o.path)
+	if os.IsNotExist(err) || o.maxBytes <= 0 {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if info.Size() == 0 || info.Size()+n <= o.maxBytes {
+		return nil
+	}
+
+	if o.keep < 1 {
+		return os.Remove(o.path)
+	}
+	os.Remove(fmt.Sprintf("%s.%d", o.path, o.keep))
+	for i := o.keep - 1; i >= 1; i-- {
+		if err := os.Rename(fmt.Sprintf("%s.%d", o.path, i), fmt.Sprintf("%s.%d", o.path, i+1)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return os.Rename(o.path, o.path+".1")
+}
+
+func csvLine(fields []string) []byte {
+	var b bytes.Buffer
+	w := csv.NewWriter(&b)
+	w.Write(fields)
+	w.Flush()
+	return b.Bytes()
+}
+
+// textfileOutput writes the stats in the Prometheus text format, for the
+// node_exporter textfile collector. The gauges shared with the metrics
+// endpoint use its names and help text; the per-country gauges are only
+// written here.
+type textfileOutput struct {
+	path string
+}
+
+func (o textfileOutput) writeStats(stats StatsJSON) error {
+	registry := prometheus.NewRegistry()
+	gauge := func(opts prometheus.GaugeOpts, value float64) {
+		g := prometheus.NewGauge(opts)
+		g.Set(value)
+		registry.MustRegister(g)
+	}
+	isLive := 0.0
+	if stats.IsLive {
+		isLive = 1
+	}
+	gauge(metrics.ConnectingClientsOpts, float64(stats.ConnectingClients))
+	gauge(metrics.ConnectedClientsOpts, float64(stats.ConnectedClients))
+	gauge(metrics.IsLiveOpts, isLive)
+	gauge(metrics.BytesUploadedOpts, float64(stats.TotalBytesUp))
+	gauge(metrics.BytesDownloadedOpts, float64(stats.TotalBytesDown))
+	gauge(metrics.UptimeSecondsOpts, float64(stats.UptimeSeconds))
+	gauge(metrics.IdleSecondsOpts, float64(stats.IdleSeconds))
+
+	station := prometheus.NewGaugeVec(metrics.StationInfoOpts, metrics.StationInfoLabels)
+	station.WithLabelValues(stats.ProxyID, stats.StationName).Set(1)
+	registry.MustRegister(station)
+
+	if len(stats.Geo) > 0 {
+		geoVec := func(name, help string) *prometheus.GaugeVec {
+			v := prometheus.NewGaugeVec(prometheus.GaugeOpts{Namespace: "conduit", Name: name, Help: help}, []string{"country"})
+			registry.MustRegister(v)
+			return v
+		}
+		connected := geoVec("geo_connected_clients", "Number of clients currently connected, by country")
+		up := geoVec("geo_bytes_uploaded", "Total number of bytes uploaded, by country")
+		down := geoVec("geo_bytes_downloaded", "Total number of bytes downloaded, by country")
+		for _, g := range stats.Geo {
+			connected.WithLabelValues(g.Code).Set(float64(g.Count))
+			up.WithLabelValues(g.Code).Set(float64(g.BytesUp))
+			down.WithLabelValues(g.Code).Set(float64(g.BytesDown))
+		}
+	}
+
+	if err := prometheus.WriteToTextfile(o.path, registry); err != nil {
+		return fmt.Errorf("failed to write metrics textfile: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,105 @@
+/*
+ * Copyright (c) 2026, Psiphon Inc.
+ * All rights reserved.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package conduit
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Psiphon-Inc/conduit/cli/internal/geo"
+)
+
+func TestHistoryOutput(t *testing.T) {
+	dir := t.TempDir()
+	stats := StatsJSON{ConnectedClients: 3, TotalBytesUp: 100, IsLive: true, Timestamp: "2026-01-25T15:44:00Z"}
+
+	// JSONL rotates once the file would exceed maxBytes, keeping 2 old files
+	path := filepath.Join(dir, "history.jsonl")
+	line, _ := json.Marshal(stats)
+	out := historyOutput{path: path, format: HistoryJSONL, maxBytes: int64(2*len(line) + 2), keep: 2}
+	for i := 0; i < 7; i++ {
+		if err := out.writeStats(stats); err != nil {
+			t.Fatalf("writeStats: %v", err)
+		}
+	}
+	for _, tt := range []struct {
+		name  string
+		lines int
+	}{{"history.jsonl", 1}, {"history.jsonl.1", 2}, {"history.jsonl.2", 2}, {"history.jsonl.3", 0}} {
+		data, err := os.ReadFile(filepath.Join(dir, tt.name))
+		if tt.lines == 0 {
+			if err == nil {
+				t.Fatalf("%s exists, expected only 2 rotated files", tt.name)
+			}
+			continue
+		}
+		if err != nil || strings.Count(string(data), "\n") != tt.lines {
+			t.Fatalf("%s has %q, expected %d lines", tt.name, data, tt.lines)
+		}
+		var decoded StatsJSON
+		if err := json.Unmarshal([]byte(strings.SplitN(string(data), "\n", 2)[0]), &decoded); err != nil || decoded.ConnectedClients != 3 {
+			t.Fatalf("%s: invalid JSONL: %v", tt.name, err)
+		}
+	}
+
+	// CSV starts every file with a header
+	path = filepath.Join(dir, "history.csv")
+	out = historyOutput{path: path, format: HistoryCSV}
+	stats.StationName = "berlin, 1"
+	out.writeStats(stats)
+	out.writeStats(stats)
+	data, _ := os.ReadFile(path)
+	expected := strings.Join(csvHeader, ",") + "\n" +
+		strings.Repeat(`2026-01-25T15:44:00Z,"berlin, 1",,0,3,100,0,0,0,true`+"\n", 2)
+	if string(data) != expected {
+		t.Fatalf("CSV history =\n%s\nexpected\n%s", data, expected)
+	}
+}
+
+func TestTextfileOutput(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "conduit.prom")
+	stats := StatsJSON{
+		StationName:      "berlin-1",
+		ProxyID:          "abc",
+		ConnectedClients: 4,
+		TotalBytesDown:   2048,
+		IsLive:           true,
+		Geo:              []geo.Result{{Code: "IR", Count: 2, BytesUp: 10}},
+	}
+	if err := (textfileOutput{path}).writeStats(stats); err != nil {
+		t.Fatalf("writeStats: %v", err)
+	}
+	data, _ := os.ReadFile(path)
+	for _, line := range []string{
+		"# TYPE conduit_connected_clients gauge\nconduit_connected_clients 4\n",
+		"conduit_bytes_downloaded 2048\n",
+		"conduit_is_live 1\n",
+		`conduit_station_info{proxy_id="abc",station_name="berlin-1"} 1` + "\n",
+		`conduit_geo_connected_clients{country="IR"} 2` + "\n",
+		`conduit_geo_bytes_uploaded{country="IR"} 10` + "\n",
+	} {
+		if !strings.Contains(string(data), line) {
+			t.Fatalf("textfile is missing %q:\n%s", line, data)
+		}
+	}
+}
@@ -0,0 +1,101 @@
+/*
+ * Copyright (c) 2026, Psiphon Inc.
+ * All rights reserved.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package conduit
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// statsWriter is the only writer of the stats outputs. It writes them every
+// interval, and as soon as possible after client counts change; changes made
+// while a write is pending are coalesced into that write.
+type statsWriter struct {
+	outputs  []statsOutput
+	interval time.Duration
+	snapshot func() StatsJSON
+	verbose  bool
+	changed  chan struct{}
+}
+
+func newStatsWriter(outputs []statsOutput, interval time.Duration, snapshot func() StatsJSON, verbose bool) *statsWriter {
+	return &statsWriter{
+		outputs:  outputs,
+		interval: interval,
+		snapshot: snapshot,
+		verbose:  verbose,
+		changed:  make(chan struct{}, 1),
+	}
+}
+
+// Changed requests a write of the latest stats without blocking. A nil
+// writer ignores the request.
+func (w *statsWriter) Changed() {
+	if w == nil {
+		return
+	}
+	select {
+	case w.changed <- struct{}{}:
+	default:
+	}
+}
+
+// start runs the writer until the returned stop function is called, which
+// makes a final write and waits for it
+func (w *statsWriter) start() (stop func()) {
+	if w == nil {
+		return func() {}
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		w.run(ctx)
+		close(done)
+	}()
+	return func() {
+		cancel()
+		<-done
+	}
+}
+
+func (w *statsWriter) run(ctx context.Context) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			w.write()
+			return
+		case <-w.changed:
+		case <-ticker.C:
+		}
+		w.write()
+	}
+}
+
+func (w *statsWriter) write() {
+	stats := w.snapshot()
+	for _, output := range w.outputs {
+		if err := output.writeStats(stats); err != nil && w.verbose {
+			fmt.Printf("[ERROR] %v\n", err)
+		}
+	}
+}
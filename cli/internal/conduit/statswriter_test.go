@@ -0,0 +1,84 @@
+/*
+ * Copyright (c) 2026, Psiphon Inc.
+ * All rights reserved.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package conduit
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestStatsWriter(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "stats.json")
+
+	var snapshots atomic.Int32
+	block := make(chan struct{})
+	w := newStatsWriter([]statsOutput{jsonFileOutput{path}}, time.Hour, func() StatsJSON {
+		n := snapshots.Add(1)
+		if n == 1 {
+			<-block
+		}
+		return StatsJSON{ConnectedClients: int(n)}
+	}, true)
+	stop := w.start()
+
+	// Changes while a write is in progress are coalesced into one more write
+	w.Changed()
+	for snapshots.Load() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	for i := 0; i < 10; i++ {
+		w.Changed()
+	}
+	close(block)
+	for snapshots.Load() < 2 {
+		time.Sleep(time.Millisecond)
+	}
+	time.Sleep(20 * time.Millisecond)
+	if n := snapshots.Load(); n != 2 {
+		t.Fatalf("%d writes for 11 changes during a write, expected 2", n)
+	}
+
+	// Stopping makes a final write
+	stop()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	var stats StatsJSON
+	if err := json.Unmarshal(data, &stats); err != nil || stats.ConnectedClients != 3 {
+		t.Fatalf("stats file after stop = %s, %v", data, err)
+	}
+
+	// Only the stats file is left behind, with the usual permissions
+	entries, _ := os.ReadDir(dir)
+	info, _ := os.Stat(path)
+	if len(entries) != 1 || info.Mode().Perm() != 0644 {
+		t.Fatalf("unexpected files after writes: %v, mode %v", entries, info.Mode())
+	}
+
+	var nilWriter *statsWriter
+	nilWriter.Changed()
+	nilWriter.start()()
+}
@@ -0,0 +1,109 @@
+/*
+ * Copyright (c) 2026, Psiphon Inc.
+ * All rights reserved.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package conduit
+
+import (
+	"context"
+	"strings"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// maxAnnouncementSpans bounds the announcements waiting for an outcome
+const maxAnnouncementSpans = 64
+
+// Broker announcement spans. The tunnel core doesn't correlate announcement
+// requests with their outcomes, and several announcements run concurrently,
+// so outcomes (a match, or an announcement error) end the oldest open span.
+
+// startAnnouncement starts a span for an announcement request (thread-safe)
+func (s *Service) startAnnouncement() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	ctx := s.traceCtx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if len(s.announcements) == maxAnnouncementSpans {
+		s.endAnnouncementLocked("unknown", "")
+	}
+	_, span := s.tracer.Start(ctx, "inproxy.announcement")
+	s.announcements = append(s.announcements, span)
+}
+
+// endAnnouncement ends the oldest announcement span with an outcome (thread-safe)
+func (s *Service) endAnnouncement(outcome, errMsg string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.endAnnouncementLocked(outcome, errMsg)
+}
+
+// endAnnouncementLocked ends the oldest announcement span. Must be called with lock held.
+func (s *Service) endAnnouncementLocked(outcome, errMsg string) {
+	if len(s.announcements) == 0 {
+		return
+	}
+	span := s.announcements[0]
+	s.announcements = s.announcements[1:]
+	span.SetAttributes(attribute.String("conduit.announcement.outcome", outcome))
+	if errMsg != "" {
+		span.SetStatus(codes.Error, errMsg)
+	}
+	span.End()
+}
+
+// endAnnouncements ends all open announcement spans (thread-safe)
+func (s *Service) endAnnouncements(outcome string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for len(s.announcements) > 0 {
+		s.endAnnouncementLocked(outcome, "")
+	}
+}
+
+// announcementOutcome classifies an error notice as an announcement outcome.
+// Unmatched and rate limited announcements are normal; other announcement
+// errors are returned as span errors.
+func announcementOutcome(errMsg string) (outcome, spanErr string, ok bool) {
+	if !strings.HasPrefix(errMsg, "inproxy") {
+		return "", "", false
+	}
+	switch {
+	case strings.Contains(errMsg, "no match"):
+		return "no_match", "", true
+	case strings.Contains(errMsg, "limited"):
+		return "limited", "", true
+	case strings.Contains(errMsg, "announcement"):
+		return "error", errMsg, true
+	}
+	return "", "", false
+}
+
+// addControllerEvent records an event on the controller span (thread-safe)
+func (s *Service) addControllerEvent(name string) {
+	s.mu.RLock()
+	ctx := s.traceCtx
+	s.mu.RUnlock()
+	if ctx != nil {
+		trace.SpanFromContext(ctx).AddEvent(name)
+	}
+}
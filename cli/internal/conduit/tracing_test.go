@@ -0,0 +1,70 @@
+/*
+ * Copyright (c) 2026, Psiphon Inc.
+ * All rights reserved.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package conduit
+
+import (
+	"testing"
+
+	"github.com/Psiphon-Inc/conduit/cli/internal/config"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestAnnouncementSpans(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	s := &Service{config: &config.Config{}, stats: &Stats{}, tracer: provider.Tracer("test")}
+
+	notices := []struct {
+		noticeType, key, value string
+	}{
+		{"Info", "message", "announcement request"},
+		{"Info", "message", "announcement request"},
+		{"Info", "message", "announcement request"},
+		{"Error", "error", "inproxy: announcement returned no match"},
+		{"Error", "error", "inproxy: broker rejected announcement: status code 400"},
+		{"Error", "error", "dial failed"},
+	}
+	for _, n := range notices {
+		s.handleNotice([]byte(`{"noticeType":"` + n.noticeType + `","data":{"` + n.key + `":"` + n.value + `"}}`))
+	}
+	s.endAnnouncements("stopped")
+
+	spans := exporter.GetSpans()
+	expected := []struct {
+		outcome string
+		status  codes.Code
+	}{{"no_match", codes.Unset}, {"error", codes.Error}, {"stopped", codes.Unset}}
+	if len(spans) != len(expected) {
+		t.Fatalf("got %d spans, expected %d", len(spans), len(expected))
+	}
+	for i, span := range spans {
+		var outcome string
+		for _, attr := range span.Attributes {
+			if attr.Key == "conduit.announcement.outcome" {
+				outcome = attr.Value.AsString()
+			}
+		}
+		if span.Name != "inproxy.announcement" || outcome != expected[i].outcome || span.Status.Code != expected[i].status {
+			t.Fatalf("span %d = %s %q %v, expected outcome %q", i, span.Name, outcome, span.Status.Code, expected[i].outcome)
+		}
+	}
+}
@@ -23,7 +23,9 @@ package config
 import (
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"net/netip"
 	"os"
 	"path/filepath"
 	"time"
@@ -37,11 +39,54 @@ const (
 	DefaultBandwidthMbps = 40.0
 	MaxClientsLimit      = 1000
 	UnlimitedBandwidth   = -1.0 // Special value for no bandwidth limit
+	DefaultStatsInterval = 10 * time.Second
 
 	// File names for persisted data
 	keyFileName = "conduit_key.json"
 )
 
+// StatsHistory configures the append-only log of stats snapshots
+type StatsHistory struct {
+	File     string
+	Format   string // "jsonl" or "csv"
+	MaxBytes int64  // rotate when the file would grow past this (0 = never)
+	Keep     int    // number of rotated files to keep
+}
+
+// MetricsPush configures pushing the Prometheus metrics, for stations that
+// can't be scraped
+type MetricsPush struct {
+	Pushgateway string // Pushgateway URL (empty = disabled)
+	RemoteWrite string // Prometheus remote-write URL (empty = disabled)
+	Interval    time.Duration
+	Username    string // Basic auth (default: from the URL)
+	Password    string
+	BearerToken string
+}
+
+// Enabled reports whether any push target is set
+func (p MetricsPush) Enabled() bool {
+	return p.Pushgateway != "" || p.RemoteWrite != ""
+}
+
+// ServerOptions secures an HTTP endpoint with TLS, authentication and a
+// source allowlist. The zero value serves plain HTTP to anyone.
+type ServerOptions struct {
+	TLSCert       string // Serve HTTPS with this certificate and key
+	TLSKey        string
+	TLSSelfSigned bool   // Generate a self-signed TLS certificate and key at TLSCert and TLSKey
+	ClientCA      string // Require client certificates signed by this CA (mTLS)
+	BearerToken   string
+	Username      string // Basic auth
+	Password      string
+	Allow         []netip.Prefix // Source addresses allowed to connect (empty = all)
+}
+
+// TLS reports whether the endpoint is served over HTTPS
+func (o ServerOptions) TLS() bool {
+	return o.TLSCert != ""
+}
+
 // Options represents CLI options passed to LoadOrCreate
 type Options struct {
 	DataDir           string
@@ -50,11 +95,23 @@ type Options struct {
 	MaxClients        int
 	BandwidthMbps     float64
 	BandwidthSet      bool
-	Verbosity         int    // 0=normal, 1=verbose, 2+=debug
-	StatsFile         string // Path to write stats JSON file (empty = disabled)
-	GeoEnabled        bool   // Enable geo tracking via tcpdump
-	MetricsAddr       string // Address for Prometheus metrics endpoint (empty = disabled)
+	Verbosity         int           // 0=normal, 1=verbose, 2+=debug
+	StatsFile         string        // Path to write stats JSON file (empty = disabled)
+	StatsInterval     time.Duration // How often the stats file is rewritten (0 = default)
+	StatsHistory      StatsHistory  // Append-only stats log (empty file = disabled)
+	StatsTextfile     string        // Path to write node_exporter textfile metrics (empty = disabled)
+	GeoEnabled        bool          // Enable geo tracking via tcpdump
+	MetricsAddr       string        // Address for Prometheus metrics endpoint (empty = disabled)
+	MetricsServer     ServerOptions // TLS, auth and allowlist of the metrics endpoint
+	MetricsPush       MetricsPush   // Push metrics to a Pushgateway or remote-write endpoint
+	DashboardAddr     string        // Address for the web dashboard (empty = disabled)
+	DashboardServer   ServerOptions // TLS, auth and allowlist of the web dashboard
 	IdleRestart       time.Duration
+	KeyFile           string // Load the key from this file instead of the data dir (never created)
+	Key               KeyOptions
+	RestoreMnemonic   string // Restore the key from this mnemonic instead of generating one
+	DerivationPath    string // Derivation path used with RestoreMnemonic
+	StationName       string // Persist this station name (empty = keep the saved name)
 }
 
 // Config represents the validated configuration for the Conduit service
@@ -65,18 +122,31 @@ type Config struct {
 	BandwidthBytesPerSecond int
 	DataDir                 string
 	PsiphonConfigPath       string
-	PsiphonConfigData       []byte // Embedded config data (if used)
-	Verbosity               int    // 0=normal, 1=verbose, 2+=debug
-	StatsFile               string // Path to write stats JSON file (empty = disabled)
-	GeoEnabled              bool   // Enable geo tracking via tcpdump
-	MetricsAddr             string // Address for Prometheus metrics endpoint (empty = disabled)
+	PsiphonConfigData       []byte        // Embedded or remote config data (if used)
+	PsiphonConfigURL        string        // Where PsiphonConfigData was fetched from (empty = not remote)
+	Verbosity               int           // 0=normal, 1=verbose, 2+=debug
+	StatsFile               string        // Path to write stats JSON file (empty = disabled)
+	StatsInterval           time.Duration // How often the stats file is rewritten
+	StatsHistory            StatsHistory  // Append-only stats log (empty file = disabled)
+	StatsTextfile           string        // Path to write node_exporter textfile metrics (empty = disabled)
+	GeoEnabled              bool          // Enable geo tracking via tcpdump
+	MetricsAddr             string        // Address for Prometheus metrics endpoint (empty = disabled)
+	MetricsServer           ServerOptions // TLS, auth and allowlist of the metrics endpoint
+	MetricsPush             MetricsPush   // Push metrics to a Pushgateway or remote-write endpoint
+	DashboardAddr           string        // Address for the web dashboard (empty = disabled)
+	DashboardServer         ServerOptions // TLS, auth and allowlist of the web dashboard
 	IdleRestart             time.Duration
+	StationName             string // Cosmetic name shared with Ryve, stats and metrics (may be empty)
+	Version                 string // CLI version, for build info
 }
 
-// persistedKey represents the key data saved to disk
+// persistedKey represents the key data saved to disk. When the key is
+// encrypted, Encrypted holds the sealed JSON of the plaintext fields.
 type persistedKey struct {
-	Mnemonic         string `json:"mnemonic"`
-	PrivateKeyBase64 string `json:"privateKeyBase64"`
+	Mnemonic         string            `json:"mnemonic,omitempty"`
+	DerivationPath   string            `json:"derivationPath,omitempty"`
+	PrivateKeyBase64 string            `json:"privateKeyBase64,omitempty"`
+	Encrypted        *crypto.SealedBox `json:"encrypted,omitempty"`
 }
 
 // LoadOrCreate loads existing configuration or creates a new one with generated keys.
@@ -90,9 +160,43 @@ func LoadOrCreate(opts Options) (*Config, error) {
 	}
 
 	// Try to load existing key, or generate new one
-	keyPair, privateKeyBase64, err := loadOrCreateKey(opts.DataDir, opts.Verbosity > 0)
+	var keyPair *crypto.KeyPair
+	var privateKeyBase64 string
+	var err error
+	if opts.KeyFile != "" {
+		if opts.RestoreMnemonic != "" {
+			return nil, fmt.Errorf("cannot restore a mnemonic when the key is provided as a file")
+		}
+		keyPair, privateKeyBase64, err = LoadKeyFile(opts.KeyFile, opts.Key)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load key file: %w", err)
+		}
+	} else if opts.RestoreMnemonic != "" {
+		// Restoring is idempotent: the same mnemonic can be passed on every start,
+		// but it never replaces a different existing key
+		info, _, err := ImportMnemonic(opts.DataDir, opts.RestoreMnemonic, opts.DerivationPath, false, opts.Key)
+		if errors.Is(err, ErrKeyExists) {
+			return nil, fmt.Errorf("refusing to restore mnemonic: %s holds a different key (move it away or use 'conduit key import --force')", KeyFilePath(opts.DataDir))
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to restore key from mnemonic: %w", err)
+		}
+		keyPair, privateKeyBase64 = info.KeyPair, info.PrivateKeyBase64
+	} else {
+		keyPair, privateKeyBase64, err = loadOrCreateKey(opts.DataDir, opts.Key, opts.Verbosity > 0)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load or create key: %w", err)
+		}
+	}
+
+	if opts.StationName != "" {
+		if err := SaveStationName(opts.DataDir, opts.StationName); err != nil {
+			return nil, err
+		}
+	}
+	stationName, err := LoadStationName(opts.DataDir)
 	if err != nil {
-		return nil, fmt.Errorf("failed to load or create key: %w", err)
+		return nil, err
 	}
 
 	// Handle psiphon config source
@@ -109,20 +213,58 @@ func LoadOrCreate(opts Options) (*Config, error) {
 		psiphonConfigFileData = data
 	}
 
+	maxClients, bandwidthBytesPerSecond, err := ResolveLimits(psiphonConfigFileData, opts.MaxClients, opts.BandwidthMbps, opts.BandwidthSet)
+	if err != nil {
+		return nil, err
+	}
+
+	statsInterval := opts.StatsInterval
+	if statsInterval == 0 {
+		statsInterval = DefaultStatsInterval
+	}
+
+	return &Config{
+		KeyPair:                 keyPair,
+		PrivateKeyBase64:        privateKeyBase64,
+		MaxClients:              maxClients,
+		BandwidthBytesPerSecond: bandwidthBytesPerSecond,
+		DataDir:                 opts.DataDir,
+		PsiphonConfigPath:       opts.PsiphonConfigPath,
+		PsiphonConfigData:       psiphonConfigData,
+		Verbosity:               opts.Verbosity,
+		StatsFile:               opts.StatsFile,
+		StatsInterval:           statsInterval,
+		StatsHistory:            opts.StatsHistory,
+		StatsTextfile:           opts.StatsTextfile,
+		GeoEnabled:              opts.GeoEnabled,
+		MetricsAddr:             opts.MetricsAddr,
+		MetricsServer:           opts.MetricsServer,
+		MetricsPush:             opts.MetricsPush,
+		DashboardAddr:           opts.DashboardAddr,
+		DashboardServer:         opts.DashboardServer,
+		IdleRestart:             opts.IdleRestart,
+		StationName:             stationName,
+	}, nil
+}
+
+// ResolveLimits resolves max clients and bandwidth in bytes per second (0 =
+// unlimited): flag > Psiphon config > default. A zero maxClientsFlag and an
+// unset bandwidth mean the flags were not given.
+func ResolveLimits(psiphonConfigData []byte, maxClientsFlag int, bandwidthMbps float64, bandwidthSet bool) (maxClients, bandwidthBytesPerSecond int, err error) {
 	// Parse inproxy settings from config if available
 	var inproxyConfig struct {
 		InproxyMaxClients                    *int `json:"InproxyMaxClients"`
 		InproxyLimitUpstreamBytesPerSecond   *int `json:"InproxyLimitUpstreamBytesPerSecond"`
 		InproxyLimitDownstreamBytesPerSecond *int `json:"InproxyLimitDownstreamBytesPerSecond"`
 	}
-	if len(psiphonConfigFileData) > 0 {
-		if err := json.Unmarshal(psiphonConfigFileData, &inproxyConfig); err != nil {
-			return nil, fmt.Errorf("failed to parse psiphon config file: %w", err)
+	if len(psiphonConfigData) > 0 {
+		if err := json.Unmarshal(psiphonConfigData, &inproxyConfig); err != nil {
+			return 0, 0, fmt.Errorf("failed to parse psiphon config file: %w", err)
 		}
 	}
 
 	// Resolve max clients: flag > config > default
-	maxClients := opts.MaxClients
+	maxClients = maxClientsFlag
 	if maxClients == 0 && inproxyConfig.InproxyMaxClients != nil {
 		maxClients = *inproxyConfig.InproxyMaxClients
 	}
@@ -130,15 +272,13 @@ func LoadOrCreate(opts Options) (*Config, error) {
 		maxClients = DefaultMaxClients
 	}
 	if maxClients < 1 || maxClients > MaxClientsLimit {
-		return nil, fmt.Errorf("max-clients must be between 1 and %d", MaxClientsLimit)
+		return 0, 0, fmt.Errorf("max-clients must be between 1 and %d", MaxClientsLimit)
 	}
 
 	// Resolve bandwidth: flag > config > default
-	var bandwidthBytesPerSecond int
-	if opts.BandwidthSet {
-		bandwidthMbps := opts.BandwidthMbps
+	if bandwidthSet {
 		if bandwidthMbps != UnlimitedBandwidth && bandwidthMbps < 1 {
-			return nil, fmt.Errorf("bandwidth must be at least 1 Mbps (or -1 for unlimited)")
+			return 0, 0, fmt.Errorf("bandwidth must be at least 1 Mbps (or -1 for unlimited)")
 		}
 		if bandwidthMbps == UnlimitedBandwidth {
 			bandwidthBytesPerSecond = 0
@@ -149,10 +289,10 @@ func LoadOrCreate(opts Options) (*Config, error) {
 		hasUpstream := inproxyConfig.InproxyLimitUpstreamBytesPerSecond != nil
 		hasDownstream := inproxyConfig.InproxyLimitDownstreamBytesPerSecond != nil
 		if hasUpstream && *inproxyConfig.InproxyLimitUpstreamBytesPerSecond < 0 {
-			return nil, fmt.Errorf("bandwidth must be at least 1 Mbps (or -1 for unlimited)")
+			return 0, 0, fmt.Errorf("bandwidth must be at least 1 Mbps (or -1 for unlimited)")
 		}
 		if hasDownstream && *inproxyConfig.InproxyLimitDownstreamBytesPerSecond < 0 {
-			return nil, fmt.Errorf("bandwidth must be at least 1 Mbps (or -1 for unlimited)")
+			return 0, 0, fmt.Errorf("bandwidth must be at least 1 Mbps (or -1 for unlimited)")
 		}
 		minPositive := 0
 		if hasUpstream && *inproxyConfig.InproxyLimitUpstreamBytesPerSecond > 0 {
@@ -171,46 +311,24 @@ func LoadOrCreate(opts Options) (*Config, error) {
 			bandwidthBytesPerSecond = int(DefaultBandwidthMbps * 1000 * 1000 / 8)
 		}
 	}
-
-	return &Config{
-		KeyPair:                 keyPair,
-		PrivateKeyBase64:        privateKeyBase64,
-		MaxClients:              maxClients,
-		BandwidthBytesPerSecond: bandwidthBytesPerSecond,
-		DataDir:                 opts.DataDir,
-		PsiphonConfigPath:       opts.PsiphonConfigPath,
-		PsiphonConfigData:       psiphonConfigData,
-		Verbosity:               opts.Verbosity,
-		StatsFile:               opts.StatsFile,
-		GeoEnabled:              opts.GeoEnabled,
-		MetricsAddr:             opts.MetricsAddr,
-		IdleRestart:             opts.IdleRestart,
-	}, nil
+	return maxClients, bandwidthBytesPerSecond, nil
 }
 
 // loadOrCreateKey loads an existing key from disk or generates a new one
-func loadOrCreateKey(dataDir string, verbose bool) (*crypto.KeyPair, string, error) {
+func loadOrCreateKey(dataDir string, keyOpts KeyOptions, verbose bool) (*crypto.KeyPair, string, error) {
 	keyPath := filepath.Join(dataDir, keyFileName)
 
-	// Try to load existing key
-	if data, err := os.ReadFile(keyPath); err == nil {
-		var pk persistedKey
-		if err := json.Unmarshal(data, &pk); err == nil && pk.PrivateKeyBase64 != "" {
-			// Parse the stored key
-			privateKeyBytes, err := base64.RawStdEncoding.DecodeString(pk.PrivateKeyBase64)
-			if err != nil {
-				privateKeyBytes, err = base64.StdEncoding.DecodeString(pk.PrivateKeyBase64)
-			}
-			if err == nil {
-				keyPair, err := crypto.ParsePrivateKey(privateKeyBytes)
-				if err == nil {
-					if verbose {
-						fmt.Println("Loaded existing key from", keyPath)
-					}
-					return keyPair, pk.PrivateKeyBase64, nil
-				}
-			}
+	// Try to load existing key. An existing but unreadable key (e.g. encrypted with
+	// a passphrase we don't have) is an error: never replace it with a new key.
+	info, err := readKeyFile(keyPath, keyOpts)
+	if err == nil {
+		if verbose {
+			fmt.Println("Loaded existing key from", keyPath)
 		}
+		return info.KeyPair, info.PrivateKeyBase64, nil
+	}
+	if !errors.Is(err, os.ErrNotExist) {
+		return nil, "", err
 	}
 
 	// Generate new key
@@ -229,18 +347,20 @@ func loadOrCreateKey(dataDir string, verbose bool) (*crypto.KeyPair, string, err
 
 	privateKeyBase64 := base64.RawStdEncoding.EncodeToString(keyPair.PrivateKey)
 
+	// Encrypt new keys when a passphrase has been configured
+	var passphrase []byte
+	if keyOpts.EncryptNew {
+		if passphrase, err = keyOpts.passphrase(); err != nil {
+			return nil, "", err
+		}
+	}
+
 	// Save to disk
-	pk := persistedKey{
+	if err := writeKeyFile(keyPath, persistedKey{
 		Mnemonic:         mnemonic,
 		PrivateKeyBase64: privateKeyBase64,
-	}
-	data, err := json.MarshalIndent(pk, "", "  ")
-	if err != nil {
-		return nil, "", fmt.Errorf("failed to marshal key: %w", err)
-	}
-
-	if err := os.WriteFile(keyPath, data, 0600); err != nil {
-		return nil, "", fmt.Errorf("failed to save key: %w", err)
+	}, passphrase); err != nil {
+		return nil, "", err
 	}
 
 	if verbose {
@@ -251,31 +371,15 @@ func loadOrCreateKey(dataDir string, verbose bool) (*crypto.KeyPair, string, err
 }
 
 // LoadKey loads an existing key from disk (for claim command)
-func LoadKey(dataDir string) (*crypto.KeyPair, string, error) {
-	keyPath := filepath.Join(dataDir, keyFileName)
-
-	// Try to load existing key
-	data, err := os.ReadFile(keyPath)
-	if err != nil {
-		return nil, "", fmt.Errorf("failed to load key: %w", err)
-	}
-
-	var pk persistedKey
-	if err := json.Unmarshal(data, &pk); err != nil || pk.PrivateKeyBase64 == "" {
-		return nil, "", fmt.Errorf("failed to parse key: %w", err)
-	}
-
-	// Parse the stored key
-	privateKeyBytes, err := base64.RawStdEncoding.DecodeString(pk.PrivateKeyBase64)
-	if err != nil {
-		privateKeyBytes, err = base64.StdEncoding.DecodeString(pk.PrivateKeyBase64)
-	}
+func LoadKey(dataDir string, keyOpts KeyOptions) (*crypto.KeyPair, string, error) {
+	return LoadKeyFile(filepath.Join(dataDir, keyFileName), keyOpts)
+}
 
+// LoadKeyFile loads an existing key from the given conduit_key.json file
+func LoadKeyFile(keyPath string, keyOpts KeyOptions) (*crypto.KeyPair, string, error) {
+	info, err := readKeyFile(keyPath, keyOpts)
 	if err != nil {
-		return nil, pk.PrivateKeyBase64, fmt.Errorf("failed to parse key: %w", err)
+		return nil, "", err
 	}
-
-	keyPair, err := crypto.ParsePrivateKey(privateKeyBytes)
-	return keyPair, pk.PrivateKeyBase64, err
-
+	return info.KeyPair, info.PrivateKeyBase64, nil
 }
@@ -0,0 +1,115 @@
+/*
+ * Copyright (c) 2026, Psiphon Inc.
+ * All rights reserved.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package config
+
+import (
+	"net/url"
+
+	"github.com/Psiphon-Inc/conduit/cli/internal/crypto"
+)
+
+// Effective is the configuration in effect, without secrets, for
+// diagnostics and bug reports
+type Effective struct {
+	ProxyID                 string       `json:"proxyId"`
+	StationName             string       `json:"stationName,omitempty"`
+	DataDir                 string       `json:"dataDir"`
+	PsiphonConfig           string       `json:"psiphonConfig"` // path, URL, or "embedded"
+	MaxClients              int          `json:"maxClients"`
+	BandwidthBytesPerSecond int          `json:"bandwidthBytesPerSecond"` // 0 = unlimited
+	Verbosity               int          `json:"verbosity"`
+	StatsFile               string       `json:"statsFile,omitempty"`
+	StatsInterval           string       `json:"statsInterval"`
+	StatsHistory            StatsHistory `json:"statsHistory"`
+	StatsTextfile           string       `json:"statsTextfile,omitempty"`
+	GeoEnabled              bool         `json:"geoEnabled"`
+	MetricsAddr             string       `json:"metricsAddr,omitempty"`
+	MetricsTLS              bool         `json:"metricsTls"`
+	MetricsAuth             []string     `json:"metricsAuth,omitempty"` // "bearer", "basic", "mtls"
+	MetricsAllow            []string     `json:"metricsAllow,omitempty"`
+	MetricsPushgateway      string       `json:"metricsPushgateway,omitempty"`
+	MetricsRemoteWrite      string       `json:"metricsRemoteWrite,omitempty"`
+	MetricsPushInterval     string       `json:"metricsPushInterval,omitempty"`
+	DashboardAddr           string       `json:"dashboardAddr,omitempty"`
+	IdleRestart             string       `json:"idleRestart,omitempty"`
+}
+
+// Effective returns the configuration without keys, tokens or passwords
+func (c *Config) Effective() Effective {
+	e := Effective{
+		StationName:             c.StationName,
+		DataDir:                 c.DataDir,
+		PsiphonConfig:           c.PsiphonConfigPath,
+		MaxClients:              c.MaxClients,
+		BandwidthBytesPerSecond: c.BandwidthBytesPerSecond,
+		Verbosity:               c.Verbosity,
+		StatsFile:               c.StatsFile,
+		StatsInterval:           c.StatsInterval.String(),
+		StatsHistory:            c.StatsHistory,
+		StatsTextfile:           c.StatsTextfile,
+		GeoEnabled:              c.GeoEnabled,
+		MetricsAddr:             c.MetricsAddr,
+		MetricsTLS:              c.MetricsServer.TLS(),
+		MetricsPushgateway:      RedactURL(c.MetricsPush.Pushgateway),
+		MetricsRemoteWrite:      RedactURL(c.MetricsPush.RemoteWrite),
+		DashboardAddr:           c.DashboardAddr,
+	}
+	if c.KeyPair != nil {
+		e.ProxyID, _ = crypto.KeyPairToCurve25519Base64(c.KeyPair)
+	}
+	if len(c.PsiphonConfigData) > 0 && c.PsiphonConfigPath == "" {
+		e.PsiphonConfig = "embedded"
+	}
+	if c.PsiphonConfigURL != "" {
+		e.PsiphonConfig = RedactURL(c.PsiphonConfigURL)
+	}
+	if c.MetricsServer.BearerToken != "" {
+		e.MetricsAuth = append(e.MetricsAuth, "bearer")
+	}
+	if c.MetricsServer.Username != "" {
+		e.MetricsAuth = append(e.MetricsAuth, "basic")
+	}
+	if c.MetricsServer.ClientCA != "" {
+		e.MetricsAuth = append(e.MetricsAuth, "mtls")
+	}
+	for _, prefix := range c.MetricsServer.Allow {
+		e.MetricsAllow = append(e.MetricsAllow, prefix.String())
+	}
+	if c.MetricsPush.Enabled() {
+		e.MetricsPushInterval = c.MetricsPush.Interval.String()
+	}
+	if c.IdleRestart > 0 {
+		e.IdleRestart = c.IdleRestart.String()
+	}
+	return e
+}
+
+// RedactURL removes credentials from a URL for logs and diagnostics: the
+// userinfo, and the query and fragment, which often hold tokens
+func RedactURL(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return "REDACTED"
+	}
+	u.User = nil
+	u.RawQuery, u.ForceQuery = "", false
+	u.Fragment, u.RawFragment = "", ""
+	return u.String()
+}
@@ -0,0 +1,157 @@
+/*
+ * Copyright (c) 2026, Psiphon Inc.
+ * All rights reserved.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/Psiphon-Inc/conduit/cli/internal/crypto"
+)
+
+const manifestVersion = 1
+
+// Manifest lists the stations derived from a fleet mnemonic. It holds no secrets:
+// with the mnemonic, every station key can be re-derived from its index.
+type Manifest struct {
+	Version  int             `json:"version"`
+	Updated  string          `json:"updated"`
+	Stations []ManifestEntry `json:"stations"`
+}
+
+// ManifestEntry describes one station derived from a fleet mnemonic
+type ManifestEntry struct {
+	Index          uint32 `json:"index"`
+	DerivationPath string `json:"derivationPath"`
+	Name           string `json:"name,omitempty"`
+	ProxyID        string `json:"proxyId"`
+	PublicKey      string `json:"publicKey"`
+}
+
+// DeriveStation derives the key of the station at index from a fleet mnemonic
+func DeriveStation(mnemonic string, index uint32, name string) (*crypto.KeyPair, ManifestEntry, error) {
+	path := crypto.StationDerivationPath(index)
+	keyPair, err := crypto.DeriveKeyPairFromMnemonic(normalizeMnemonic(mnemonic), path)
+	if err != nil {
+		return nil, ManifestEntry{}, err
+	}
+	proxyID, err := crypto.KeyPairToCurve25519Base64(keyPair)
+	if err != nil {
+		return nil, ManifestEntry{}, fmt.Errorf("failed to derive proxy id: %w", err)
+	}
+	return keyPair, ManifestEntry{
+		Index:          index,
+		DerivationPath: path,
+		Name:           name,
+		ProxyID:        proxyID,
+		PublicKey:      crypto.PublicKeyBase64(keyPair),
+	}, nil
+}
+
+// WriteStationKey derives the key of the station at index and saves it to dataDir.
+// The fleet mnemonic is deliberately not stored with the key: a compromised
+// station must not reveal the keys of the rest of the fleet.
+// Existing keys are handled as in ImportMnemonic. A non-empty name is also
+// saved as the station name.
+func WriteStationKey(dataDir, mnemonic string, index uint32, name string, force bool, keyOpts KeyOptions) (ManifestEntry, string, error) {
+	if err := ValidateStationName(name); err != nil {
+		return ManifestEntry{}, "", err
+	}
+	_, entry, err := DeriveStation(mnemonic, index, name)
+	if err != nil {
+		return ManifestEntry{}, "", err
+	}
+	_, backupPath, err := ImportMnemonic(dataDir, mnemonic, entry.DerivationPath, force, keyOpts)
+	if err != nil {
+		return ManifestEntry{}, backupPath, err
+	}
+	if name != "" {
+		if err := SaveStationName(dataDir, name); err != nil {
+			return ManifestEntry{}, backupPath, err
+		}
+	}
+	return entry, backupPath, nil
+}
+
+// LoadManifest reads a manifest file, returning an empty manifest if it doesn't exist
+func LoadManifest(path string) (*Manifest, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return &Manifest{Version: manifestVersion}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read manifest: %w", err)
+	}
+
+	var m Manifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("failed to parse manifest: %w", err)
+	}
+	if m.Version != manifestVersion {
+		return nil, fmt.Errorf("unsupported manifest version %d", m.Version)
+	}
+	return &m, nil
+}
+
+// Upsert adds or replaces the entry with the same index. A manifest must only
+// ever describe one fleet mnemonic, so an index whose proxy ID changed is an error.
+func (m *Manifest) Upsert(entry ManifestEntry) error {
+	for i, existing := range m.Stations {
+		if existing.Index != entry.Index {
+			continue
+		}
+		if existing.ProxyID != entry.ProxyID {
+			return fmt.Errorf("station %d in manifest has proxy ID %s, but derived %s: wrong mnemonic?", entry.Index, existing.ProxyID, entry.ProxyID)
+		}
+		if entry.Name == "" {
+			entry.Name = existing.Name
+		}
+		m.Stations[i] = entry
+		return nil
+	}
+	m.Stations = append(m.Stations, entry)
+	sort.Slice(m.Stations, func(i, j int) bool { return m.Stations[i].Index < m.Stations[j].Index })
+	return nil
+}
+
+// Save writes the manifest to path
+func (m *Manifest) Save(path string) error {
+	m.Version = manifestVersion
+	m.Updated = time.Now().UTC().Format(time.RFC3339)
+
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal manifest: %w", err)
+	}
+	if dir := filepath.Dir(path); dir != "" {
+		if err := os.MkdirAll(dir, 0700); err != nil {
+			return fmt.Errorf("failed to create manifest directory: %w", err)
+		}
+	}
+	if err := WriteFileAtomic(path, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write manifest: %w", err)
+	}
+	return nil
+}
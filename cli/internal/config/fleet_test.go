@@ -0,0 +1,148 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Psiphon-Inc/conduit/cli/internal/crypto"
+)
+
+func TestDeriveStationDeterministic(t *testing.T) {
+	mnemonic, err := crypto.GenerateMnemonic()
+	if err != nil {
+		t.Fatalf("GenerateMnemonic: %v", err)
+	}
+
+	seen := make(map[string]uint32)
+	for index := uint32(0); index < 5; index++ {
+		_, first, err := DeriveStation(mnemonic, index, "")
+		if err != nil {
+			t.Fatalf("DeriveStation(%d): %v", index, err)
+		}
+		_, second, err := DeriveStation(mnemonic, index, "")
+		if err != nil {
+			t.Fatalf("DeriveStation(%d): %v", index, err)
+		}
+		if first.ProxyID != second.ProxyID {
+			t.Fatalf("station %d derived differently twice", index)
+		}
+		if other, ok := seen[first.ProxyID]; ok {
+			t.Fatalf("stations %d and %d share a proxy ID", other, index)
+		}
+		seen[first.ProxyID] = index
+	}
+
+	// Station keys are independent of the default (path "") key
+	defaultKey, _ := crypto.DeriveKeyPairFromMnemonic(mnemonic, "")
+	defaultProxyID, _ := crypto.KeyPairToCurve25519Base64(defaultKey)
+	if _, ok := seen[defaultProxyID]; ok {
+		t.Fatalf("a station key equals the default key")
+	}
+}
+
+func TestWriteStationKey(t *testing.T) {
+	mnemonic, _ := crypto.GenerateMnemonic()
+	dataDir := t.TempDir()
+
+	entry, _, err := WriteStationKey(dataDir, mnemonic, 3, "berlin-1", false, KeyOptions{})
+	if err != nil {
+		t.Fatalf("WriteStationKey: %v", err)
+	}
+
+	info, err := VerifyKey(dataDir, KeyOptions{})
+	if err != nil {
+		t.Fatalf("VerifyKey: %v", err)
+	}
+	proxyID, _ := crypto.KeyPairToCurve25519Base64(info.KeyPair)
+	if proxyID != entry.ProxyID || info.DerivationPath != "m/3" {
+		t.Fatalf("written key does not match manifest entry: %s %s", proxyID, info.DerivationPath)
+	}
+
+	// The fleet mnemonic must not be stored on the station
+	data, err := os.ReadFile(info.Path)
+	if err != nil {
+		t.Fatalf("read key: %v", err)
+	}
+	if info.Mnemonic != "" || strings.Contains(string(data), strings.Fields(mnemonic)[1]) {
+		t.Fatalf("station key file contains the fleet mnemonic")
+	}
+
+	// Restoring a station from the fleet mnemonic gives the same key
+	restored, _, err := ImportMnemonic(t.TempDir(), mnemonic, entry.DerivationPath, false, KeyOptions{})
+	if err != nil {
+		t.Fatalf("ImportMnemonic: %v", err)
+	}
+	if string(restored.KeyPair.PrivateKey) != string(info.KeyPair.PrivateKey) {
+		t.Fatalf("ImportMnemonic with derivation path gave a different key")
+	}
+}
+
+func TestManifest(t *testing.T) {
+	mnemonic, _ := crypto.GenerateMnemonic()
+	path := filepath.Join(t.TempDir(), "fleet", "manifest.json")
+
+	m, err := LoadManifest(path)
+	if err != nil {
+		t.Fatalf("LoadManifest (missing): %v", err)
+	}
+	for _, index := range []uint32{2, 0, 1} {
+		_, entry, _ := DeriveStation(mnemonic, index, "")
+		if err := m.Upsert(entry); err != nil {
+			t.Fatalf("Upsert(%d): %v", index, err)
+		}
+	}
+	if err := m.Save(path); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	loaded, err := LoadManifest(path)
+	if err != nil {
+		t.Fatalf("LoadManifest: %v", err)
+	}
+	if len(loaded.Stations) != 3 || loaded.Stations[0].Index != 0 || loaded.Stations[2].Index != 2 {
+		t.Fatalf("unexpected stations: %+v", loaded.Stations)
+	}
+
+	// Naming an existing station keeps its identity
+	_, entry, _ := DeriveStation(mnemonic, 1, "tokyo")
+	if err := loaded.Upsert(entry); err != nil {
+		t.Fatalf("Upsert rename: %v", err)
+	}
+	if len(loaded.Stations) != 3 || loaded.Stations[1].Name != "tokyo" {
+		t.Fatalf("rename failed: %+v", loaded.Stations)
+	}
+
+	// Mixing fleets in one manifest is refused
+	other, _ := crypto.GenerateMnemonic()
+	_, entry, _ = DeriveStation(other, 1, "")
+	if err := loaded.Upsert(entry); err == nil {
+		t.Fatalf("Upsert accepted a station from another mnemonic")
+	}
+}
+
+func TestStationName(t *testing.T) {
+	dataDir := t.TempDir()
+
+	name, err := LoadStationName(dataDir)
+	if err != nil || name != "" {
+		t.Fatalf("LoadStationName on empty dir = %q, %v", name, err)
+	}
+
+	if err := SaveStationName(dataDir, "  berlin-1 "); err != nil {
+		t.Fatalf("SaveStationName: %v", err)
+	}
+	if name, _ := LoadStationName(dataDir); name != "berlin-1" {
+		t.Fatalf("LoadStationName = %q, expected berlin-1", name)
+	}
+
+	for _, invalid := range []string{"a name that is far too long for the app", "tab\there"} {
+		if err := SaveStationName(dataDir, invalid); err == nil {
+			t.Fatalf("SaveStationName(%q) should fail", invalid)
+		}
+	}
+	if name, _ := LoadStationName(dataDir); name != "berlin-1" {
+		t.Fatalf("invalid name replaced the saved one: %q", name)
+	}
+}
@@ -0,0 +1,377 @@
+/*
+ * Copyright (c) 2026, Psiphon Inc.
+ * All rights reserved.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package config
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/Psiphon-Inc/conduit/cli/internal/crypto"
+)
+
+// ErrKeyExists is returned when an operation would replace a different existing key
+var ErrKeyExists = errors.New("a different key already exists")
+
+// ErrKeyEncrypted is returned when an encrypted key is read without a passphrase
+var ErrKeyEncrypted = errors.New("key is encrypted and no passphrase was provided")
+
+// PassphraseFunc returns the passphrase for an encrypted key. It is only
+// called when a passphrase is actually needed.
+type PassphraseFunc func() ([]byte, error)
+
+// KeyOptions controls how key files are read and written
+type KeyOptions struct {
+	Passphrase PassphraseFunc // Unlocks encrypted keys (nil = encrypted keys can't be read)
+	EncryptNew bool           // Encrypt newly created key files with Passphrase
+}
+
+// passphrase calls the configured PassphraseFunc
+func (o KeyOptions) passphrase() ([]byte, error) {
+	if o.Passphrase == nil {
+		return nil, ErrKeyEncrypted
+	}
+	passphrase, err := o.Passphrase()
+	if err != nil {
+		return nil, err
+	}
+	if len(passphrase) == 0 {
+		return nil, ErrKeyEncrypted
+	}
+	return passphrase, nil
+}
+
+// KeyInfo describes a station key loaded from disk
+type KeyInfo struct {
+	Path             string
+	KeyPair          *crypto.KeyPair
+	PrivateKeyBase64 string
+	Mnemonic         string // Empty if the key was imported without a mnemonic
+	DerivationPath   string // HKDF path used to derive the key from Mnemonic
+	Encrypted        bool   // Whether the key file is encrypted at rest
+
+	passphrase []byte // Passphrase that unlocked the key, reused when replacing it
+}
+
+// KeyFilePath returns the path of the key file in the data directory
+func KeyFilePath(dataDir string) string {
+	return filepath.Join(dataDir, keyFileName)
+}
+
+// ReadKey loads the key file from the data directory, including its mnemonic
+func ReadKey(dataDir string, keyOpts KeyOptions) (*KeyInfo, error) {
+	return readKeyFile(KeyFilePath(dataDir), keyOpts)
+}
+
+// IsKeyEncrypted reports whether the key file in the data directory is encrypted
+func IsKeyEncrypted(dataDir string) (bool, error) {
+	data, err := os.ReadFile(KeyFilePath(dataDir))
+	if err != nil {
+		return false, fmt.Errorf("failed to load key: %w", err)
+	}
+	var pk persistedKey
+	if err := json.Unmarshal(data, &pk); err != nil {
+		return false, fmt.Errorf("failed to parse key: %w", err)
+	}
+	return pk.Encrypted != nil, nil
+}
+
+// readKeyFile loads and, if needed, decrypts a key file
+func readKeyFile(keyPath string, keyOpts KeyOptions) (*KeyInfo, error) {
+	data, err := os.ReadFile(keyPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load key: %w", err)
+	}
+
+	var pk persistedKey
+	if err := json.Unmarshal(data, &pk); err != nil {
+		return nil, fmt.Errorf("failed to parse key: %w", err)
+	}
+
+	info := &KeyInfo{Path: keyPath}
+
+	if pk.Encrypted != nil {
+		passphrase, err := keyOpts.passphrase()
+		if err != nil {
+			return nil, fmt.Errorf("failed to unlock key %s: %w", keyPath, err)
+		}
+		plaintext, err := crypto.Open(pk.Encrypted, passphrase)
+		if err != nil {
+			return nil, fmt.Errorf("failed to unlock key %s: %w", keyPath, err)
+		}
+		var inner persistedKey
+		if err := json.Unmarshal(plaintext, &inner); err != nil {
+			return nil, fmt.Errorf("failed to parse decrypted key: %w", err)
+		}
+		pk = inner
+		info.Encrypted = true
+		info.passphrase = passphrase
+	}
+
+	keyPair, err := parsePrivateKeyBase64(pk.PrivateKeyBase64)
+	if err != nil {
+		return nil, err
+	}
+
+	info.KeyPair = keyPair
+	info.PrivateKeyBase64 = pk.PrivateKeyBase64
+	info.Mnemonic = pk.Mnemonic
+	info.DerivationPath = pk.DerivationPath
+	return info, nil
+}
+
+// VerifyKey checks the integrity of the key file in the data directory:
+// the private key must parse, its embedded public key must match its seed,
+// and the mnemonic (if present) must derive the same key
+func VerifyKey(dataDir string, keyOpts KeyOptions) (*KeyInfo, error) {
+	info, err := ReadKey(dataDir, keyOpts)
+	if err != nil {
+		return nil, err
+	}
+
+	seed := ed25519.PrivateKey(info.KeyPair.PrivateKey).Seed()
+	expected := ed25519.NewKeyFromSeed(seed)
+	if !ed25519.PrivateKey(info.KeyPair.PrivateKey).Equal(expected) {
+		return info, fmt.Errorf("private key is corrupt: public key does not match seed")
+	}
+
+	if info.Mnemonic != "" {
+		derived, err := crypto.DeriveKeyPairFromMnemonic(info.Mnemonic, info.DerivationPath)
+		if err != nil {
+			return info, fmt.Errorf("stored mnemonic is invalid: %w", err)
+		}
+		if !ed25519.PrivateKey(derived.PrivateKey).Equal(expected) {
+			return info, fmt.Errorf("stored mnemonic does not match private key")
+		}
+	}
+
+	if fi, err := os.Stat(info.Path); err == nil && fi.Mode().Perm()&0077 != 0 {
+		return info, fmt.Errorf("key file %s is accessible by other users (mode %04o), expected 0600", info.Path, fi.Mode().Perm())
+	}
+
+	return info, nil
+}
+
+// ImportMnemonic derives a key from a BIP-39 mnemonic and derivation path (usually
+// empty) and saves it to the data directory. If a different key already exists,
+// ErrKeyExists is returned unless force is set, in which case the old key is
+// backed up first.
+//
+// A non-empty path means the mnemonic is a fleet seed shared by many stations
+// (see WriteStationKey), so it is not stored with the key.
+func ImportMnemonic(dataDir, mnemonic, path string, force bool, keyOpts KeyOptions) (*KeyInfo, string, error) {
+	mnemonic = normalizeMnemonic(mnemonic)
+	keyPair, err := crypto.DeriveKeyPairFromMnemonic(mnemonic, path)
+	if err != nil {
+		return nil, "", err
+	}
+	backup := persistedKey{DerivationPath: path}
+	if path == "" {
+		backup.Mnemonic = mnemonic
+	}
+	return importKey(dataDir, keyPair, backup, force, keyOpts)
+}
+
+// ImportPrivateKey saves a base64-encoded 64-byte private key to the data directory.
+// See ImportMnemonic for how existing keys are handled.
+func ImportPrivateKey(dataDir, privateKeyBase64 string, force bool, keyOpts KeyOptions) (*KeyInfo, string, error) {
+	keyPair, err := parsePrivateKeyBase64(strings.TrimSpace(privateKeyBase64))
+	if err != nil {
+		return nil, "", err
+	}
+	return importKey(dataDir, keyPair, persistedKey{}, force, keyOpts)
+}
+
+// RotateKey backs up the current key and replaces it with a newly generated one
+func RotateKey(dataDir string, keyOpts KeyOptions) (*KeyInfo, string, error) {
+	mnemonic, err := crypto.GenerateMnemonic()
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to generate mnemonic: %w", err)
+	}
+	keyPair, err := crypto.DeriveKeyPairFromMnemonic(mnemonic, "")
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to derive key: %w", err)
+	}
+	return importKey(dataDir, keyPair, persistedKey{Mnemonic: mnemonic}, true, keyOpts)
+}
+
+// EncryptKey encrypts the key file in the data directory with newPassphrase.
+// An already encrypted key is re-encrypted, which changes its passphrase.
+func EncryptKey(dataDir string, keyOpts KeyOptions, newPassphrase []byte) (*KeyInfo, error) {
+	if len(newPassphrase) == 0 {
+		return nil, fmt.Errorf("passphrase is empty")
+	}
+	return rewriteKey(dataDir, keyOpts, newPassphrase)
+}
+
+// DecryptKey stores the key file in the data directory as plaintext
+func DecryptKey(dataDir string, keyOpts KeyOptions) (*KeyInfo, error) {
+	return rewriteKey(dataDir, keyOpts, nil)
+}
+
+// rewriteKey re-saves the existing key, encrypted with passphrase or in plaintext if nil
+func rewriteKey(dataDir string, keyOpts KeyOptions, passphrase []byte) (*KeyInfo, error) {
+	info, err := ReadKey(dataDir, keyOpts)
+	if err != nil {
+		return nil, err
+	}
+	if err := writeKeyFile(info.Path, persistedKey{
+		Mnemonic:         info.Mnemonic,
+		DerivationPath:   info.DerivationPath,
+		PrivateKeyBase64: info.PrivateKeyBase64,
+	}, passphrase); err != nil {
+		return nil, err
+	}
+	info.Encrypted = passphrase != nil
+	info.passphrase = passphrase
+	return info, nil
+}
+
+// importKey writes a key to the data directory, backing up any different existing key.
+// backup holds the mnemonic and derivation path the key came from, if any.
+// Replacements of an encrypted key are encrypted with the same passphrase.
+// Returns the path of the backup, if one was made.
+func importKey(dataDir string, keyPair *crypto.KeyPair, backup persistedKey, force bool, keyOpts KeyOptions) (*KeyInfo, string, error) {
+	if err := os.MkdirAll(dataDir, 0700); err != nil {
+		return nil, "", fmt.Errorf("failed to create data directory: %w", err)
+	}
+
+	keyPath := KeyFilePath(dataDir)
+	backupPath := ""
+	var passphrase []byte
+
+	if existing, err := ReadKey(dataDir, keyOpts); err == nil {
+		passphrase = existing.passphrase
+		if ed25519.PrivateKey(existing.KeyPair.PrivateKey).Equal(ed25519.PrivateKey(keyPair.PrivateKey)) {
+			// Same key: nothing to do, unless we can add the missing mnemonic backup
+			if existing.Mnemonic != "" || backup.Mnemonic == "" {
+				return existing, "", nil
+			}
+		} else {
+			if !force {
+				return nil, "", ErrKeyExists
+			}
+			backupPath, err = backupKeyFile(keyPath)
+			if err != nil {
+				return nil, "", err
+			}
+		}
+	} else if _, statErr := os.Stat(keyPath); statErr == nil {
+		// Unreadable key file: keep a copy rather than silently replacing it
+		if !force {
+			return nil, "", fmt.Errorf("existing key file is unreadable (%v), use force to replace it", err)
+		}
+		backupPath, err = backupKeyFile(keyPath)
+		if err != nil {
+			return nil, "", err
+		}
+	}
+
+	if passphrase == nil && keyOpts.EncryptNew {
+		var err error
+		if passphrase, err = keyOpts.passphrase(); err != nil {
+			return nil, backupPath, err
+		}
+	}
+
+	privateKeyBase64 := base64.RawStdEncoding.EncodeToString(keyPair.PrivateKey)
+	if err := writeKeyFile(keyPath, persistedKey{
+		Mnemonic:         backup.Mnemonic,
+		DerivationPath:   backup.DerivationPath,
+		PrivateKeyBase64: privateKeyBase64,
+	}, passphrase); err != nil {
+		return nil, backupPath, err
+	}
+
+	return &KeyInfo{
+		Path:             keyPath,
+		KeyPair:          keyPair,
+		PrivateKeyBase64: privateKeyBase64,
+		Mnemonic:         backup.Mnemonic,
+		DerivationPath:   backup.DerivationPath,
+		Encrypted:        passphrase != nil,
+		passphrase:       passphrase,
+	}, backupPath, nil
+}
+
+// backupKeyFile copies the key file to a timestamped backup next to it
+func backupKeyFile(keyPath string) (string, error) {
+	data, err := os.ReadFile(keyPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to read key for backup: %w", err)
+	}
+	backupPath := fmt.Sprintf("%s.%s.bak", keyPath, time.Now().UTC().Format("20060102T150405Z"))
+	if err := os.WriteFile(backupPath, data, 0600); err != nil {
+		return "", fmt.Errorf("failed to back up key: %w", err)
+	}
+	return backupPath, nil
+}
+
+// writeKeyFile atomically writes a key file with owner-only permissions,
+// encrypting it if passphrase is non-nil
+func writeKeyFile(keyPath string, pk persistedKey, passphrase []byte) error {
+	if passphrase != nil {
+		plaintext, err := json.Marshal(pk)
+		if err != nil {
+			return fmt.Errorf("failed to marshal key: %w", err)
+		}
+		box, err := crypto.Seal(plaintext, passphrase)
+		if err != nil {
+			return fmt.Errorf("failed to encrypt key: %w", err)
+		}
+		pk = persistedKey{Encrypted: box}
+	}
+
+	data, err := json.MarshalIndent(pk, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal key: %w", err)
+	}
+
+	if err := WriteFileAtomic(keyPath, data, 0600); err != nil {
+		return fmt.Errorf("failed to save key: %w", err)
+	}
+	return nil
+}
+
+// parsePrivateKeyBase64 decodes a private key in raw or padded base64
+func parsePrivateKeyBase64(privateKeyBase64 string) (*crypto.KeyPair, error) {
+	if privateKeyBase64 == "" {
+		return nil, fmt.Errorf("failed to parse key: missing private key")
+	}
+	privateKeyBytes, err := base64.RawStdEncoding.DecodeString(privateKeyBase64)
+	if err != nil {
+		privateKeyBytes, err = base64.StdEncoding.DecodeString(privateKeyBase64)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse key: %w", err)
+	}
+	return crypto.ParsePrivateKey(privateKeyBytes)
+}
+
+// normalizeMnemonic collapses whitespace and case so pasted phrases validate
+func normalizeMnemonic(mnemonic string) string {
+	return strings.Join(strings.Fields(strings.ToLower(mnemonic)), " ")
+}
@@ -0,0 +1,354 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Psiphon-Inc/conduit/cli/internal/crypto"
+)
+
+func TestKeyImportRotateVerify(t *testing.T) {
+	dataDir := t.TempDir()
+
+	mnemonic, err := crypto.GenerateMnemonic()
+	if err != nil {
+		t.Fatalf("GenerateMnemonic: %v", err)
+	}
+
+	// Extra whitespace and capitals, as pasted by a user
+	info, backup, err := ImportMnemonic(dataDir, "  "+strings.ToUpper(mnemonic)+"\n", "", false, KeyOptions{})
+	if err != nil {
+		t.Fatalf("ImportMnemonic: %v", err)
+	}
+	if backup != "" {
+		t.Fatalf("unexpected backup for fresh import: %s", backup)
+	}
+	expected, _ := crypto.DeriveKeyPairFromMnemonic(mnemonic, "")
+	if string(info.KeyPair.PrivateKey) != string(expected.PrivateKey) {
+		t.Fatalf("imported key does not match mnemonic derivation")
+	}
+
+	if _, err := VerifyKey(dataDir, KeyOptions{}); err != nil {
+		t.Fatalf("VerifyKey after import: %v", err)
+	}
+
+	// Loading for start must use the imported key, not generate a new one
+	kp, _, err := loadOrCreateKey(dataDir, KeyOptions{}, false)
+	if err != nil {
+		t.Fatalf("loadOrCreateKey: %v", err)
+	}
+	if string(kp.PrivateKey) != string(expected.PrivateKey) {
+		t.Fatalf("loadOrCreateKey returned a different key")
+	}
+
+	// Re-importing the same mnemonic is a no-op
+	if _, backup, err := ImportMnemonic(dataDir, mnemonic, "", false, KeyOptions{}); err != nil || backup != "" {
+		t.Fatalf("re-import: backup=%q err=%v", backup, err)
+	}
+
+	// A different key must not silently replace the existing one
+	other, _ := crypto.GenerateMnemonic()
+	if _, _, err := ImportMnemonic(dataDir, other, "", false, KeyOptions{}); !errors.Is(err, ErrKeyExists) {
+		t.Fatalf("expected ErrKeyExists, got %v", err)
+	}
+
+	rotated, backup, err := RotateKey(dataDir, KeyOptions{})
+	if err != nil {
+		t.Fatalf("RotateKey: %v", err)
+	}
+	if backup == "" {
+		t.Fatalf("RotateKey did not back up the old key")
+	}
+	if string(rotated.KeyPair.PrivateKey) == string(expected.PrivateKey) {
+		t.Fatalf("RotateKey did not change the key")
+	}
+	if rotated.Mnemonic == "" {
+		t.Fatalf("rotated key has no mnemonic backup")
+	}
+
+	// The backup holds the old key
+	data, err := os.ReadFile(backup)
+	if err != nil {
+		t.Fatalf("read backup: %v", err)
+	}
+	if !strings.Contains(string(data), info.PrivateKeyBase64) {
+		t.Fatalf("backup does not contain the previous key")
+	}
+	if fi, err := os.Stat(backup); err != nil || fi.Mode().Perm() != 0600 {
+		t.Fatalf("backup permissions: %v %v", fi.Mode().Perm(), err)
+	}
+}
+
+func TestImportPrivateKey(t *testing.T) {
+	dataDir := t.TempDir()
+
+	kp, err := crypto.GenerateKeyPair()
+	if err != nil {
+		t.Fatalf("GenerateKeyPair: %v", err)
+	}
+	encoded, _ := crypto.KeyPairToBase64NoPad(kp)
+
+	info, _, err := ImportPrivateKey(dataDir, encoded, false, KeyOptions{})
+	if err != nil {
+		t.Fatalf("ImportPrivateKey: %v", err)
+	}
+	if info.Mnemonic != "" {
+		t.Fatalf("imported private key should have no mnemonic")
+	}
+	if _, err := VerifyKey(dataDir, KeyOptions{}); err != nil {
+		t.Fatalf("VerifyKey: %v", err)
+	}
+
+	if _, _, err := ImportPrivateKey(t.TempDir(), "not-base64!", false, KeyOptions{}); err == nil {
+		t.Fatalf("expected error for invalid private key")
+	}
+}
+
+func TestVerifyKeyDetectsCorruption(t *testing.T) {
+	tests := []struct {
+		name   string
+		mutate func(t *testing.T, keyPath string, info *KeyInfo)
+	}{
+		{
+			name: "mnemonic_mismatch",
+			mutate: func(t *testing.T, keyPath string, info *KeyInfo) {
+				other, _ := crypto.GenerateMnemonic()
+				if err := writeKeyFile(keyPath, persistedKey{Mnemonic: other, PrivateKeyBase64: info.PrivateKeyBase64}, nil); err != nil {
+					t.Fatalf("writeKeyFile: %v", err)
+				}
+			},
+		},
+		{
+			name: "public_half_mismatch",
+			mutate: func(t *testing.T, keyPath string, info *KeyInfo) {
+				other, _ := crypto.GenerateKeyPair()
+				corrupt := append(append([]byte{}, info.KeyPair.PrivateKey[:32]...), other.PublicKey...)
+				kp, _ := crypto.ParsePrivateKey(corrupt)
+				encoded, _ := crypto.KeyPairToBase64NoPad(kp)
+				if err := writeKeyFile(keyPath, persistedKey{PrivateKeyBase64: encoded}, nil); err != nil {
+					t.Fatalf("writeKeyFile: %v", err)
+				}
+			},
+		},
+		{
+			name: "world_readable",
+			mutate: func(t *testing.T, keyPath string, info *KeyInfo) {
+				if err := os.Chmod(keyPath, 0644); err != nil {
+					t.Fatalf("chmod: %v", err)
+				}
+			},
+		},
+		{
+			name: "truncated",
+			mutate: func(t *testing.T, keyPath string, info *KeyInfo) {
+				if err := os.WriteFile(keyPath, []byte(`{"mnemonic": "`), 0600); err != nil {
+					t.Fatalf("write: %v", err)
+				}
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			dataDir := t.TempDir()
+			info, _, err := RotateKey(dataDir, KeyOptions{})
+			if err != nil {
+				t.Fatalf("RotateKey: %v", err)
+			}
+			test.mutate(t, info.Path, info)
+			if _, err := VerifyKey(dataDir, KeyOptions{}); err == nil {
+				t.Fatalf("VerifyKey did not detect %s", test.name)
+			}
+		})
+	}
+}
+
+func staticPassphrase(passphrase string) KeyOptions {
+	return KeyOptions{Passphrase: func() ([]byte, error) { return []byte(passphrase), nil }}
+}
+
+func TestEncryptedKey(t *testing.T) {
+	dataDir := t.TempDir()
+
+	plain, _, err := RotateKey(dataDir, KeyOptions{})
+	if err != nil {
+		t.Fatalf("RotateKey: %v", err)
+	}
+
+	if _, err := EncryptKey(dataDir, KeyOptions{}, []byte("hunter2")); err != nil {
+		t.Fatalf("EncryptKey: %v", err)
+	}
+
+	// Neither the mnemonic nor the private key may remain in plaintext
+	data, err := os.ReadFile(plain.Path)
+	if err != nil {
+		t.Fatalf("read key: %v", err)
+	}
+	if strings.Contains(string(data), plain.PrivateKeyBase64) || strings.Contains(string(data), strings.Fields(plain.Mnemonic)[0]+" ") {
+		t.Fatalf("encrypted key file contains plaintext key material:\n%s", data)
+	}
+	if encrypted, err := IsKeyEncrypted(dataDir); err != nil || !encrypted {
+		t.Fatalf("IsKeyEncrypted = %v, %v", encrypted, err)
+	}
+
+	if _, err := ReadKey(dataDir, KeyOptions{}); !errors.Is(err, ErrKeyEncrypted) {
+		t.Fatalf("expected ErrKeyEncrypted without passphrase, got %v", err)
+	}
+	if _, err := ReadKey(dataDir, staticPassphrase("wrong")); !errors.Is(err, crypto.ErrWrongPassphrase) {
+		t.Fatalf("expected ErrWrongPassphrase, got %v", err)
+	}
+
+	info, err := VerifyKey(dataDir, staticPassphrase("hunter2"))
+	if err != nil {
+		t.Fatalf("VerifyKey: %v", err)
+	}
+	if !info.Encrypted || info.PrivateKeyBase64 != plain.PrivateKeyBase64 || info.Mnemonic != plain.Mnemonic {
+		t.Fatalf("decrypted key does not match original")
+	}
+
+	// Starting with a locked key must fail rather than generate a new key
+	if _, _, err := loadOrCreateKey(dataDir, KeyOptions{}, false); err == nil {
+		t.Fatalf("loadOrCreateKey succeeded without passphrase")
+	}
+	kp, _, err := loadOrCreateKey(dataDir, staticPassphrase("hunter2"), false)
+	if err != nil {
+		t.Fatalf("loadOrCreateKey: %v", err)
+	}
+	if string(kp.PrivateKey) != string(plain.KeyPair.PrivateKey) {
+		t.Fatalf("loadOrCreateKey returned a different key")
+	}
+
+	// Rotation keeps the key encrypted with the same passphrase
+	if _, _, err := RotateKey(dataDir, staticPassphrase("hunter2")); err != nil {
+		t.Fatalf("RotateKey: %v", err)
+	}
+	if encrypted, _ := IsKeyEncrypted(dataDir); !encrypted {
+		t.Fatalf("rotated key is not encrypted")
+	}
+
+	if _, err := DecryptKey(dataDir, staticPassphrase("hunter2")); err != nil {
+		t.Fatalf("DecryptKey: %v", err)
+	}
+	if _, err := ReadKey(dataDir, KeyOptions{}); err != nil {
+		t.Fatalf("ReadKey after DecryptKey: %v", err)
+	}
+}
+
+func TestNewKeyEncryptedWithConfiguredPassphrase(t *testing.T) {
+	dataDir := t.TempDir()
+	opts := staticPassphrase("s3cret")
+	opts.EncryptNew = true
+
+	kp, _, err := loadOrCreateKey(dataDir, opts, false)
+	if err != nil {
+		t.Fatalf("loadOrCreateKey: %v", err)
+	}
+	if encrypted, _ := IsKeyEncrypted(dataDir); !encrypted {
+		t.Fatalf("new key was not encrypted")
+	}
+	info, err := ReadKey(dataDir, opts)
+	if err != nil {
+		t.Fatalf("ReadKey: %v", err)
+	}
+	if string(info.KeyPair.PrivateKey) != string(kp.PrivateKey) {
+		t.Fatalf("ReadKey returned a different key")
+	}
+}
+
+func TestLookupPassphrase(t *testing.T) {
+	t.Setenv(PassphraseEnv, "")
+	t.Setenv(PassphraseFileEnv, "")
+	t.Setenv("CREDENTIALS_DIRECTORY", "")
+
+	if _, ok, err := LookupPassphrase(); ok || err != nil {
+		t.Fatalf("expected no passphrase configured, got ok=%v err=%v", ok, err)
+	}
+
+	file := filepath.Join(t.TempDir(), "passphrase")
+	if err := os.WriteFile(file, []byte("from-file\n"), 0600); err != nil {
+		t.Fatalf("write passphrase file: %v", err)
+	}
+	t.Setenv(PassphraseFileEnv, file)
+	if p, ok, err := LookupPassphrase(); !ok || err != nil || string(p) != "from-file" {
+		t.Fatalf("file passphrase = %q, %v, %v", p, ok, err)
+	}
+
+	// The variable takes precedence over the file
+	t.Setenv(PassphraseEnv, "from-env")
+	if p, _, _ := LookupPassphrase(); string(p) != "from-env" {
+		t.Fatalf("env passphrase = %q", p)
+	}
+}
+
+func TestRestoreMnemonicOnStart(t *testing.T) {
+	mnemonic, err := crypto.GenerateMnemonic()
+	if err != nil {
+		t.Fatalf("GenerateMnemonic: %v", err)
+	}
+	const path = "m/7"
+	expected, err := crypto.DeriveKeyPairFromMnemonic(mnemonic, path)
+	if err != nil {
+		t.Fatalf("DeriveKeyPairFromMnemonic: %v", err)
+	}
+
+	dataDir := t.TempDir()
+	configPath := writeTempConfig(t, dataDir, `{}`)
+	opts := Options{
+		DataDir:           dataDir,
+		PsiphonConfigPath: configPath,
+		RestoreMnemonic:   mnemonic,
+		DerivationPath:    path,
+	}
+
+	// Restoring twice with the same mnemonic is fine, so the flag can stay in a unit file
+	for i := 0; i < 2; i++ {
+		cfg, err := LoadOrCreate(opts)
+		if err != nil {
+			t.Fatalf("LoadOrCreate #%d: %v", i, err)
+		}
+		if string(cfg.KeyPair.PrivateKey) != string(expected.PrivateKey) {
+			t.Fatalf("LoadOrCreate #%d restored the wrong key", i)
+		}
+	}
+
+	info, err := VerifyKey(dataDir, KeyOptions{})
+	if err != nil {
+		t.Fatalf("VerifyKey: %v", err)
+	}
+	if info.DerivationPath != path {
+		t.Fatalf("DerivationPath = %q, expected %q", info.DerivationPath, path)
+	}
+
+	// A different mnemonic must never replace the existing key
+	other, _ := crypto.GenerateMnemonic()
+	opts.RestoreMnemonic = other
+	if _, err := LoadOrCreate(opts); err == nil {
+		t.Fatalf("LoadOrCreate replaced an existing key with a different mnemonic")
+	}
+	if _, err := LoadOrCreate(Options{DataDir: t.TempDir(), PsiphonConfigPath: configPath, RestoreMnemonic: "not a mnemonic"}); err == nil {
+		t.Fatalf("expected error for invalid mnemonic")
+	}
+
+	// A fleet seed (non-empty path) is not stored on the station
+	if info.Mnemonic != "" {
+		t.Fatalf("mnemonic stored for a key with a derivation path")
+	}
+
+	// Adding the mnemonic to a key imported without one keeps the key
+	dataDir = t.TempDir()
+	expected, _ = crypto.DeriveKeyPairFromMnemonic(mnemonic, "")
+	encoded, _ := crypto.KeyPairToBase64NoPad(expected)
+	if _, _, err := ImportPrivateKey(dataDir, encoded, false, KeyOptions{}); err != nil {
+		t.Fatalf("ImportPrivateKey: %v", err)
+	}
+	info, _, err = ImportMnemonic(dataDir, mnemonic, "", false, KeyOptions{})
+	if err != nil {
+		t.Fatalf("ImportMnemonic over same key: %v", err)
+	}
+	if info.Mnemonic == "" {
+		t.Fatalf("mnemonic backup was not added to existing key")
+	}
+}
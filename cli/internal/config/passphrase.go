@@ -0,0 +1,63 @@
+/*
+ * Copyright (c) 2026, Psiphon Inc.
+ * All rights reserved.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package config
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/Psiphon-Inc/conduit/cli/internal/systemd"
+)
+
+// Environment variables that supply the key passphrase
+const (
+	PassphraseEnv     = "CONDUIT_KEY_PASSPHRASE"
+	PassphraseFileEnv = "CONDUIT_KEY_PASSPHRASE_FILE"
+)
+
+// LookupPassphrase returns the key passphrase configured non-interactively, from
+// $CONDUIT_KEY_PASSPHRASE, the file named by $CONDUIT_KEY_PASSPHRASE_FILE, or the
+// systemd credential conduit-key-passphrase, in that order.
+// The boolean reports whether any source was configured.
+func LookupPassphrase() ([]byte, bool, error) {
+	if passphrase := os.Getenv(PassphraseEnv); passphrase != "" {
+		return []byte(passphrase), true, nil
+	}
+
+	path := os.Getenv(PassphraseFileEnv)
+	if path == "" {
+		path, _ = systemd.CredentialPath(systemd.KeyPassphraseCredential)
+	}
+	if path == "" {
+		return nil, false, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, true, fmt.Errorf("failed to read passphrase file: %w", err)
+	}
+	// Tolerate the trailing newline most editors and 'echo' add
+	passphrase := strings.TrimRight(string(data), "\r\n")
+	if passphrase == "" {
+		return nil, true, fmt.Errorf("passphrase file %s is empty", path)
+	}
+	return []byte(passphrase), true, nil
+}
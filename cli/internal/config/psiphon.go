@@ -0,0 +1,315 @@
+/*
+ * Copyright (c) 2026, Psiphon Inc.
+ * All rights reserved.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Psiphon config issue severities
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+)
+
+// PsiphonIssue is a problem found in a Psiphon config
+type PsiphonIssue struct {
+	Severity string `json:"severity"`
+	Key      string `json:"key,omitempty"`
+	Message  string `json:"message"`
+}
+
+// PsiphonChange is a top-level key that differs between two Psiphon configs.
+// Old or New is nil when the key is missing on that side.
+type PsiphonChange struct {
+	Key string `json:"key"`
+	Old any    `json:"old,omitempty"`
+	New any    `json:"new,omitempty"`
+}
+
+// psiphonKinds are the JSON types of the Psiphon config keys conduit relies on
+var psiphonKinds = map[string]string{
+	"PropagationChannelId":                 "string",
+	"SponsorId":                            "string",
+	"AdditionalParameters":                 "string",
+	"InproxyBrokerSpecs":                   "array",
+	"InproxyProxyBrokerSpecs":              "array",
+	"ClientPlatform":                       "string",
+	"ClientVersion":                        "string",
+	"DataRootDirectory":                    "string",
+	"DisableTunnels":                       "bool",
+	"DisableLocalHTTPProxy":                "bool",
+	"DisableLocalSocksProxy":               "bool",
+	"EmitDiagnosticNotices":                "bool",
+	"EmitInproxyProxyActivity":             "bool",
+	"FeedbackUploadURLs":                   "array",
+	"FeedbackEncryptionPublicKey":          "string",
+	"InproxyEnableProxy":                   "bool",
+	"InproxyMaxClients":                    "number",
+	"InproxyLimitUpstreamBytesPerSecond":   "number",
+	"InproxyLimitDownstreamBytesPerSecond": "number",
+	"InproxyProxySessionPrivateKey":        "string",
+	"RemoteServerListURLs":                 "array",
+	"RemoteServerListSignaturePublicKey":   "string",
+	"ServerEntrySignaturePublicKey":        "string",
+}
+
+// psiphonOverrideSources says what sets each key conduit overrides
+var psiphonOverrideSources = map[string]string{
+	"DataRootDirectory":                    "--data-dir",
+	"InproxyMaxClients":                    "--max-clients",
+	"InproxyLimitUpstreamBytesPerSecond":   "--bandwidth",
+	"InproxyLimitDownstreamBytesPerSecond": "--bandwidth",
+	"InproxyProxySessionPrivateKey":        "the station key",
+}
+
+// PsiphonOverrides returns the tunnel-core settings conduit applies on top of
+// the Psiphon config
+func (c *Config) PsiphonOverrides() map[string]any {
+	overrides := map[string]any{
+		"DataRootDirectory": c.DataDir,
+
+		// Client version - used by broker for compatibility
+		"ClientVersion": "1",
+
+		// Inproxy mode settings
+		"InproxyEnableProxy":            true,
+		"InproxyMaxClients":             c.MaxClients,
+		"InproxyProxySessionPrivateKey": c.PrivateKeyBase64,
+
+		// Disable regular tunnel functionality - we're just a proxy
+		"DisableTunnels": true,
+
+		// Disable local proxies (not needed for inproxy mode)
+		"DisableLocalHTTPProxy":  true,
+		"DisableLocalSocksProxy": true,
+
+		// Enable activity notices for stats
+		"EmitInproxyProxyActivity": true,
+
+		// Keep diagnostic notices enabled (we filter in handleNotice)
+		// This is needed to get the broker connection status
+		"EmitDiagnosticNotices": true,
+	}
+	// Only set bandwidth limits if not unlimited (0 means unlimited)
+	if c.BandwidthBytesPerSecond > 0 {
+		overrides["InproxyLimitUpstreamBytesPerSecond"] = c.BandwidthBytesPerSecond
+		overrides["InproxyLimitDownstreamBytesPerSecond"] = c.BandwidthBytesPerSecond
+	}
+	return overrides
+}
+
+// ParsePsiphonConfig parses a Psiphon config into its top-level keys
+func ParsePsiphonConfig(data []byte) (map[string]any, error) {
+	var config map[string]any
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, err
+	}
+	if config == nil {
+		return nil, fmt.Errorf("config is not a JSON object")
+	}
+	return config, nil
+}
+
+// MergePsiphonConfig returns the Psiphon config with overrides applied
+func MergePsiphonConfig(data []byte, overrides map[string]any) (map[string]any, error) {
+	config, err := ParsePsiphonConfig(data)
+	if err != nil {
+		return nil, err
+	}
+	for key, value := range overrides {
+		config[key] = value
+	}
+	return config, nil
+}
+
+// ValidatePsiphonConfig checks a Psiphon config before tunnel-core loads it.
+// Keys in the config that overrides would change are reported as warnings.
+func ValidatePsiphonConfig(data []byte, overrides map[string]any) []PsiphonIssue {
+	config, err := ParsePsiphonConfig(data)
+	if err != nil {
+		return []PsiphonIssue{{Severity: SeverityError, Message: fmt.Sprintf("invalid JSON: %v", err)}}
+	}
+
+	var issues []PsiphonIssue
+	add := func(severity, key, format string, args ...any) {
+		issues = append(issues, PsiphonIssue{Severity: severity, Key: key, Message: fmt.Sprintf(format, args...)})
+	}
+
+	for _, key := range sortedKeys(config) {
+		if kind, ok := psiphonKinds[key]; ok && config[key] != nil && jsonKind(config[key]) != kind {
+			add(SeverityError, key, "has type %s, expected %s", jsonKind(config[key]), kind)
+		}
+	}
+	for _, key := range []string{"PropagationChannelId", "SponsorId"} {
+		value, _ := config[key].(string)
+		switch {
+		case config[key] == nil || value == "" && jsonKind(config[key]) == "string":
+			add(SeverityError, key, "is required")
+		case value != "" && strings.Trim(value, "F") == "":
+			add(SeverityError, key, "has the example's placeholder value; contact conduit-oss@psiphon.ca for a network config, or use an official release")
+		}
+	}
+	if isEmpty(config["AdditionalParameters"]) && isEmpty(config["InproxyBrokerSpecs"]) && isEmpty(config["InproxyProxyBrokerSpecs"]) {
+		add(SeverityError, "AdditionalParameters", "no broker specs: the config needs AdditionalParameters or InproxyBrokerSpecs")
+	}
+
+	for _, key := range sortedKeys(overrides) {
+		value, ok := config[key]
+		if !ok || jsonEqual(value, overrides[key]) {
+			continue
+		}
+		source, ok := psiphonOverrideSources[key]
+		if !ok {
+			source = "conduit"
+		}
+		if key == "InproxyProxySessionPrivateKey" {
+			add(SeverityWarning, key, "is ignored: set by %s", source)
+			continue
+		}
+		add(SeverityWarning, key, "is ignored: set to %s by %s", jsonString(overrides[key]), source)
+	}
+	return issues
+}
+
+// RedactPsiphonConfig returns a copy of a Psiphon config with keys, secrets
+// and the encrypted broker parameters replaced
+func RedactPsiphonConfig(config map[string]any) map[string]any {
+	redacted := make(map[string]any, len(config))
+	for key, value := range config {
+		redacted[key] = redactPsiphonValue(key, value)
+	}
+	return redacted
+}
+
+// DiffPsiphonConfig lists the top-level keys that differ between two Psiphon
+// configs, with secret values redacted
+func DiffPsiphonConfig(old, new map[string]any) []PsiphonChange {
+	keys := map[string]any{}
+	for key := range old {
+		keys[key] = nil
+	}
+	for key := range new {
+		keys[key] = nil
+	}
+	var changes []PsiphonChange
+	for _, key := range sortedKeys(keys) {
+		oldValue, inOld := old[key]
+		newValue, inNew := new[key]
+		if inOld && inNew && jsonEqual(oldValue, newValue) {
+			continue
+		}
+		change := PsiphonChange{Key: key}
+		if inOld {
+			change.Old = redactPsiphonValue(key, oldValue)
+		}
+		if inNew {
+			change.New = redactPsiphonValue(key, newValue)
+		}
+		changes = append(changes, change)
+	}
+	return changes
+}
+
+// isSecretPsiphonKey reports whether a key holds a secret. Public keys, like
+// the signature keys, are not secrets.
+func isSecretPsiphonKey(key string) bool {
+	if key == "AdditionalParameters" {
+		return true
+	}
+	lower := strings.ToLower(key)
+	for _, marker := range []string{"privatekey", "secret", "password", "token", "obfuscationkey", "obfuscatedkey"} {
+		if strings.Contains(lower, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+func redactPsiphonValue(key string, value any) any {
+	if isSecretPsiphonKey(key) && !isEmpty(value) {
+		return "REDACTED"
+	}
+	switch v := value.(type) {
+	case map[string]any:
+		return RedactPsiphonConfig(v)
+	case []any:
+		items := make([]any, len(v))
+		for i, item := range v {
+			items[i] = redactPsiphonValue("", item)
+		}
+		return items
+	}
+	return value
+}
+
+func jsonKind(value any) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "bool"
+	case string:
+		return "string"
+	case []any:
+		return "array"
+	case map[string]any:
+		return "object"
+	}
+	return "number"
+}
+
+func isEmpty(value any) bool {
+	switch v := value.(type) {
+	case nil:
+		return true
+	case string:
+		return v == ""
+	case []any:
+		return len(v) == 0
+	case map[string]any:
+		return len(v) == 0
+	}
+	return false
+}
+
+func jsonString(value any) string {
+	data, _ := json.Marshal(value)
+	return string(data)
+}
+
+func jsonEqual(a, b any) bool {
+	dataA, errA := json.Marshal(a)
+	dataB, errB := json.Marshal(b)
+	return errA == nil && errB == nil && bytes.Equal(dataA, dataB)
+}
+
+func sortedKeys(m map[string]any) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
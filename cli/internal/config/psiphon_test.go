@@ -0,0 +1,131 @@
+/*
+ * Copyright (c) 2026, Psiphon Inc.
+ * All rights reserved.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package config
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+const testPsiphonConfig = `{"PropagationChannelId":"0123456789ABCDEF","SponsorId":"0123456789ABCDEF","AdditionalParameters":"abc"}`
+
+func TestValidatePsiphonConfig(t *testing.T) {
+	overrides := (&Config{DataDir: "/var/lib/conduit", MaxClients: 50, PrivateKeyBase64: "key"}).PsiphonOverrides()
+
+	tests := []struct {
+		name     string
+		config   string
+		expected []string // severity:key of each issue
+	}{
+		{"valid", testPsiphonConfig, nil},
+		{"broker specs", `{"PropagationChannelId":"0123","SponsorId":"0123","InproxyBrokerSpecs":[{}]}`, nil},
+		{"invalid json", `{"PropagationChannelId":`, []string{"error:"}},
+		{"not an object", `[]`, []string{"error:"}},
+		{"missing ids", `{"AdditionalParameters":"abc"}`, []string{"error:PropagationChannelId", "error:SponsorId"}},
+		{"empty sponsor", `{"PropagationChannelId":"0123","SponsorId":"","AdditionalParameters":"abc"}`, []string{"error:SponsorId"}},
+		{"placeholders", `{"PropagationChannelId":"FFFFFFFFFFFFFFFF","SponsorId":"FFFFFFFFFFFFFFFF","AdditionalParameters":"abc"}`,
+			[]string{"error:PropagationChannelId", "error:SponsorId"}},
+		{"no broker specs", `{"PropagationChannelId":"0123","SponsorId":"0123"}`, []string{"error:AdditionalParameters"}},
+		{"wrong types", `{"PropagationChannelId":1,"SponsorId":"0123","AdditionalParameters":{"a":1},"DisableTunnels":"yes"}`,
+			[]string{"error:AdditionalParameters", "error:DisableTunnels", "error:PropagationChannelId", "warning:DisableTunnels"}},
+		{"overridden", `{"PropagationChannelId":"0123","SponsorId":"0123","AdditionalParameters":"abc","DataRootDirectory":"/tmp","DisableTunnels":false,"EmitDiagnosticNotices":true,"InproxyMaxClients":50,"InproxyProxySessionPrivateKey":"other"}`,
+			[]string{"warning:DataRootDirectory", "warning:DisableTunnels", "warning:InproxyProxySessionPrivateKey"}},
+	}
+	for _, tt := range tests {
+		var got []string
+		for _, issue := range ValidatePsiphonConfig([]byte(tt.config), overrides) {
+			got = append(got, issue.Severity+":"+issue.Key)
+			if strings.Contains(issue.Message, "other") || strings.Contains(issue.Message, `"key"`) {
+				t.Fatalf("%s: issue leaks the private key: %s", tt.name, issue.Message)
+			}
+		}
+		if strings.Join(got, ",") != strings.Join(tt.expected, ",") {
+			t.Fatalf("%s: issues %v, expected %v", tt.name, got, tt.expected)
+		}
+	}
+}
+
+func TestMergePsiphonConfig(t *testing.T) {
+	cfg := &Config{DataDir: "/var/lib/conduit", MaxClients: 20, PrivateKeyBase64: "key"}
+	merged, err := MergePsiphonConfig([]byte(`{"SponsorId":"0123","DisableTunnels":false,"InproxyLimitUpstreamBytesPerSecond":1000}`), cfg.PsiphonOverrides())
+	if err != nil {
+		t.Fatalf("MergePsiphonConfig: %v", err)
+	}
+	for key, expected := range map[string]any{
+		"SponsorId":                          "0123",
+		"DisableTunnels":                     true,
+		"DataRootDirectory":                  "/var/lib/conduit",
+		"InproxyMaxClients":                  20,
+		"InproxyLimitUpstreamBytesPerSecond": 1000.0, // unlimited keeps the base config's value
+	} {
+		if merged[key] != expected {
+			t.Fatalf("%s = %v, expected %v", key, merged[key], expected)
+		}
+	}
+}
+
+func TestRedactPsiphonConfig(t *testing.T) {
+	config := map[string]any{
+		"SponsorId":                          "0123",
+		"AdditionalParameters":               "encrypted",
+		"InproxyProxySessionPrivateKey":      "key",
+		"RemoteServerListSignaturePublicKey": "public",
+		"InproxyBrokerSpecs": []any{map[string]any{
+			"BrokerPublicKey":             "public",
+			"BrokerRootObfuscationSecret": "secret",
+		}},
+		"UpstreamProxyPassword": "",
+	}
+	data, _ := json.Marshal(RedactPsiphonConfig(config))
+	for _, secret := range []string{"encrypted", `"key"`, "secret"} {
+		if strings.Contains(string(data), secret) {
+			t.Fatalf("redacted config contains %s: %s", secret, data)
+		}
+	}
+	for _, kept := range []string{`"0123"`, `"public"`, `"UpstreamProxyPassword":""`} {
+		if !strings.Contains(string(data), kept) {
+			t.Fatalf("redacted config lost %s: %s", kept, data)
+		}
+	}
+	if config["AdditionalParameters"] != "encrypted" {
+		t.Fatalf("RedactPsiphonConfig modified its argument")
+	}
+}
+
+func TestDiffPsiphonConfig(t *testing.T) {
+	old := map[string]any{"SponsorId": "0123", "EmitServerAlerts": true, "AdditionalParameters": "a", "Gone": 1.0}
+	new := map[string]any{"SponsorId": "0123", "EmitServerAlerts": false, "AdditionalParameters": "b", "Added": []any{"x"}}
+
+	var got []string
+	for _, change := range DiffPsiphonConfig(old, new) {
+		data, _ := json.Marshal(change)
+		got = append(got, string(data))
+	}
+	expected := []string{
+		`{"key":"Added","new":["x"]}`,
+		`{"key":"AdditionalParameters","old":"REDACTED","new":"REDACTED"}`,
+		`{"key":"EmitServerAlerts","old":true,"new":false}`,
+		`{"key":"Gone","old":1}`,
+	}
+	if strings.Join(got, "\n") != strings.Join(expected, "\n") {
+		t.Fatalf("changes:\n%s\nexpected:\n%s", strings.Join(got, "\n"), strings.Join(expected, "\n"))
+	}
+}
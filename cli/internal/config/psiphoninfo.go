@@ -0,0 +1,93 @@
+/*
+ * Copyright (c) 2026, Psiphon Inc.
+ * All rights reserved.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package config
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// Psiphon config sources
+const (
+	SourceEmbedded = "embedded"
+	SourceFile     = "file"
+	SourceRemote   = "remote"
+)
+
+// PsiphonConfigInfo identifies a Psiphon config, so fleets running mixed
+// builds and configs can be audited
+type PsiphonConfigInfo struct {
+	Source               string `json:"source"`
+	Fingerprint          string `json:"fingerprint"` // SHA-256 of the config, hex
+	PropagationChannelId string `json:"propagationChannelId,omitempty"`
+	SponsorId            string `json:"sponsorId,omitempty"`
+	Version              string `json:"version,omitempty"` // The config's ConfigVersion, if any
+}
+
+// DescribePsiphonConfig fingerprints a Psiphon config and reads its
+// identifying fields. Unparseable configs still get a fingerprint.
+func DescribePsiphonConfig(source string, data []byte) PsiphonConfigInfo {
+	sum := sha256.Sum256(data)
+	info := PsiphonConfigInfo{Source: source, Fingerprint: hex.EncodeToString(sum[:])}
+	var fields struct {
+		PropagationChannelId string
+		SponsorId            string
+		ConfigVersion        json.RawMessage
+	}
+	if json.Unmarshal(data, &fields) == nil {
+		info.PropagationChannelId = fields.PropagationChannelId
+		info.SponsorId = fields.SponsorId
+		// A string or a number
+		var version string
+		if json.Unmarshal(fields.ConfigVersion, &version) != nil && len(fields.ConfigVersion) > 0 {
+			version = string(fields.ConfigVersion)
+		}
+		info.Version = version
+	}
+	return info
+}
+
+// EmbeddedPsiphonConfigInfo describes the config embedded at build time
+func EmbeddedPsiphonConfigInfo() (PsiphonConfigInfo, bool) {
+	if !HasEmbeddedConfig() {
+		return PsiphonConfigInfo{}, false
+	}
+	return DescribePsiphonConfig(SourceEmbedded, GetEmbeddedPsiphonConfig()), true
+}
+
+// PsiphonConfigInfo describes the Psiphon config the service runs with
+func (c *Config) PsiphonConfigInfo() (PsiphonConfigInfo, error) {
+	switch {
+	case c.PsiphonConfigURL != "":
+		return DescribePsiphonConfig(SourceRemote, c.PsiphonConfigData), nil
+	case len(c.PsiphonConfigData) > 0:
+		return DescribePsiphonConfig(SourceEmbedded, c.PsiphonConfigData), nil
+	case c.PsiphonConfigPath != "":
+		data, err := os.ReadFile(c.PsiphonConfigPath)
+		if err != nil {
+			return PsiphonConfigInfo{}, fmt.Errorf("failed to read psiphon config file: %w", err)
+		}
+		return DescribePsiphonConfig(SourceFile, data), nil
+	}
+	return PsiphonConfigInfo{}, fmt.Errorf("no psiphon config available")
+}
@@ -0,0 +1,75 @@
+/*
+ * Copyright (c) 2026, Psiphon Inc.
+ * All rights reserved.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDescribePsiphonConfig(t *testing.T) {
+	tests := []struct {
+		config  string
+		ids     string
+		version string
+	}{
+		{`{"PropagationChannelId":"AB","SponsorId":"CD","ConfigVersion":"2026.10"}`, "AB/CD", "2026.10"},
+		{`{"PropagationChannelId":"AB","SponsorId":"CD","ConfigVersion":42}`, "AB/CD", "42"},
+		{`{"PropagationChannelId":"AB","SponsorId":"CD"}`, "AB/CD", ""},
+		{`not json`, "/", ""},
+	}
+	for _, tt := range tests {
+		info := DescribePsiphonConfig(SourceFile, []byte(tt.config))
+		if info.PropagationChannelId+"/"+info.SponsorId != tt.ids || info.Version != tt.version || len(info.Fingerprint) != 64 || info.Source != SourceFile {
+			t.Fatalf("DescribePsiphonConfig(%s) = %+v", tt.config, info)
+		}
+	}
+	if a, b := DescribePsiphonConfig(SourceFile, []byte(`{}`)), DescribePsiphonConfig(SourceFile, []byte(`{ }`)); a.Fingerprint == b.Fingerprint {
+		t.Fatalf("different configs have the same fingerprint")
+	}
+}
+
+func TestConfigPsiphonConfigInfo(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "psiphon_config.json")
+	if err := os.WriteFile(path, []byte(`{"SponsorId":"file"}`), 0600); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	data := []byte(`{"SponsorId":"data"}`)
+
+	tests := []struct {
+		config  Config
+		source  string
+		sponsor string
+	}{
+		{Config{PsiphonConfigPath: path}, SourceFile, "file"},
+		{Config{PsiphonConfigData: data}, SourceEmbedded, "data"},
+		{Config{PsiphonConfigPath: path, PsiphonConfigData: data, PsiphonConfigURL: "https://example.com/c.json"}, SourceRemote, "data"},
+	}
+	for _, tt := range tests {
+		info, err := tt.config.PsiphonConfigInfo()
+		if err != nil || info.Source != tt.source || info.SponsorId != tt.sponsor {
+			t.Fatalf("PsiphonConfigInfo() = %+v, %v, expected %s from %s", info, err, tt.sponsor, tt.source)
+		}
+	}
+	if _, err := (&Config{}).PsiphonConfigInfo(); err == nil {
+		t.Fatalf("PsiphonConfigInfo() without a config succeeded")
+	}
+}
@@ -0,0 +1,58 @@
+/*
+ * Copyright (c) 2026, Psiphon Inc.
+ * All rights reserved.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package config
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Environment variables that supply the metrics, dashboard and debug
+// endpoint credentials (or, with a _FILE suffix, files containing them)
+const (
+	MetricsTokenEnv      = "CONDUIT_METRICS_TOKEN"
+	MetricsPasswordEnv   = "CONDUIT_METRICS_PASSWORD"
+	DashboardTokenEnv    = "CONDUIT_DASHBOARD_TOKEN"
+	DashboardPasswordEnv = "CONDUIT_DASHBOARD_PASSWORD"
+	DebugTokenEnv        = "CONDUIT_DEBUG_TOKEN"
+)
+
+// LookupSecret returns a secret from the environment variable name, or from
+// the file named by name_FILE (e.g. a Docker or systemd secret). It returns
+// "" when neither is set.
+func LookupSecret(name string) (string, error) {
+	if secret := os.Getenv(name); secret != "" {
+		return secret, nil
+	}
+	path := os.Getenv(name + "_FILE")
+	if path == "" {
+		return "", nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s_FILE: %w", name, err)
+	}
+	secret := strings.TrimRight(string(data), "\r\n")
+	if secret == "" {
+		return "", fmt.Errorf("%s_FILE %s is empty", name, path)
+	}
+	return secret, nil
+}
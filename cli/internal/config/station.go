@@ -0,0 +1,93 @@
+/*
+ * Copyright (c) 2026, Psiphon Inc.
+ * All rights reserved.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+const stationFileName = "conduit_station.json"
+
+// MaxStationNameLength matches the name field in the mobile app
+const MaxStationNameLength = 22
+
+// persistedStation holds station settings saved in the data directory
+type persistedStation struct {
+	Name string `json:"name"`
+}
+
+// StationFilePath returns the path of the station settings file in dataDir
+func StationFilePath(dataDir string) string {
+	return filepath.Join(dataDir, stationFileName)
+}
+
+// ValidateStationName checks a station name. The name is cosmetic and
+// optional, so the empty name is valid.
+func ValidateStationName(name string) error {
+	if utf8.RuneCountInString(name) > MaxStationNameLength {
+		return fmt.Errorf("station name must be at most %d characters", MaxStationNameLength)
+	}
+	if strings.IndexFunc(name, unicode.IsControl) >= 0 {
+		return fmt.Errorf("station name must not contain control characters")
+	}
+	return nil
+}
+
+// LoadStationName returns the persisted station name, or "" if none is set
+func LoadStationName(dataDir string) (string, error) {
+	data, err := os.ReadFile(StationFilePath(dataDir))
+	if errors.Is(err, os.ErrNotExist) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read station name: %w", err)
+	}
+	var station persistedStation
+	if err := json.Unmarshal(data, &station); err != nil {
+		return "", fmt.Errorf("failed to parse %s: %w", stationFileName, err)
+	}
+	return station.Name, nil
+}
+
+// SaveStationName persists the station name in dataDir
+func SaveStationName(dataDir, name string) error {
+	name = strings.TrimSpace(name)
+	if err := ValidateStationName(name); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dataDir, 0700); err != nil {
+		return fmt.Errorf("failed to create data directory: %w", err)
+	}
+	data, err := json.MarshalIndent(persistedStation{Name: name}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal station name: %w", err)
+	}
+	if err := WriteFileAtomic(StationFilePath(dataDir), data, 0600); err != nil {
+		return fmt.Errorf("failed to save station name: %w", err)
+	}
+	return nil
+}
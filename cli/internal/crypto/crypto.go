@@ -152,3 +152,17 @@ func KeyPairToCurve25519Base64(kp *KeyPair) (string, error) {
 
 	return base64.RawStdEncoding.EncodeToString(curveKey[:]), nil
 }
+
+// PublicKeyBase64 returns the Ed25519 public key as unpadded base64
+func PublicKeyBase64(kp *KeyPair) string {
+	if kp == nil {
+		return ""
+	}
+	return base64.RawStdEncoding.EncodeToString(kp.PublicKey)
+}
+
+// StationDerivationPath returns the derivation path of the station at index
+// when deriving a fleet of station keys from a single mnemonic
+func StationDerivationPath(index uint32) string {
+	return fmt.Sprintf("m/%d", index)
+}
@@ -22,7 +22,9 @@ package crypto
 import (
 	"crypto/ed25519"
 	"encoding/base64"
+	"errors"
 	"fmt"
+	"strings"
 	"testing"
 )
 
@@ -109,3 +111,54 @@ func TestKeyPairToBase64NoPad(t *testing.T) {
 
 	fmt.Println("encoded:", "'"+encoded+"'", "len:", len(encoded))
 }
+
+func TestSealOpen(t *testing.T) {
+	plaintext := []byte(`{"privateKeyBase64":"secret"}`)
+	passphrase := []byte("correct horse battery staple")
+
+	box, err := Seal(plaintext, passphrase)
+	if err != nil {
+		t.Fatalf("Seal failed: %v", err)
+	}
+	if strings.Contains(box.Ciphertext, "secret") {
+		t.Fatalf("ciphertext contains plaintext")
+	}
+
+	opened, err := Open(box, passphrase)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	if string(opened) != string(plaintext) {
+		t.Fatalf("Open returned %q, expected %q", opened, plaintext)
+	}
+
+	if _, err := Open(box, []byte("wrong")); !errors.Is(err, ErrWrongPassphrase) {
+		t.Fatalf("expected ErrWrongPassphrase, got %v", err)
+	}
+
+	// Sealing the same data twice must use a fresh salt and nonce
+	box2, err := Seal(plaintext, passphrase)
+	if err != nil {
+		t.Fatalf("second Seal failed: %v", err)
+	}
+	if box.Salt == box2.Salt || box.Nonce == box2.Nonce || box.Ciphertext == box2.Ciphertext {
+		t.Fatalf("sealed boxes are not randomized")
+	}
+
+	// Refuse absurd KDF costs from a tampered file
+	for _, tamper := range []func(b *SealedBox){
+		func(b *SealedBox) { b.N = 1 << 30 },
+		func(b *SealedBox) { b.R = 1 << 20 },
+		func(b *SealedBox) { b.P = 1 << 20 },
+	} {
+		tampered := *box2
+		tamper(&tampered)
+		if _, err := Open(&tampered, passphrase); err == nil {
+			t.Fatalf("expected error for excessive scrypt cost %d/%d/%d", tampered.N, tampered.R, tampered.P)
+		}
+	}
+
+	if _, err := Seal(plaintext, nil); err == nil {
+		t.Fatalf("expected error for empty passphrase")
+	}
+}
@@ -0,0 +1,139 @@
+/*
+ * Copyright (c) 2026, Psiphon Inc.
+ * All rights reserved.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package crypto
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+
+	"golang.org/x/crypto/chacha20poly1305"
+	"golang.org/x/crypto/scrypt"
+)
+
+// scrypt cost parameters for new sealed boxes (~100ms and 32MB on commodity hardware)
+const (
+	scryptN      = 1 << 15
+	scryptR      = 8
+	scryptP      = 1
+	scryptKeyLen = chacha20poly1305.KeySize
+	saltLen      = 16
+
+	// maxScryptN, maxScryptR and maxScryptP bound the cost read from disk so a
+	// tampered file can't exhaust memory (128*N*R bytes, at most 1GB) or CPU
+	maxScryptN = 1 << 20
+	maxScryptR = 8
+	maxScryptP = 4
+
+	sealAssociatedData = "conduit-sealed-box-v1"
+)
+
+// ErrWrongPassphrase is returned when a sealed box can't be opened with the given passphrase
+var ErrWrongPassphrase = errors.New("wrong passphrase or corrupted data")
+
+// SealedBox is data encrypted with a passphrase using scrypt and XChaCha20-Poly1305
+type SealedBox struct {
+	KDF        string `json:"kdf"`
+	N          int    `json:"n"`
+	R          int    `json:"r"`
+	P          int    `json:"p"`
+	Salt       string `json:"salt"`
+	Cipher     string `json:"cipher"`
+	Nonce      string `json:"nonce"`
+	Ciphertext string `json:"ciphertext"`
+}
+
+// Seal encrypts plaintext with a key derived from passphrase
+func Seal(plaintext, passphrase []byte) (*SealedBox, error) {
+	if len(passphrase) == 0 {
+		return nil, errors.New("passphrase is empty")
+	}
+
+	salt := make([]byte, saltLen)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, fmt.Errorf("failed to generate salt: %w", err)
+	}
+	nonce := make([]byte, chacha20poly1305.NonceSizeX)
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+
+	key, err := scrypt.Key(passphrase, salt, scryptN, scryptR, scryptP, scryptKeyLen)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive key: %w", err)
+	}
+	aead, err := chacha20poly1305.NewX(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+
+	return &SealedBox{
+		KDF:        "scrypt",
+		N:          scryptN,
+		R:          scryptR,
+		P:          scryptP,
+		Salt:       base64.RawStdEncoding.EncodeToString(salt),
+		Cipher:     "xchacha20-poly1305",
+		Nonce:      base64.RawStdEncoding.EncodeToString(nonce),
+		Ciphertext: base64.RawStdEncoding.EncodeToString(aead.Seal(nil, nonce, plaintext, []byte(sealAssociatedData))),
+	}, nil
+}
+
+// Open decrypts a sealed box with passphrase
+func Open(box *SealedBox, passphrase []byte) ([]byte, error) {
+	if box == nil {
+		return nil, errors.New("sealed box is nil")
+	}
+	if box.KDF != "scrypt" || box.Cipher != "xchacha20-poly1305" {
+		return nil, fmt.Errorf("unsupported encryption: %s/%s", box.KDF, box.Cipher)
+	}
+	if box.N <= 1 || box.N > maxScryptN || box.R <= 0 || box.R > maxScryptR || box.P <= 0 || box.P > maxScryptP {
+		return nil, fmt.Errorf("invalid scrypt parameters")
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(box.Salt)
+	if err != nil {
+		return nil, fmt.Errorf("invalid salt: %w", err)
+	}
+	nonce, err := base64.RawStdEncoding.DecodeString(box.Nonce)
+	if err != nil || len(nonce) != chacha20poly1305.NonceSizeX {
+		return nil, fmt.Errorf("invalid nonce")
+	}
+	ciphertext, err := base64.RawStdEncoding.DecodeString(box.Ciphertext)
+	if err != nil {
+		return nil, fmt.Errorf("invalid ciphertext: %w", err)
+	}
+
+	key, err := scrypt.Key(passphrase, salt, box.N, box.R, box.P, scryptKeyLen)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive key: %w", err)
+	}
+	aead, err := chacha20poly1305.NewX(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+
+	plaintext, err := aead.Open(nil, nonce, ciphertext, []byte(sealAssociatedData))
+	if err != nil {
+		return nil, ErrWrongPassphrase
+	}
+	return plaintext, nil
+}
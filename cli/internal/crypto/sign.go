@@ -0,0 +1,68 @@
+/*
+ * Copyright (c) 2026, Psiphon Inc.
+ * All rights reserved.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package crypto
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/x509"
+	"encoding/base64"
+	"fmt"
+	"strings"
+)
+
+// ParseEd25519PublicKey parses a base64 Ed25519 public key, either the raw
+// 32 bytes or DER (as printed by 'openssl pkey -pubout -outform DER | base64')
+func ParseEd25519PublicKey(s string) (ed25519.PublicKey, error) {
+	der, err := base64.StdEncoding.DecodeString(strings.TrimSpace(s))
+	if err != nil {
+		return nil, fmt.Errorf("invalid public key: %w", err)
+	}
+	if len(der) == ed25519.PublicKeySize {
+		return ed25519.PublicKey(der), nil
+	}
+	key, err := x509.ParsePKIXPublicKey(der)
+	if err != nil {
+		return nil, fmt.Errorf("invalid public key: %w", err)
+	}
+	edKey, ok := key.(ed25519.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("invalid public key: not an Ed25519 key")
+	}
+	return edKey, nil
+}
+
+// VerifySignature checks an Ed25519 signature of data. The signature is the
+// 64 bytes either raw or base64 encoded, as produced by
+// 'openssl pkeyutl -sign -rawin' with or without '| base64'.
+func VerifySignature(key ed25519.PublicKey, data, signature []byte) error {
+	sig := signature
+	if len(sig) != ed25519.SignatureSize {
+		decoded, err := base64.StdEncoding.DecodeString(string(bytes.TrimSpace(signature)))
+		if err != nil || len(decoded) != ed25519.SignatureSize {
+			return fmt.Errorf("malformed signature")
+		}
+		sig = decoded
+	}
+	if !ed25519.Verify(key, data, sig) {
+		return fmt.Errorf("signature does not match the pinned public key")
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+/*
+ * Copyright (c) 2026, Psiphon Inc.
+ * All rights reserved.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package crypto
+
+import (
+	"crypto/ed25519"
+	"crypto/x509"
+	"encoding/base64"
+	"testing"
+)
+
+func TestParseEd25519PublicKey(t *testing.T) {
+	public, _, _ := ed25519.GenerateKey(nil)
+	der, _ := x509.MarshalPKIXPublicKey(public)
+
+	for _, encoded := range []string{
+		base64.StdEncoding.EncodeToString(public),
+		base64.StdEncoding.EncodeToString(der) + "\n",
+	} {
+		key, err := ParseEd25519PublicKey(encoded)
+		if err != nil || !key.Equal(public) {
+			t.Fatalf("ParseEd25519PublicKey(%q) = %v, %v", encoded, key, err)
+		}
+	}
+	for _, bad := range []string{"", "not base64!", base64.StdEncoding.EncodeToString([]byte("short"))} {
+		if _, err := ParseEd25519PublicKey(bad); err == nil {
+			t.Fatalf("ParseEd25519PublicKey(%q) succeeded", bad)
+		}
+	}
+}
+
+func TestVerifySignature(t *testing.T) {
+	public, private, _ := ed25519.GenerateKey(nil)
+	other, _, _ := ed25519.GenerateKey(nil)
+	data := []byte("checksums")
+	sig := ed25519.Sign(private, data)
+
+	tests := []struct {
+		name  string
+		key   ed25519.PublicKey
+		data  []byte
+		sig   []byte
+		valid bool
+	}{
+		{"raw", public, data, sig, true},
+		{"base64", public, data, []byte(base64.StdEncoding.EncodeToString(sig) + "\n"), true},
+		{"other key", other, data, sig, false},
+		{"other data", public, []byte("checksumz"), sig, false},
+		{"malformed", public, data, []byte("not a signature"), false},
+	}
+	for _, tt := range tests {
+		if err := VerifySignature(tt.key, tt.data, tt.sig); (err == nil) != tt.valid {
+			t.Fatalf("%s: VerifySignature = %v", tt.name, err)
+		}
+	}
+}
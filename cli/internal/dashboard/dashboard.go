@@ -0,0 +1,213 @@
+/*
+ * Copyright (c) 2026, Psiphon Inc.
+ * All rights reserved.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+// Package dashboard serves a small embedded web UI with live station stats
+package dashboard
+
+import (
+	"context"
+	"embed"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/Psiphon-Inc/conduit/cli/internal/events"
+	"github.com/Psiphon-Inc/conduit/cli/internal/httpserver"
+)
+
+//go:embed static
+var staticFiles embed.FS
+
+// DefaultInterval is how often the events endpoint pushes a new state
+const DefaultInterval = time.Second
+
+// keepAliveInterval is how often an idle stream sends a comment, so proxies
+// don't close it
+const keepAliveInterval = 15 * time.Second
+
+// StateFunc returns the current state shown by the dashboard. It must be safe
+// to call concurrently and return a JSON-serializable value.
+type StateFunc func() any
+
+// Server serves the dashboard UI and its JSON and SSE endpoints
+type Server struct {
+	state    StateFunc
+	events   *events.Bus
+	interval time.Duration
+	server   *httpserver.Server
+
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// New creates a dashboard server for state
+func New(state StateFunc) *Server {
+	return &Server{
+		state:    state,
+		interval: DefaultInterval,
+		done:     make(chan struct{}),
+	}
+}
+
+// SetEvents sets the bus served by the stream endpoint. Must be called before
+// the server starts.
+func (d *Server) SetEvents(bus *events.Bus) {
+	d.events = bus
+}
+
+// Handler returns the dashboard HTTP handler:
+//
+//	/            the web UI
+//	/api/state   the current state as JSON
+//	/api/events  the state as server-sent events, once per interval
+//	/api/stream  typed station events as server-sent events, if a bus is set
+func (d *Server) Handler() http.Handler {
+	static, err := fs.Sub(staticFiles, "static")
+	if err != nil {
+		panic(err)
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("GET /", http.FileServerFS(static))
+	mux.HandleFunc("GET /api/state", d.handleState)
+	mux.HandleFunc("GET /api/events", d.handleEvents)
+	if d.events != nil {
+		mux.HandleFunc("GET /api/stream", d.handleStream)
+	}
+	return mux
+}
+
+func (d *Server) handleState(w http.ResponseWriter, r *http.Request) {
+	data, err := json.Marshal(d.state())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.Write(data)
+}
+
+func (d *Server) handleEvents(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("X-Accel-Buffering", "no")
+
+	ticker := time.NewTicker(d.interval)
+	defer ticker.Stop()
+	for {
+		data, err := json.Marshal(d.state())
+		if err != nil {
+			return
+		}
+		if _, err := fmt.Fprintf(w, "event: state\ndata: %s\n\n", data); err != nil {
+			return
+		}
+		flusher.Flush()
+
+		select {
+		case <-r.Context().Done():
+			return
+		case <-d.done:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// handleStream sends bus events as they are published, optionally filtered by
+// the comma-separated types query parameter. Clients resuming with a
+// Last-Event-ID header get the events they missed, if still kept.
+func (d *Server) handleStream(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+	lastID, _ := strconv.ParseUint(r.Header.Get("Last-Event-ID"), 10, 64)
+	filter := events.ParseFilter(r.URL.Query().Get("types"))
+
+	sub := d.events.Subscribe(lastID)
+	defer sub.Close()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	keepAlive := time.NewTicker(keepAliveInterval)
+	defer keepAlive.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-d.done:
+			return
+		case <-keepAlive.C:
+			if _, err := fmt.Fprint(w, ": keep-alive\n\n"); err != nil {
+				return
+			}
+		case event, ok := <-sub.Events():
+			if !ok {
+				// Fell behind; the client reconnects and resumes from its last ID
+				return
+			}
+			if !filter.Match(event.Type) {
+				continue
+			}
+			data, err := json.Marshal(event)
+			if err != nil {
+				continue
+			}
+			if _, err := fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", event.ID, event.Type, data); err != nil {
+				return
+			}
+		}
+		flusher.Flush()
+	}
+}
+
+// StartServer starts the dashboard HTTP server in the background
+func (d *Server) StartServer(addr string, opts httpserver.Options) error {
+	server, err := httpserver.Start("Dashboard", addr, d.Handler(), opts)
+	if err != nil {
+		return err
+	}
+	d.server = server
+	return nil
+}
+
+// Shutdown ends open event streams and gracefully shuts down the server
+func (d *Server) Shutdown(ctx context.Context) error {
+	d.closeOnce.Do(func() { close(d.done) })
+	if d.server != nil {
+		return d.server.Shutdown(ctx)
+	}
+	return nil
+}
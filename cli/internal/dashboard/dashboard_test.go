@@ -0,0 +1,135 @@
+/*
+ * Copyright (c) 2026, Psiphon Inc.
+ * All rights reserved.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package dashboard
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Psiphon-Inc/conduit/cli/internal/events"
+)
+
+func TestHandler(t *testing.T) {
+	d := New(func() any { return map[string]int{"connectedClients": 7} })
+	d.interval = 10 * time.Millisecond
+	server := httptest.NewServer(d.Handler())
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/")
+	if err != nil {
+		t.Fatalf("GET /: %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || !strings.Contains(string(body), "app.js") {
+		t.Fatalf("GET / = %d, expected the dashboard page", resp.StatusCode)
+	}
+
+	resp, err = http.Get(server.URL + "/api/state")
+	if err != nil {
+		t.Fatalf("GET /api/state: %v", err)
+	}
+	var state map[string]int
+	err = json.NewDecoder(resp.Body).Decode(&state)
+	resp.Body.Close()
+	if err != nil || state["connectedClients"] != 7 {
+		t.Fatalf("GET /api/state = %v, %v", state, err)
+	}
+
+	// The event stream sends the state repeatedly until the server shuts down
+	resp, err = http.Get(server.URL + "/api/events")
+	if err != nil {
+		t.Fatalf("GET /api/events: %v", err)
+	}
+	defer resp.Body.Close()
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Fatalf("events Content-Type = %q", ct)
+	}
+	reader := bufio.NewReader(resp.Body)
+	for i := 0; i < 2; i++ {
+		event, _ := reader.ReadString('\n')
+		data, _ := reader.ReadString('\n')
+		reader.ReadString('\n')
+		if event != "event: state\n" || data != "data: {\"connectedClients\":7}\n" {
+			t.Fatalf("unexpected event %q %q", event, data)
+		}
+	}
+
+	d.Shutdown(context.Background())
+	if _, err := io.ReadAll(reader); err != nil {
+		t.Fatalf("event stream did not end cleanly after shutdown: %v", err)
+	}
+}
+
+func TestStream(t *testing.T) {
+	bus := events.NewBus(events.DefaultHistory)
+	d := New(func() any { return nil })
+	d.SetEvents(bus)
+	server := httptest.NewServer(d.Handler())
+	defer server.Close()
+	defer d.Shutdown(context.Background())
+
+	readEvent := func(reader *bufio.Reader) string {
+		var lines []string
+		for {
+			line, err := reader.ReadString('\n')
+			if err != nil {
+				t.Fatalf("reading stream: %v", err)
+			}
+			if line == "\n" {
+				return strings.Join(lines, "")
+			}
+			lines = append(lines, line)
+		}
+	}
+
+	// Only connection events are sent to a filtered stream
+	resp, err := http.Get(server.URL + "/api/stream?types=connection")
+	if err != nil {
+		t.Fatalf("GET /api/stream: %v", err)
+	}
+	defer resp.Body.Close()
+	bus.Publish(events.TypeBroker, events.Broker{Connected: true})
+	bus.Publish(events.TypeConnectionEstablished, events.Connection{CandidateType: "host", Country: "CA"})
+	event := readEvent(bufio.NewReader(resp.Body))
+	if !strings.HasPrefix(event, "id: 2\nevent: connection.established\ndata: ") ||
+		!strings.Contains(event, `"data":{"candidateType":"host","country":"CA"}`) {
+		t.Fatalf("unexpected event %q", event)
+	}
+
+	// Resuming replays the events missed since the last ID
+	req, _ := http.NewRequest("GET", server.URL+"/api/stream", nil)
+	req.Header.Set("Last-Event-ID", "1")
+	resumed, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("GET /api/stream: %v", err)
+	}
+	defer resumed.Body.Close()
+	if event := readEvent(bufio.NewReader(resumed.Body)); !strings.HasPrefix(event, "id: 2\n") {
+		t.Fatalf("resumed stream started with %q", event)
+	}
+}
@@ -0,0 +1,188 @@
+"use strict";
+
+// Keep five minutes of samples at the server's one second interval
+const HISTORY = 300;
+const samples = [];
+
+const $ = (id) => document.getElementById(id);
+
+function formatBytes(bytes) {
+  const units = ["B", "KB", "MB", "GB", "TB", "PB"];
+  let i = 0;
+  while (bytes >= 1024 && i < units.length - 1) {
+    bytes /= 1024;
+    i++;
+  }
+  return (i === 0 ? bytes : bytes.toFixed(1)) + " " + units[i];
+}
+
+function formatRate(bytesPerSecond) {
+  return formatBytes(bytesPerSecond) + "/s";
+}
+
+function formatDuration(seconds) {
+  const d = Math.floor(seconds / 86400);
+  const h = Math.floor((seconds % 86400) / 3600);
+  const m = Math.floor((seconds % 3600) / 60);
+  const s = Math.floor(seconds % 60);
+  if (d > 0) return `${d}d ${h}h`;
+  if (h > 0) return `${h}h ${m}m`;
+  if (m > 0) return `${m}m ${s}s`;
+  return `${s}s`;
+}
+
+function flag(code) {
+  if (!/^[A-Z]{2}$/.test(code)) return "";
+  return String.fromCodePoint(...[...code].map((c) => 0x1f1e6 + c.charCodeAt(0) - 65));
+}
+
+function addSample(stats) {
+  const now = Date.now() / 1000;
+  const last = samples[samples.length - 1];
+  let up = 0;
+  let down = 0;
+  if (last && now > last.time) {
+    // Totals reset when the service restarts
+    up = Math.max(0, stats.totalBytesUp - last.totalUp) / (now - last.time);
+    down = Math.max(0, stats.totalBytesDown - last.totalDown) / (now - last.time);
+  }
+  samples.push({
+    time: now,
+    totalUp: stats.totalBytesUp,
+    totalDown: stats.totalBytesDown,
+    up,
+    down,
+    connected: stats.connectedClients,
+    connecting: stats.connectingClients,
+  });
+  if (samples.length > HISTORY) samples.shift();
+  return { up, down };
+}
+
+function drawChart(canvas, series) {
+  const ratio = window.devicePixelRatio || 1;
+  const width = canvas.clientWidth * ratio;
+  const height = canvas.clientHeight * ratio;
+  canvas.width = width;
+  canvas.height = height;
+  const ctx = canvas.getContext("2d");
+  ctx.clearRect(0, 0, width, height);
+
+  let max = 1;
+  for (const s of series) for (const v of s.values) max = Math.max(max, v);
+
+  const pad = 6 * ratio;
+  const step = (width - 2 * pad) / (HISTORY - 1);
+  for (const s of series) {
+    ctx.strokeStyle = s.color;
+    ctx.lineWidth = 2 * ratio;
+    ctx.beginPath();
+    const offset = HISTORY - s.values.length;
+    s.values.forEach((v, i) => {
+      const x = pad + (offset + i) * step;
+      const y = height - pad - (v / max) * (height - 2 * pad);
+      i === 0 ? ctx.moveTo(x, y) : ctx.lineTo(x, y);
+    });
+    ctx.stroke();
+  }
+
+  ctx.fillStyle = "#a99bb8";
+  ctx.font = `${11 * ratio}px system-ui, sans-serif`;
+  ctx.fillText(series[0].format(max), pad, pad + 10 * ratio);
+}
+
+function renderGeo(geo) {
+  $("geo-section").hidden = !geo || geo.length === 0;
+  if (!geo) return;
+  const rows = [...geo].sort((a, b) => b.count - a.count || b.count_total - a.count_total);
+  const maxTotal = Math.max(1, ...rows.map((r) => r.count_total));
+  $("geo").replaceChildren(
+    ...rows.map((r) => {
+      const tr = document.createElement("tr");
+      const cells = [
+        `${flag(r.code)} ${r.country}`,
+        r.count,
+        r.count_total,
+        `${formatBytes(r.bytes_up)} ↑ ${formatBytes(r.bytes_down)} ↓`,
+      ];
+      for (const c of cells) {
+        const td = document.createElement("td");
+        td.textContent = c;
+        tr.append(td);
+      }
+      const bar = document.createElement("td");
+      bar.className = "bar";
+      const fill = document.createElement("div");
+      fill.style.width = `${(100 * r.count_total) / maxTotal}%`;
+      bar.append(fill);
+      tr.append(bar);
+      return tr;
+    }),
+  );
+}
+
+function renderConfig(config) {
+  const bandwidth = config.bandwidthBytesPerSecond
+    ? `${((config.bandwidthBytesPerSecond * 8) / 1e6).toFixed(1)} Mbps`
+    : "unlimited";
+  const rows = [
+    ["Max clients", config.maxClients],
+    ["Bandwidth limit", bandwidth],
+    ["Idle restart", config.idleRestartSeconds ? formatDuration(config.idleRestartSeconds) : "off"],
+    ["Geo tracking", config.geoEnabled ? "on" : "off"],
+    ["Metrics", config.metricsEnabled ? "on" : "off"],
+  ];
+  $("config").replaceChildren(
+    ...rows.map(([k, v]) => {
+      const tr = document.createElement("tr");
+      const th = document.createElement("th");
+      th.textContent = k;
+      const td = document.createElement("td");
+      td.textContent = v;
+      tr.append(th, td);
+      return tr;
+    }),
+  );
+}
+
+function render(state) {
+  const stats = state.stats;
+  const rate = addSample(stats);
+
+  $("station-name").textContent = stats.stationName || "";
+  $("proxy-id").textContent = stats.proxyId ? `Proxy ID ${stats.proxyId}` : "";
+  const broker = $("broker");
+  broker.textContent = stats.isLive ? "Live" : "Connecting to broker…";
+  broker.classList.toggle("live", stats.isLive);
+
+  $("connected").textContent = stats.connectedClients;
+  $("connecting").textContent = stats.connectingClients;
+  $("rate-up").textContent = formatRate(rate.up);
+  $("rate-down").textContent = formatRate(rate.down);
+  $("total-up").textContent = `${formatBytes(stats.totalBytesUp)} total`;
+  $("total-down").textContent = `${formatBytes(stats.totalBytesDown)} total`;
+  $("uptime").textContent = formatDuration(stats.uptimeSeconds);
+  $("idle").textContent = stats.idleSeconds > 0 ? `idle ${formatDuration(stats.idleSeconds)}` : "active";
+
+  drawChart($("throughput"), [
+    { color: "#f5a086", values: samples.map((s) => s.up), format: formatRate },
+    { color: "#9d81c9", values: samples.map((s) => s.down), format: formatRate },
+  ]);
+  drawChart($("clients"), [
+    { color: "#f5a086", values: samples.map((s) => s.connected), format: (v) => `${v} clients` },
+    { color: "#9d81c9", values: samples.map((s) => s.connecting), format: (v) => `${v} clients` },
+  ]);
+  renderGeo(stats.geo);
+  renderConfig(state.config);
+  $("updated").textContent = `Updated ${new Date(stats.timestamp).toLocaleTimeString()}`;
+}
+
+function connect() {
+  const events = new EventSource("api/events");
+  events.addEventListener("state", (e) => render(JSON.parse(e.data)));
+  events.onerror = () => {
+    $("updated").textContent = "Disconnected, retrying…";
+  };
+}
+
+connect();
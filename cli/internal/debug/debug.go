@@ -0,0 +1,212 @@
+/*
+ * Copyright (c) 2026, Psiphon Inc.
+ * All rights reserved.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+// Package debug serves runtime diagnostics (pprof, goroutine dumps, recent
+// notices) and collects them into tarballs for bug reports
+package debug
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/pprof"
+	"runtime"
+	runtimepprof "runtime/pprof"
+	"time"
+
+	"github.com/Psiphon-Inc/conduit/cli/internal/httpserver"
+)
+
+// DefaultAddr is the debug server address when --debug-addr is given without one
+const DefaultAddr = "127.0.0.1:6060"
+
+// Sources provides the station state included in diagnostics
+type Sources struct {
+	Version string
+	Started time.Time
+	Stats   func() (any, bool) // Current stats, or false while no service is running
+	Config  any                // Configuration, without secrets
+	Notices *Notices
+}
+
+// Handler serves pprof under /debug/pprof/, plus /debug/stats,
+// /debug/config, /debug/notices and /debug/dump. The pprof cmdline endpoint
+// is left out, since the command line may hold credentials in URLs.
+func Handler(src Sources) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+
+	mux.HandleFunc("/debug/stats", func(w http.ResponseWriter, r *http.Request) {
+		stats, ok := src.stats()
+		if !ok {
+			http.Error(w, "Service not running", http.StatusServiceUnavailable)
+			return
+		}
+		writeJSON(w, stats)
+	})
+	mux.HandleFunc("/debug/config", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, src.Config)
+	})
+	mux.HandleFunc("/debug/notices", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		for _, line := range src.Notices.Recent() {
+			w.Write(append(line, '\n'))
+		}
+	})
+	mux.HandleFunc("/debug/dump", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/gzip")
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", DumpName(time.Now())))
+		if err := WriteDump(w, src); err != nil {
+			fmt.Printf("[ERROR] Failed to write debug dump: %v\n", err)
+		}
+	})
+	return mux
+}
+
+func (src Sources) stats() (any, bool) {
+	if src.Stats == nil {
+		return nil, false
+	}
+	return src.Stats()
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.Encode(v)
+}
+
+// Start serves diagnostics on addr in the background, protected by opts
+func Start(addr string, src Sources, opts httpserver.Options) (*httpserver.Server, error) {
+	return httpserver.Start("Debug", addr, Handler(src), opts)
+}
+
+// DumpName is the file name of a dump taken at t
+func DumpName(t time.Time) string {
+	return "conduit-debug-" + t.UTC().Format("20060102-150405") + ".tar.gz"
+}
+
+// runtimeInfo summarizes the Go runtime in a dump
+type runtimeInfo struct {
+	Version       string  `json:"version"`
+	GoVersion     string  `json:"goVersion"`
+	OS            string  `json:"os"`
+	Arch          string  `json:"arch"`
+	CPUs          int     `json:"cpus"`
+	GOMAXPROCS    int     `json:"gomaxprocs"`
+	Goroutines    int     `json:"goroutines"`
+	UptimeSeconds int64   `json:"uptimeSeconds"`
+	HeapAlloc     uint64  `json:"heapAllocBytes"`
+	HeapInuse     uint64  `json:"heapInuseBytes"`
+	Sys           uint64  `json:"sysBytes"`
+	NumGC         uint32  `json:"numGC"`
+	GCPauseTotal  float64 `json:"gcPauseTotalSeconds"`
+}
+
+// dumpFile is a file in a dump
+type dumpFile struct {
+	name  string
+	write func(w io.Writer) error
+}
+
+// WriteDump writes a gzipped tarball of heap and goroutine profiles,
+// goroutine stacks, runtime info, current stats, configuration and recent
+// notices
+func WriteDump(w io.Writer, src Sources) error {
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+	now := time.Now()
+	add := func(name string, write func(w io.Writer) error) error {
+		var buf bytes.Buffer
+		if err := write(&buf); err != nil {
+			return fmt.Errorf("failed to collect %s: %w", name, err)
+		}
+		if err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: int64(buf.Len()), ModTime: now}); err != nil {
+			return err
+		}
+		_, err := tw.Write(buf.Bytes())
+		return err
+	}
+	jsonFile := func(v any) func(w io.Writer) error {
+		return func(w io.Writer) error {
+			enc := json.NewEncoder(w)
+			enc.SetIndent("", "  ")
+			return enc.Encode(v)
+		}
+	}
+
+	// Collect garbage first, so the heap profile is current
+	runtime.GC()
+	var mem runtime.MemStats
+	runtime.ReadMemStats(&mem)
+	info := runtimeInfo{
+		Version:      src.Version,
+		GoVersion:    runtime.Version(),
+		OS:           runtime.GOOS,
+		Arch:         runtime.GOARCH,
+		CPUs:         runtime.NumCPU(),
+		GOMAXPROCS:   runtime.GOMAXPROCS(0),
+		Goroutines:   runtime.NumGoroutine(),
+		HeapAlloc:    mem.HeapAlloc,
+		HeapInuse:    mem.HeapInuse,
+		Sys:          mem.Sys,
+		NumGC:        mem.NumGC,
+		GCPauseTotal: time.Duration(mem.PauseTotalNs).Seconds(),
+	}
+	if !src.Started.IsZero() {
+		info.UptimeSeconds = int64(now.Sub(src.Started).Seconds())
+	}
+
+	files := []dumpFile{
+		{"runtime.json", jsonFile(info)},
+		{"heap.pprof", func(w io.Writer) error { return runtimepprof.Lookup("heap").WriteTo(w, 0) }},
+		{"goroutine.pprof", func(w io.Writer) error { return runtimepprof.Lookup("goroutine").WriteTo(w, 0) }},
+		{"goroutines.txt", func(w io.Writer) error { return runtimepprof.Lookup("goroutine").WriteTo(w, 2) }},
+		{"config.json", jsonFile(src.Config)},
+		{"notices.jsonl", func(w io.Writer) error {
+			for _, line := range src.Notices.Recent() {
+				if _, err := w.Write(append(line, '\n')); err != nil {
+					return err
+				}
+			}
+			return nil
+		}},
+	}
+	if stats, ok := src.stats(); ok {
+		files = append(files, dumpFile{"stats.json", jsonFile(stats)})
+	}
+	for _, f := range files {
+		if err := add(f.name, f.write); err != nil {
+			return err
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	return gz.Close()
+}
@@ -0,0 +1,196 @@
+/*
+ * Copyright (c) 2026, Psiphon Inc.
+ * All rights reserved.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package debug
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRedactAddresses(t *testing.T) {
+	tests := []struct {
+		in, expected string
+	}{
+		{`{"peer":"203.0.113.7"}`, `{"peer":"[redacted]"}`},
+		{`dial 203.0.113.7:443 failed`, `dial [redacted] failed`},
+		{`peer [2001:db8::1]:443`, `peer [redacted]`},
+		{`candidate fe80::1 host`, `candidate [redacted] host`},
+		{`"timestamp":"2026-10-18T12:34:56.789Z"`, `"timestamp":"2026-10-18T12:34:56.789Z"`},
+		{`version 1.2.3`, `version 1.2.3`},
+		{`id deadbeef`, `id deadbeef`},
+	}
+	for _, tt := range tests {
+		if got := string(RedactAddresses([]byte(tt.in))); got != tt.expected {
+			t.Fatalf("RedactAddresses(%q) = %q, expected %q", tt.in, got, tt.expected)
+		}
+	}
+}
+
+func TestNotices(t *testing.T) {
+	var none *Notices
+	none.Add([]byte("ignored"))
+	if none.Recent() != nil {
+		t.Fatalf("nil buffer returned notices")
+	}
+
+	n := NewNotices(3)
+	n.Add([]byte(`{"noticeType":"InproxyProxyActivity"}`))
+	if len(n.Recent()) != 0 {
+		t.Fatalf("activity notice was kept")
+	}
+	for i := 1; i <= 5; i++ {
+		n.Add([]byte(fmt.Sprintf(`{"noticeType":"Info","n":%d}`+"\n", i)))
+	}
+	var got []string
+	for _, line := range n.Recent() {
+		got = append(got, string(line))
+	}
+	expected := `{"noticeType":"Info","n":3}|{"noticeType":"Info","n":4}|{"noticeType":"Info","n":5}`
+	if strings.Join(got, "|") != expected {
+		t.Fatalf("Recent() = %v, expected %s", got, expected)
+	}
+}
+
+func TestHandler(t *testing.T) {
+	notices := NewNotices(10)
+	notices.Add([]byte(`{"noticeType":"Warning","data":{"message":"dial 198.51.100.1:443 failed"}}`))
+	var running atomic.Bool
+	server := httptest.NewServer(Handler(Sources{
+		Version: "1.2.3",
+		Started: time.Now().Add(-time.Minute),
+		Stats: func() (any, bool) {
+			return map[string]int{"connectedClients": 7}, running.Load()
+		},
+		Config:  map[string]string{"stationName": "berlin"},
+		Notices: notices,
+	}))
+	defer server.Close()
+
+	get := func(path string) (*http.Response, string) {
+		resp, err := http.Get(server.URL + path)
+		if err != nil {
+			t.Fatalf("GET %s: %v", path, err)
+		}
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		return resp, string(body)
+	}
+
+	if resp, _ := get("/debug/stats"); resp.StatusCode != http.StatusServiceUnavailable {
+		t.Fatalf("stats while stopped: %s", resp.Status)
+	}
+	running.Store(true)
+	if _, body := get("/debug/stats"); !strings.Contains(body, `"connectedClients": 7`) {
+		t.Fatalf("stats = %s", body)
+	}
+	if _, body := get("/debug/pprof/goroutine?debug=2"); !strings.Contains(body, "goroutine ") {
+		t.Fatalf("goroutine dump = %.200s", body)
+	}
+	// The command line may hold credentials, so it's not served
+	if resp, _ := get("/debug/pprof/cmdline"); resp.StatusCode != http.StatusNotFound {
+		t.Fatalf("cmdline: %s", resp.Status)
+	}
+	if _, body := get("/debug/notices"); strings.Contains(body, "198.51.100.1") || !strings.Contains(body, "[redacted]") {
+		t.Fatalf("notices = %s", body)
+	}
+
+	resp, err := http.Get(server.URL + "/debug/dump")
+	if err != nil {
+		t.Fatalf("GET /debug/dump: %v", err)
+	}
+	defer resp.Body.Close()
+	gz, err := gzip.NewReader(resp.Body)
+	if err != nil {
+		t.Fatalf("gzip: %v", err)
+	}
+	files := map[string]string{}
+	tr := tar.NewReader(gz)
+	for {
+		h, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("tar: %v", err)
+		}
+		data, _ := io.ReadAll(tr)
+		files[h.Name] = string(data)
+	}
+
+	var names []string
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	expected := "config.json goroutine.pprof goroutines.txt heap.pprof notices.jsonl runtime.json stats.json"
+	if strings.Join(names, " ") != expected {
+		t.Fatalf("dump contains %v, expected %s", names, expected)
+	}
+	if !strings.Contains(files["runtime.json"], `"version": "1.2.3"`) || !strings.Contains(files["config.json"], "berlin") {
+		t.Fatalf("unexpected runtime or config: %s %s", files["runtime.json"], files["config.json"])
+	}
+	if len(files["heap.pprof"]) == 0 {
+		t.Fatalf("empty heap profile")
+	}
+}
+
+func TestClient(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+		io.WriteString(w, "ok")
+	}))
+	defer server.Close()
+	ca := filepath.Join(t.TempDir(), "debug_tls.crt")
+	os.WriteFile(ca, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw}), 0644)
+	addr := strings.TrimPrefix(server.URL, "https://")
+
+	resp, err := Client{Addr: addr, Token: "secret", CA: ca}.Get(context.Background(), "/debug/config")
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if string(body) != "ok" {
+		t.Fatalf("body = %q", body)
+	}
+
+	if _, err := (Client{Addr: addr, CA: ca}).Get(context.Background(), "/debug/config"); err == nil || !strings.Contains(err.Error(), "401") {
+		t.Fatalf("Get without token = %v", err)
+	}
+	if _, err := (Client{Addr: addr, Token: "secret"}).Get(context.Background(), "/debug/config"); err == nil {
+		t.Fatalf("Get over plain HTTP succeeded against a TLS server")
+	}
+}
@@ -0,0 +1,94 @@
+/*
+ * Copyright (c) 2026, Psiphon Inc.
+ * All rights reserved.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package debug
+
+import (
+	"bytes"
+	"net/netip"
+	"regexp"
+	"sync"
+)
+
+// DefaultNotices is how many recent notices are kept for debug dumps
+const DefaultNotices = 500
+
+// addressCandidate matches text that may be an IP address, or an address and port
+var addressCandidate = regexp.MustCompile(`\[?[0-9A-Fa-f:.]{3,}\]?(:[0-9]+)?`)
+
+// Notices keeps the most recent psiphon notices, with IP addresses redacted.
+// Frequent activity notices are skipped, since the stats cover them.
+type Notices struct {
+	mu     sync.Mutex
+	lines  [][]byte
+	next   int
+	filled bool
+}
+
+// NewNotices creates a buffer of the last size notices
+func NewNotices(size int) *Notices {
+	return &Notices{lines: make([][]byte, size)}
+}
+
+// Add records a notice. Safe to call on a nil buffer.
+func (n *Notices) Add(notice []byte) {
+	if n == nil || len(n.lines) == 0 {
+		return
+	}
+	if bytes.Contains(notice, []byte(`"noticeType":"InproxyProxyActivity"`)) ||
+		bytes.Contains(notice, []byte(`"noticeType":"InproxyProxyTotalActivity"`)) {
+		return
+	}
+	line := RedactAddresses(bytes.TrimRight(notice, "\n"))
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.lines[n.next] = line
+	n.next = (n.next + 1) % len(n.lines)
+	if n.next == 0 {
+		n.filled = true
+	}
+}
+
+// Recent returns the kept notices, oldest first
+func (n *Notices) Recent() [][]byte {
+	if n == nil {
+		return nil
+	}
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if !n.filled {
+		return append([][]byte(nil), n.lines[:n.next]...)
+	}
+	return append(append([][]byte(nil), n.lines[n.next:]...), n.lines[:n.next]...)
+}
+
+// RedactAddresses replaces IPv4 and IPv6 addresses (with or without a port)
+func RedactAddresses(b []byte) []byte {
+	return addressCandidate.ReplaceAllFunc(b, func(match []byte) []byte {
+		s := string(bytes.Trim(match, "[]"))
+		if _, err := netip.ParseAddr(s); err == nil {
+			return []byte("[redacted]")
+		}
+		if _, err := netip.ParseAddrPort(string(match)); err == nil {
+			return []byte("[redacted]")
+		}
+		return match
+	})
+}
@@ -0,0 +1,414 @@
+/*
+ * Copyright (c) 2026, Psiphon Inc.
+ * All rights reserved.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+// Package doctor diagnoses why a station might not get clients: bad config,
+// data dir or key problems, blocked DNS, HTTPS or UDP, NAT type and clock skew
+package doctor
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/Psiphon-Inc/conduit/cli/internal/config"
+	"github.com/Psiphon-Labs/psiphon-tunnel-core/psiphon"
+)
+
+// Status is the outcome of a check
+type Status string
+
+const (
+	StatusOK   Status = "ok"
+	StatusWarn Status = "warn"
+	StatusFail Status = "fail"
+	StatusSkip Status = "skip"
+)
+
+// Default probe targets for outbound HTTPS, clock skew, and UDP and NAT type.
+// The broker is probed at the fronting addresses of the Psiphon config.
+var (
+	DefaultHTTPSTargets = []string{"https://psiphon.ca/", "https://www.cloudflare.com/"}
+	DefaultSTUNServers  = []string{"stun.l.google.com:19302", "stun.cloudflare.com:3478"}
+)
+
+// Clock skew thresholds
+const (
+	clockWarnSkew = time.Minute
+	clockFailSkew = 10 * time.Minute
+)
+
+// Result is the outcome of one check
+type Result struct {
+	Name    string `json:"name"`
+	Status  Status `json:"status"`
+	Message string `json:"message"`
+	Fix     string `json:"fix,omitempty"` // what to do about a warning or failure
+}
+
+// Options configures the checks
+type Options struct {
+	DataDir       string
+	PsiphonConfig []byte
+	ConfigError   error // why the Psiphon config couldn't be read
+	KeyOptions    config.KeyOptions
+	HTTPSTargets  []string
+	STUNServers   []string
+}
+
+// Probes performs the system and network operations of the checks, so they
+// can be replaced in tests
+type Probes struct {
+	LoadPsiphonConfig func(data []byte) error
+	VerifyKey         func(dataDir string, keyOpts config.KeyOptions) error
+	LookupHost        func(ctx context.Context, host string) ([]string, error)
+	HTTPS             func(ctx context.Context, url string) (serverTime time.Time, err error)
+	Dial              func(ctx context.Context, address string) error
+	STUN              func(ctx context.Context, servers []string) (STUNResult, error)
+	Now               func() time.Time
+}
+
+// DefaultProbes uses tunnel-core, the filesystem and the network
+func DefaultProbes() Probes {
+	client := &http.Client{
+		Timeout: 10 * time.Second,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+	return Probes{
+		LoadPsiphonConfig: func(data []byte) error {
+			_, err := psiphon.LoadConfig(data)
+			return err
+		},
+		VerifyKey: func(dataDir string, keyOpts config.KeyOptions) error {
+			_, err := config.VerifyKey(dataDir, keyOpts)
+			return err
+		},
+		LookupHost: net.DefaultResolver.LookupHost,
+		HTTPS: func(ctx context.Context, url string) (time.Time, error) {
+			req, err := http.NewRequestWithContext(ctx, http.MethodHead, url, nil)
+			if err != nil {
+				return time.Time{}, err
+			}
+			resp, err := client.Do(req)
+			if err != nil {
+				return time.Time{}, err
+			}
+			resp.Body.Close()
+			date, _ := http.ParseTime(resp.Header.Get("Date"))
+			return date, nil
+		},
+		Dial: func(ctx context.Context, address string) error {
+			dialer := net.Dialer{Timeout: 10 * time.Second}
+			conn, err := dialer.DialContext(ctx, "tcp", address)
+			if err != nil {
+				return err
+			}
+			return conn.Close()
+		},
+		STUN: ProbeSTUN,
+		Now:  time.Now,
+	}
+}
+
+// Run runs every check in order
+func Run(ctx context.Context, opts Options, probes Probes) []Result {
+	if opts.HTTPSTargets == nil {
+		opts.HTTPSTargets = DefaultHTTPSTargets
+	}
+	if opts.STUNServers == nil {
+		opts.STUNServers = DefaultSTUNServers
+	}
+
+	https, serverTime := checkHTTPS(ctx, opts, probes)
+	return []Result{
+		checkPsiphonConfig(opts, probes),
+		checkDataDir(opts),
+		checkKey(opts, probes),
+		checkDNS(ctx, opts, probes),
+		https,
+		checkBroker(ctx, opts, probes),
+		checkClock(serverTime, probes),
+		checkSTUN(ctx, opts, probes),
+	}
+}
+
+func checkPsiphonConfig(opts Options, probes Probes) Result {
+	r := Result{Name: "Psiphon config"}
+	switch {
+	case opts.ConfigError != nil:
+		return fail(r, opts.ConfigError.Error(), "use --psiphon-config, or an official release with an embedded config")
+	case len(opts.PsiphonConfig) == 0:
+		return fail(r, "no Psiphon config", "use --psiphon-config, or an official release with an embedded config")
+	}
+	for _, issue := range config.ValidatePsiphonConfig(opts.PsiphonConfig, nil) {
+		if issue.Severity == config.SeverityError {
+			return fail(r, strings.TrimSpace(issue.Key+" "+issue.Message), "use the config provided by Psiphon; 'conduit config validate' lists all problems")
+		}
+	}
+	if err := probes.LoadPsiphonConfig(opts.PsiphonConfig); err != nil {
+		return fail(r, fmt.Sprintf("tunnel-core rejects the config: %v", err), "use the config provided by Psiphon")
+	}
+	r.Status, r.Message = StatusOK, "valid"
+	return r
+}
+
+func checkDataDir(opts Options) Result {
+	r := Result{Name: "Data directory"}
+	info, err := os.Stat(opts.DataDir)
+	if errors.Is(err, os.ErrNotExist) {
+		return warn(r, fmt.Sprintf("%s doesn't exist yet", opts.DataDir), "it is created on first start; check --data-dir if you expected an existing station")
+	}
+	if err != nil {
+		return fail(r, err.Error(), "check --data-dir")
+	}
+	if !info.IsDir() {
+		return fail(r, fmt.Sprintf("%s is not a directory", opts.DataDir), "point --data-dir at a directory")
+	}
+	probe, err := os.CreateTemp(opts.DataDir, ".doctor-")
+	if err != nil {
+		return fail(r, fmt.Sprintf("%s is not writable: %v", opts.DataDir, err), "fix the owner or permissions, e.g. chown to the user running conduit")
+	}
+	probe.Close()
+	os.Remove(probe.Name())
+	if info.Mode().Perm()&0002 != 0 {
+		return warn(r, fmt.Sprintf("%s is writable by all users (mode %04o)", opts.DataDir, info.Mode().Perm()), "chmod 700 "+opts.DataDir)
+	}
+	r.Status, r.Message = StatusOK, opts.DataDir+" is writable"
+	return r
+}
+
+func checkKey(opts Options, probes Probes) Result {
+	r := Result{Name: "Station key"}
+	if _, err := os.Stat(config.KeyFilePath(opts.DataDir)); errors.Is(err, os.ErrNotExist) {
+		return warn(r, "no key yet", "a key is generated on first start; restore a backup with 'conduit key import' to keep a station's reputation")
+	}
+	if err := probes.VerifyKey(opts.DataDir, opts.KeyOptions); err != nil {
+		return fail(r, err.Error(), "see 'conduit key verify'; restore the key from its mnemonic with 'conduit key import' if it is corrupt")
+	}
+	r.Status, r.Message = StatusOK, "valid"
+	return r
+}
+
+func checkDNS(ctx context.Context, opts Options, probes Probes) Result {
+	r := Result{Name: "DNS"}
+	var hosts, failed []string
+	for _, target := range opts.HTTPSTargets {
+		if u, err := url.Parse(target); err == nil && u.Hostname() != "" {
+			hosts = append(hosts, u.Hostname())
+		}
+	}
+	for _, server := range opts.STUNServers {
+		if host, _, err := net.SplitHostPort(server); err == nil {
+			hosts = append(hosts, host)
+		}
+	}
+	for _, host := range hosts {
+		ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+		_, err := probes.LookupHost(ctx, host)
+		cancel()
+		if err != nil {
+			failed = append(failed, host)
+		}
+	}
+	switch {
+	case len(hosts) == 0:
+		r.Status, r.Message = StatusSkip, "no hosts to resolve"
+	case len(failed) == len(hosts):
+		return fail(r, "no names resolve", "check /etc/resolv.conf or the container's DNS settings")
+	case len(failed) > 0:
+		return warn(r, "can't resolve "+strings.Join(failed, ", "), "the resolver may be filtering names; try another DNS server")
+	default:
+		r.Status, r.Message = StatusOK, fmt.Sprintf("resolved %d names", len(hosts))
+	}
+	return r
+}
+
+func checkHTTPS(ctx context.Context, opts Options, probes Probes) (Result, time.Time) {
+	r := Result{Name: "Outbound HTTPS"}
+	var serverTime time.Time
+	var failed []string
+	for _, target := range opts.HTTPSTargets {
+		date, err := probes.HTTPS(ctx, target)
+		if err != nil {
+			failed = append(failed, fmt.Sprintf("%s (%v)", target, err))
+			continue
+		}
+		if serverTime.IsZero() {
+			serverTime = date
+		}
+	}
+	switch {
+	case len(opts.HTTPSTargets) == 0:
+		r.Status, r.Message = StatusSkip, "no targets"
+	case len(failed) == len(opts.HTTPSTargets):
+		r = fail(r, "no HTTPS site is reachable: "+strings.Join(failed, "; "), "check the firewall and proxy settings for outbound HTTPS")
+	case len(failed) > 0:
+		r = warn(r, "unreachable: "+strings.Join(failed, "; "), "outbound HTTPS may be filtered")
+	default:
+		r.Status, r.Message = StatusOK, fmt.Sprintf("%d sites reachable", len(opts.HTTPSTargets))
+	}
+	return r, serverTime
+}
+
+func checkBroker(ctx context.Context, opts Options, probes Probes) Result {
+	r := Result{Name: "Broker reachability"}
+	addresses, encrypted := brokerAddresses(opts.PsiphonConfig)
+	if len(addresses) == 0 {
+		r.Status, r.Message = StatusSkip, "no broker addresses in the Psiphon config"
+		if encrypted {
+			r.Message = "broker specs are encrypted in AdditionalParameters, only tunnel-core can read them"
+		}
+		return r
+	}
+	var failed []string
+	for _, address := range addresses {
+		if err := probes.Dial(ctx, address); err != nil {
+			failed = append(failed, fmt.Sprintf("%s (%v)", address, err))
+		}
+	}
+	switch {
+	case len(failed) == len(addresses):
+		return fail(r, "no broker address is reachable: "+strings.Join(failed, "; "), "the station can't announce to the broker; check the firewall and proxy settings for outbound HTTPS")
+	case len(failed) > 0:
+		return warn(r, "unreachable: "+strings.Join(failed, "; "), "some broker fronts may be blocked; the station uses the reachable ones")
+	}
+	r.Status, r.Message = StatusOK, fmt.Sprintf("%d broker addresses reachable", len(addresses))
+	return r
+}
+
+// brokerAddresses returns the fronting dial addresses of the plaintext broker
+// specs, as host:port. It also reports whether there are AdditionalParameters,
+// which may hold broker specs but are encrypted.
+func brokerAddresses(data []byte) (addresses []string, encrypted bool) {
+	type brokerSpecs []struct {
+		BrokerFrontingSpecs []struct {
+			Addresses []string
+		}
+	}
+	var config struct {
+		AdditionalParameters    string
+		InproxyBrokerSpecs      brokerSpecs
+		InproxyProxyBrokerSpecs brokerSpecs
+	}
+	if json.Unmarshal(data, &config) != nil {
+		return nil, false
+	}
+
+	seen := map[string]bool{}
+	for _, specs := range []brokerSpecs{config.InproxyProxyBrokerSpecs, config.InproxyBrokerSpecs} {
+		for _, spec := range specs {
+			for _, fronting := range spec.BrokerFrontingSpecs {
+				for _, address := range fronting.Addresses {
+					// Addresses may be regen patterns, which can't be dialed as is
+					if address == "" || strings.ContainsAny(address, `[]()*+?{}|^$\`) {
+						continue
+					}
+					if _, _, err := net.SplitHostPort(address); err != nil {
+						address = net.JoinHostPort(address, "443")
+					}
+					if !seen[address] {
+						seen[address] = true
+						addresses = append(addresses, address)
+					}
+				}
+			}
+		}
+	}
+	return addresses, config.AdditionalParameters != ""
+}
+
+func checkClock(serverTime time.Time, probes Probes) Result {
+	r := Result{Name: "Clock"}
+	if serverTime.IsZero() {
+		r.Status, r.Message = StatusSkip, "no server time to compare with"
+		return r
+	}
+	skew := probes.Now().Sub(serverTime)
+	if skew < 0 {
+		skew = -skew
+	}
+	message := fmt.Sprintf("off by %s", skew.Round(time.Second))
+	switch {
+	case skew > clockFailSkew:
+		return fail(r, message, "broker connections fail with a wrong clock; enable NTP (e.g. timedatectl set-ntp true)")
+	case skew > clockWarnSkew:
+		return warn(r, message, "enable NTP (e.g. timedatectl set-ntp true)")
+	}
+	r.Status, r.Message = StatusOK, "in sync"
+	return r
+}
+
+func checkSTUN(ctx context.Context, opts Options, probes Probes) Result {
+	r := Result{Name: "UDP and NAT"}
+	if len(opts.STUNServers) == 0 {
+		r.Status, r.Message = StatusSkip, "no STUN servers"
+		return r
+	}
+	result, err := probes.STUN(ctx, opts.STUNServers)
+	if err != nil {
+		return fail(r, err.Error(), "")
+	}
+	if len(result.Mapped) == 0 {
+		return fail(r, "no STUN server answered: outbound UDP looks blocked", "clients connect with WebRTC over UDP; allow outbound UDP in the firewall")
+	}
+
+	var first string
+	symmetric := false
+	for _, server := range opts.STUNServers {
+		mapped, ok := result.Mapped[server]
+		if !ok {
+			continue
+		}
+		if first == "" {
+			first = mapped.String()
+		} else if mapped.String() != first {
+			symmetric = true
+		}
+	}
+	switch {
+	case result.Local.IsValid() && result.Mapped[opts.STUNServers[0]] == result.Local:
+		r.Status, r.Message = StatusOK, "UDP works, public address without NAT"
+	case symmetric:
+		return warn(r, "UDP works, but the NAT maps each destination to a different port (symmetric NAT)",
+			"many clients won't be able to connect; enable UPnP or a port forward on the router, or run on a host with a public address")
+	case len(result.Mapped) == 1:
+		r.Status, r.Message = StatusOK, "UDP works (NAT type unknown: only one STUN server answered)"
+	default:
+		r.Status, r.Message = StatusOK, "UDP works, NAT keeps the same public port for all destinations"
+	}
+	return r
+}
+
+func fail(r Result, message, fix string) Result {
+	r.Status, r.Message, r.Fix = StatusFail, message, fix
+	return r
+}
+
+func warn(r Result, message, fix string) Result {
+	r.Status, r.Message, r.Fix = StatusWarn, message, fix
+	return r
+}
@@ -0,0 +1,188 @@
+/*
+ * Copyright (c) 2026, Psiphon Inc.
+ * All rights reserved.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package doctor
+
+import (
+	"context"
+	"errors"
+	"net/netip"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Psiphon-Inc/conduit/cli/internal/config"
+)
+
+const validConfig = `{"PropagationChannelId":"0123456789ABCDEF","SponsorId":"0123456789ABCDEF","AdditionalParameters":"abc",
+"InproxyBrokerSpecs":[{"BrokerFrontingSpecs":[{"Addresses":["front1.example","front[0-9].example"]},{"Addresses":["front2.example:8443"]}]}]}`
+
+var now = time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+
+// healthyProbes simulate a station with working network and a cone NAT
+func healthyProbes() Probes {
+	return Probes{
+		LoadPsiphonConfig: func([]byte) error { return nil },
+		VerifyKey:         func(string, config.KeyOptions) error { return nil },
+		LookupHost: func(ctx context.Context, host string) ([]string, error) {
+			return []string{"192.0.2.1"}, nil
+		},
+		HTTPS: func(ctx context.Context, url string) (time.Time, error) {
+			return now, nil
+		},
+		Dial: func(ctx context.Context, address string) error {
+			return nil
+		},
+		STUN: func(ctx context.Context, servers []string) (STUNResult, error) {
+			result := STUNResult{Local: netip.MustParseAddrPort("10.0.0.2:5000"), Mapped: map[string]netip.AddrPort{}}
+			for _, s := range servers {
+				result.Mapped[s] = netip.MustParseAddrPort("203.0.113.1:6000")
+			}
+			return result, nil
+		},
+		Now: func() time.Time { return now },
+	}
+}
+
+func testOptions(t *testing.T) Options {
+	dataDir := t.TempDir()
+	os.Chmod(dataDir, 0700)
+	if err := os.WriteFile(config.KeyFilePath(dataDir), []byte("{}"), 0600); err != nil {
+		t.Fatalf("write key: %v", err)
+	}
+	return Options{
+		DataDir:       dataDir,
+		PsiphonConfig: []byte(validConfig),
+		HTTPSTargets:  []string{"https://a.example/", "https://b.example/"},
+		STUNServers:   []string{"stun1.example:3478", "stun2.example:3478"},
+	}
+}
+
+func statuses(results []Result) map[string]Status {
+	m := map[string]Status{}
+	for _, r := range results {
+		m[r.Name] = r.Status
+	}
+	return m
+}
+
+func TestRun(t *testing.T) {
+	tests := []struct {
+		name     string
+		modify   func(o *Options, p *Probes)
+		check    string
+		expected Status
+	}{
+		{"healthy", func(o *Options, p *Probes) {}, "", StatusOK},
+		{"no config", func(o *Options, p *Probes) { o.PsiphonConfig = nil }, "Psiphon config", StatusFail},
+		{"config error", func(o *Options, p *Probes) { o.ConfigError = errors.New("psiphon config file not found") }, "Psiphon config", StatusFail},
+		{"placeholder config", func(o *Options, p *Probes) {
+			o.PsiphonConfig = []byte(`{"PropagationChannelId":"FFFFFFFFFFFFFFFF","SponsorId":"FFFFFFFFFFFFFFFF","AdditionalParameters":{}}`)
+		}, "Psiphon config", StatusFail},
+		{"no broker parameters", func(o *Options, p *Probes) {
+			o.PsiphonConfig = []byte(`{"PropagationChannelId":"0123","SponsorId":"0123"}`)
+		}, "Psiphon config", StatusFail},
+		{"rejected config", func(o *Options, p *Probes) {
+			p.LoadPsiphonConfig = func([]byte) error { return errors.New("invalid") }
+		}, "Psiphon config", StatusFail},
+		{"missing data dir", func(o *Options, p *Probes) { o.DataDir = filepath.Join(o.DataDir, "missing") }, "Data directory", StatusWarn},
+		{"no key", func(o *Options, p *Probes) { os.Remove(config.KeyFilePath(o.DataDir)) }, "Station key", StatusWarn},
+		{"corrupt key", func(o *Options, p *Probes) {
+			p.VerifyKey = func(string, config.KeyOptions) error { return errors.New("private key is corrupt") }
+		}, "Station key", StatusFail},
+		{"no dns", func(o *Options, p *Probes) {
+			p.LookupHost = func(context.Context, string) ([]string, error) { return nil, errors.New("no such host") }
+		}, "DNS", StatusFail},
+		{"partial dns", func(o *Options, p *Probes) {
+			p.LookupHost = func(ctx context.Context, host string) ([]string, error) {
+				if host == "a.example" {
+					return nil, errors.New("no such host")
+				}
+				return []string{"192.0.2.1"}, nil
+			}
+		}, "DNS", StatusWarn},
+		{"no https", func(o *Options, p *Probes) {
+			p.HTTPS = func(context.Context, string) (time.Time, error) { return time.Time{}, errors.New("connection refused") }
+		}, "Outbound HTTPS", StatusFail},
+		{"no broker", func(o *Options, p *Probes) {
+			p.Dial = func(context.Context, string) error { return errors.New("connection refused") }
+		}, "Broker reachability", StatusFail},
+		{"partial broker", func(o *Options, p *Probes) {
+			p.Dial = func(ctx context.Context, address string) error {
+				if address == "front1.example:443" {
+					return errors.New("connection refused")
+				}
+				return nil
+			}
+		}, "Broker reachability", StatusWarn},
+		{"encrypted broker specs", func(o *Options, p *Probes) {
+			o.PsiphonConfig = []byte(`{"PropagationChannelId":"0123456789ABCDEF","SponsorId":"0123456789ABCDEF","AdditionalParameters":"abc"}`)
+		}, "Broker reachability", StatusSkip},
+		{"clock skew warning", func(o *Options, p *Probes) {
+			p.Now = func() time.Time { return now.Add(2 * time.Minute) }
+		}, "Clock", StatusWarn},
+		{"clock skew failure", func(o *Options, p *Probes) {
+			p.Now = func() time.Time { return now.Add(-time.Hour) }
+		}, "Clock", StatusFail},
+		{"clock without server time", func(o *Options, p *Probes) {
+			p.HTTPS = func(context.Context, string) (time.Time, error) { return time.Time{}, errors.New("timeout") }
+		}, "Clock", StatusSkip},
+		{"udp blocked", func(o *Options, p *Probes) {
+			p.STUN = func(context.Context, []string) (STUNResult, error) { return STUNResult{}, nil }
+		}, "UDP and NAT", StatusFail},
+		{"symmetric nat", func(o *Options, p *Probes) {
+			p.STUN = func(ctx context.Context, servers []string) (STUNResult, error) {
+				return STUNResult{Mapped: map[string]netip.AddrPort{
+					servers[0]: netip.MustParseAddrPort("203.0.113.1:6000"),
+					servers[1]: netip.MustParseAddrPort("203.0.113.1:6001"),
+				}}, nil
+			}
+		}, "UDP and NAT", StatusWarn},
+	}
+	for _, tt := range tests {
+		opts, probes := testOptions(t), healthyProbes()
+		tt.modify(&opts, &probes)
+		results := Run(context.Background(), opts, probes)
+		got := statuses(results)
+		if tt.check == "" {
+			for name, status := range got {
+				if status != StatusOK {
+					t.Fatalf("%s: %s = %s, expected ok: %+v", tt.name, name, status, results)
+				}
+			}
+			continue
+		}
+		if got[tt.check] != tt.expected {
+			t.Fatalf("%s: %s = %s, expected %s: %+v", tt.name, tt.check, got[tt.check], tt.expected, results)
+		}
+		for _, r := range results {
+			if (r.Status == StatusFail || r.Status == StatusWarn) && r.Fix == "" {
+				t.Fatalf("%s: %s has no fix", tt.name, r.Name)
+			}
+		}
+	}
+}
+
+func TestBrokerAddresses(t *testing.T) {
+	addresses, encrypted := brokerAddresses([]byte(validConfig))
+	if !encrypted || len(addresses) != 2 || addresses[0] != "front1.example:443" || addresses[1] != "front2.example:8443" {
+		t.Fatalf("brokerAddresses = %v, %v", addresses, encrypted)
+	}
+}
@@ -0,0 +1,158 @@
+/*
+ * Copyright (c) 2026, Psiphon Inc.
+ * All rights reserved.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package doctor
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"net/netip"
+	"time"
+)
+
+// STUN message constants (RFC 5389)
+const (
+	stunBindingRequest  = 0x0001
+	stunBindingResponse = 0x0101
+	stunMagicCookie     = 0x2112A442
+	stunHeaderSize      = 20
+
+	stunAttrMappedAddress    = 0x0001
+	stunAttrXORMappedAddress = 0x0020
+)
+
+// STUNResult is the public address seen by each STUN server, all probed
+// from the same local socket
+type STUNResult struct {
+	Local  netip.AddrPort
+	Mapped map[string]netip.AddrPort // by server; missing when the server didn't answer
+}
+
+// ProbeSTUN sends a binding request to each server from one UDP socket
+func ProbeSTUN(ctx context.Context, servers []string) (STUNResult, error) {
+	result := STUNResult{Mapped: map[string]netip.AddrPort{}}
+	conn, err := net.ListenUDP("udp4", nil)
+	if err != nil {
+		return result, fmt.Errorf("failed to open a UDP socket: %w", err)
+	}
+	defer conn.Close()
+
+	// The outbound interface address, to tell whether there is a NAT at all
+	if probe, err := net.Dial("udp4", "192.0.2.1:9"); err == nil {
+		result.Local = netip.MustParseAddrPort(probe.LocalAddr().String())
+		probe.Close()
+	}
+	result.Local = netip.AddrPortFrom(result.Local.Addr(), uint16(conn.LocalAddr().(*net.UDPAddr).Port))
+
+	for _, server := range servers {
+		mapped, err := stunBinding(ctx, conn, server)
+		if err == nil {
+			result.Mapped[server] = mapped
+		}
+	}
+	return result, nil
+}
+
+// stunBinding sends one binding request, retrying a few times as UDP may drop it
+func stunBinding(ctx context.Context, conn *net.UDPConn, server string) (netip.AddrPort, error) {
+	addr, err := net.ResolveUDPAddr("udp4", server)
+	if err != nil {
+		return netip.AddrPort{}, err
+	}
+	request := make([]byte, stunHeaderSize)
+	binary.BigEndian.PutUint16(request[0:], stunBindingRequest)
+	binary.BigEndian.PutUint32(request[4:], stunMagicCookie)
+	if _, err := rand.Read(request[8:20]); err != nil {
+		return netip.AddrPort{}, err
+	}
+
+	buf := make([]byte, 1500)
+	for attempt := 0; attempt < 3; attempt++ {
+		if ctx.Err() != nil {
+			return netip.AddrPort{}, ctx.Err()
+		}
+		if _, err := conn.WriteToUDP(request, addr); err != nil {
+			return netip.AddrPort{}, err
+		}
+		conn.SetReadDeadline(time.Now().Add(time.Second))
+		for {
+			n, _, err := conn.ReadFromUDP(buf)
+			if err != nil {
+				break // timed out: resend
+			}
+			if mapped, err := parseSTUNResponse(buf[:n], request[8:20]); err == nil {
+				return mapped, nil
+			}
+		}
+	}
+	return netip.AddrPort{}, errors.New("no response")
+}
+
+// parseSTUNResponse returns the mapped address of a binding response for transaction id
+func parseSTUNResponse(b, id []byte) (netip.AddrPort, error) {
+	if len(b) < stunHeaderSize || binary.BigEndian.Uint16(b[0:]) != stunBindingResponse ||
+		binary.BigEndian.Uint32(b[4:]) != stunMagicCookie || string(b[8:20]) != string(id) {
+		return netip.AddrPort{}, errors.New("not a binding response")
+	}
+	length := int(binary.BigEndian.Uint16(b[2:]))
+	if len(b) < stunHeaderSize+length {
+		return netip.AddrPort{}, errors.New("truncated response")
+	}
+
+	var mapped netip.AddrPort
+	attrs := b[stunHeaderSize : stunHeaderSize+length]
+	for len(attrs) >= 4 {
+		typ := binary.BigEndian.Uint16(attrs[0:])
+		size := int(binary.BigEndian.Uint16(attrs[2:]))
+		if len(attrs) < 4+size {
+			break
+		}
+		value := attrs[4 : 4+size]
+		// IPv4 only: family 0x01, port, 4 address bytes
+		if (typ == stunAttrXORMappedAddress || typ == stunAttrMappedAddress) && size >= 8 && value[1] == 0x01 {
+			port := binary.BigEndian.Uint16(value[2:])
+			ip := [4]byte(value[4:8])
+			if typ == stunAttrXORMappedAddress {
+				port ^= stunMagicCookie >> 16
+				cookie := binary.BigEndian.AppendUint32(nil, stunMagicCookie)
+				for i := range ip {
+					ip[i] ^= cookie[i]
+				}
+			}
+			mapped = netip.AddrPortFrom(netip.AddrFrom4(ip), port)
+			if typ == stunAttrXORMappedAddress {
+				return mapped, nil
+			}
+		}
+		// Attributes are padded to 4 bytes
+		next := 4 + (size+3)&^3
+		if next > len(attrs) {
+			break
+		}
+		attrs = attrs[next:]
+	}
+	if !mapped.IsValid() {
+		return netip.AddrPort{}, errors.New("no mapped address")
+	}
+	return mapped, nil
+}
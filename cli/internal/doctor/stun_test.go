@@ -0,0 +1,121 @@
+/*
+ * Copyright (c) 2026, Psiphon Inc.
+ * All rights reserved.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package doctor
+
+import (
+	"context"
+	"encoding/binary"
+	"net"
+	"net/netip"
+	"testing"
+)
+
+// stunServer answers binding requests with the sender's address, like a real
+// STUN server. With offset, it reports a different port, like a symmetric NAT.
+func stunServer(t *testing.T, offset uint16) string {
+	conn, err := net.ListenUDP("udp4", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	go func() {
+		buf := make([]byte, 1500)
+		for {
+			n, from, err := conn.ReadFromUDP(buf)
+			if err != nil {
+				return
+			}
+			if n < stunHeaderSize || binary.BigEndian.Uint16(buf) != stunBindingRequest {
+				continue
+			}
+			// XOR-MAPPED-ADDRESS, after an unknown padded attribute
+			resp := make([]byte, stunHeaderSize, stunHeaderSize+20)
+			binary.BigEndian.PutUint16(resp[0:], stunBindingResponse)
+			binary.BigEndian.PutUint16(resp[2:], 20)
+			copy(resp[4:20], buf[4:20])
+			resp = append(resp, 0x80, 0x22, 0x00, 0x01, 'x', 0, 0, 0)
+			resp = append(resp, 0x00, 0x20, 0x00, 0x08, 0x00, 0x01)
+			resp = binary.BigEndian.AppendUint16(resp, uint16(from.Port)+offset^stunMagicCookie>>16)
+			ip := from.IP.To4()
+			resp = binary.BigEndian.AppendUint32(resp, binary.BigEndian.Uint32(ip)^stunMagicCookie)
+			conn.WriteToUDP(resp, from)
+		}
+	}()
+	return conn.LocalAddr().String()
+}
+
+func TestProbeSTUN(t *testing.T) {
+	cone1, cone2, symmetric := stunServer(t, 0), stunServer(t, 0), stunServer(t, 1)
+
+	// A closed port never answers
+	closed, _ := net.ListenUDP("udp4", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	silent := closed.LocalAddr().String()
+	closed.Close()
+
+	result, err := ProbeSTUN(context.Background(), []string{cone1, cone2, symmetric, silent})
+	if err != nil {
+		t.Fatalf("ProbeSTUN: %v", err)
+	}
+	if len(result.Mapped) != 3 {
+		t.Fatalf("mapped = %v, expected answers from 3 servers", result.Mapped)
+	}
+	if result.Mapped[cone1] != result.Mapped[cone2] || result.Mapped[cone1].Addr() != netip.MustParseAddr("127.0.0.1") {
+		t.Fatalf("cone servers mapped %v and %v", result.Mapped[cone1], result.Mapped[cone2])
+	}
+	if result.Mapped[cone1].Port() != result.Local.Port() || result.Mapped[symmetric].Port() != result.Local.Port()+1 {
+		t.Fatalf("mapped ports %v, local %v", result.Mapped, result.Local)
+	}
+}
+
+func TestParseSTUNResponse(t *testing.T) {
+	id := []byte("0123456789ab")
+	header := func(typ uint16, length uint16, id []byte) []byte {
+		b := binary.BigEndian.AppendUint16(nil, typ)
+		b = binary.BigEndian.AppendUint16(b, length)
+		b = binary.BigEndian.AppendUint32(b, stunMagicCookie)
+		return append(b, id...)
+	}
+	mapped := append(header(stunBindingResponse, 12, id), 0x00, 0x01, 0x00, 0x08, 0x00, 0x01, 0x1f, 0x90, 203, 0, 113, 1)
+
+	tests := []struct {
+		name     string
+		response []byte
+		expected string
+	}{
+		{"mapped address", mapped, "203.0.113.1:8080"},
+		{"wrong transaction", append(header(stunBindingResponse, 12, []byte("xxxxxxxxxxxx")), mapped[20:]...), ""},
+		{"not a response", append(header(stunBindingRequest, 12, id), mapped[20:]...), ""},
+		{"truncated", header(stunBindingResponse, 12, id), ""},
+		{"truncated attribute", append(header(stunBindingResponse, 8, id), 0x00, 0x20, 0x00, 0x08, 0, 1, 0, 0), ""},
+	}
+	for _, tt := range tests {
+		got, err := parseSTUNResponse(tt.response, id)
+		if tt.expected == "" {
+			if err == nil {
+				t.Fatalf("%s: parsed %v, expected an error", tt.name, got)
+			}
+			continue
+		}
+		if err != nil || got.String() != tt.expected {
+			t.Fatalf("%s: %v, %v, expected %s", tt.name, got, err, tt.expected)
+		}
+	}
+}
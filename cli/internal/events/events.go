@@ -0,0 +1,208 @@
+/*
+ * Copyright (c) 2026, Psiphon Inc.
+ * All rights reserved.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+// Package events publishes typed station events to live subscribers
+package events
+
+import (
+	"strings"
+	"sync"
+	"time"
+)
+
+// Type identifies the kind of an event
+type Type string
+
+const (
+	TypeStats                 Type = "stats"                  // Data is a stats snapshot
+	TypeConnectionEstablished Type = "connection.established" // Data is Connection
+	TypeConnectionClosed      Type = "connection.closed"      // Data is Connection
+	TypeBroker                Type = "broker"                 // Data is Broker
+	TypeError                 Type = "error"                  // Data is Error
+	TypeRestart               Type = "restart"                // Data is Restart
+	TypeUpgrade               Type = "upgrade"                // Data is Upgrade
+)
+
+// DefaultHistory is how many recent events a bus keeps for reconnecting subscribers
+const DefaultHistory = 256
+
+// subscriberBuffer is how many events a subscriber may fall behind before it is dropped
+const subscriberBuffer = 64
+
+// Event is a single station event. IDs increase by one per event.
+type Event struct {
+	ID   uint64    `json:"id"`
+	Type Type      `json:"type"`
+	Time time.Time `json:"time"`
+	Data any       `json:"data,omitempty"`
+}
+
+// Connection describes a client connection. It never includes the client IP.
+type Connection struct {
+	CandidateType string `json:"candidateType,omitempty"`
+	Country       string `json:"country,omitempty"`
+	BytesUp       int64  `json:"bytesUp,omitempty"`
+	BytesDown     int64  `json:"bytesDown,omitempty"`
+}
+
+// Broker reports a change of the broker connection
+type Broker struct {
+	Connected bool `json:"connected"`
+}
+
+// Error is an error reported by the tunnel core
+type Error struct {
+	Message string `json:"message"`
+}
+
+// Restart reports that the service is restarting, and why
+type Restart struct {
+	Reason string `json:"reason"`
+}
+
+// Upgrade reports that the broker requires a newer version
+type Upgrade struct {
+	Message string `json:"message"`
+}
+
+// Bus fans events out to subscribers. A nil Bus is valid and drops all events.
+type Bus struct {
+	mu      sync.Mutex
+	lastID  uint64
+	history []Event
+	size    int
+	subs    map[*Subscription]struct{}
+}
+
+// NewBus creates a bus that keeps the last history events
+func NewBus(history int) *Bus {
+	return &Bus{
+		size: history,
+		subs: make(map[*Subscription]struct{}),
+	}
+}
+
+// Publish sends an event to all subscribers without blocking. A subscriber
+// that has fallen too far behind is closed, so it can resubscribe from the
+// last event it received.
+func (b *Bus) Publish(t Type, data any) {
+	if b == nil {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.lastID++
+	event := Event{ID: b.lastID, Type: t, Time: time.Now().UTC(), Data: data}
+	if b.size > 0 {
+		if len(b.history) == b.size {
+			b.history = append(b.history[:0], b.history[1:]...)
+		}
+		b.history = append(b.history, event)
+	}
+
+	for sub := range b.subs {
+		select {
+		case sub.ch <- event:
+		default:
+			b.remove(sub)
+		}
+	}
+}
+
+// Subscribe returns a subscription to new events. Kept events with an ID
+// after lastID are replayed first; an ID newer than any published event
+// (from before a process restart) replays all kept events.
+func (b *Bus) Subscribe(lastID uint64) *Subscription {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var replay []Event
+	if lastID > 0 {
+		for _, event := range b.history {
+			if event.ID > lastID || lastID > b.lastID {
+				replay = append(replay, event)
+			}
+		}
+	}
+
+	sub := &Subscription{bus: b, ch: make(chan Event, subscriberBuffer+len(replay))}
+	for _, event := range replay {
+		sub.ch <- event
+	}
+	b.subs[sub] = struct{}{}
+	return sub
+}
+
+// remove closes a subscription. Must be called with lock held.
+func (b *Bus) remove(sub *Subscription) {
+	if _, ok := b.subs[sub]; ok {
+		delete(b.subs, sub)
+		close(sub.ch)
+	}
+}
+
+// Subscription receives events from a bus until closed
+type Subscription struct {
+	bus *Bus
+	ch  chan Event
+}
+
+// Events returns the channel of events. It is closed when the subscription
+// is closed or falls too far behind.
+func (s *Subscription) Events() <-chan Event {
+	return s.ch
+}
+
+// Close stops the subscription
+func (s *Subscription) Close() {
+	s.bus.mu.Lock()
+	defer s.bus.mu.Unlock()
+	s.bus.remove(s)
+}
+
+// Filter matches event types against a list of types. A name without a dot
+// also matches all types in that group, so "connection" matches
+// "connection.established" and "connection.closed".
+type Filter []string
+
+// ParseFilter parses a comma-separated list of types. An empty list matches all events.
+func ParseFilter(s string) Filter {
+	var f Filter
+	for _, name := range strings.Split(s, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			f = append(f, name)
+		}
+	}
+	return f
+}
+
+// Match reports whether t is selected by the filter
+func (f Filter) Match(t Type) bool {
+	if len(f) == 0 {
+		return true
+	}
+	for _, name := range f {
+		if string(t) == name || strings.HasPrefix(string(t), name+".") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,104 @@
+/*
+ * Copyright (c) 2026, Psiphon Inc.
+ * All rights reserved.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package events
+
+import "testing"
+
+func TestBus(t *testing.T) {
+	var nilBus *Bus
+	nilBus.Publish(TypeStats, nil)
+
+	bus := NewBus(3)
+	sub := bus.Subscribe(0)
+	for i := 0; i < 5; i++ {
+		bus.Publish(TypeError, Error{Message: "e"})
+	}
+	for i := uint64(1); i <= 5; i++ {
+		if event := <-sub.Events(); event.ID != i || event.Type != TypeError {
+			t.Fatalf("event %d = %+v", i, event)
+		}
+	}
+	sub.Close()
+	if _, ok := <-sub.Events(); ok {
+		t.Fatalf("closed subscription still receives events")
+	}
+	sub.Close()
+
+	tests := []struct {
+		lastID   uint64
+		expected []uint64
+	}{
+		{0, nil},               // new subscribers start with new events
+		{4, []uint64{5}},       // resume after the last event received
+		{1, []uint64{3, 4, 5}}, // events older than the history are lost
+		{5, nil},
+		{9, []uint64{3, 4, 5}}, // IDs from before a restart replay everything
+	}
+	for _, tt := range tests {
+		sub := bus.Subscribe(tt.lastID)
+		var ids []uint64
+		for len(sub.Events()) > 0 {
+			ids = append(ids, (<-sub.Events()).ID)
+		}
+		sub.Close()
+		if len(ids) != len(tt.expected) {
+			t.Fatalf("Subscribe(%d) replayed %v, expected %v", tt.lastID, ids, tt.expected)
+		}
+		for i := range ids {
+			if ids[i] != tt.expected[i] {
+				t.Fatalf("Subscribe(%d) replayed %v, expected %v", tt.lastID, ids, tt.expected)
+			}
+		}
+	}
+
+	// A subscriber that falls behind is closed rather than blocking the bus
+	slow := bus.Subscribe(0)
+	for i := 0; i < subscriberBuffer+1; i++ {
+		bus.Publish(TypeStats, nil)
+	}
+	n := 0
+	for range slow.Events() {
+		n++
+	}
+	if n != subscriberBuffer {
+		t.Fatalf("slow subscriber got %d events, expected %d before being closed", n, subscriberBuffer)
+	}
+}
+
+func TestFilter(t *testing.T) {
+	tests := []struct {
+		filter   string
+		t        Type
+		expected bool
+	}{
+		{"", TypeStats, true},
+		{"stats", TypeStats, true},
+		{"stats", TypeBroker, false},
+		{"connection", TypeConnectionClosed, true},
+		{"connection.closed", TypeConnectionEstablished, false},
+		{" broker , upgrade ", TypeUpgrade, true},
+		{"conn", TypeConnectionClosed, false},
+	}
+	for _, tt := range tests {
+		if got := ParseFilter(tt.filter).Match(tt.t); got != tt.expected {
+			t.Fatalf("ParseFilter(%q).Match(%s) = %v, expected %v", tt.filter, tt.t, got, tt.expected)
+		}
+	}
+}
@@ -0,0 +1,104 @@
+/*
+ * Copyright (c) 2026, Psiphon Inc.
+ * All rights reserved.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package feedback
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/Psiphon-Inc/conduit/cli/internal/crypto"
+	"github.com/Psiphon-Labs/psiphon-tunnel-core/psiphon"
+)
+
+// PassphraseEnv supplies the passphrase of local bundles (or, with a _FILE
+// suffix, a file containing it)
+const PassphraseEnv = "CONDUIT_FEEDBACK_PASSPHRASE"
+
+const bundleFormat = "conduit-feedback-v1"
+
+// bundle is a report sealed with a passphrase, for keeping or sending later
+type bundle struct {
+	Format string            `json:"format"`
+	ID     string            `json:"id"`
+	Sealed *crypto.SealedBox `json:"sealed"`
+}
+
+// Seal encrypts a marshaled report with a passphrase
+func Seal(report []byte, id string, passphrase []byte) ([]byte, error) {
+	sealed, err := crypto.Seal(report, passphrase)
+	if err != nil {
+		return nil, err
+	}
+	return json.MarshalIndent(bundle{Format: bundleFormat, ID: id, Sealed: sealed}, "", "  ")
+}
+
+// Open decrypts a sealed bundle
+func Open(data, passphrase []byte) ([]byte, error) {
+	var b bundle
+	if err := json.Unmarshal(data, &b); err != nil || b.Format != bundleFormat {
+		return nil, errors.New("not a conduit feedback bundle")
+	}
+	return crypto.Open(b.Sealed, passphrase)
+}
+
+// Uploader sends a marshaled report to Psiphon
+type Uploader func(ctx context.Context, diagnostics []byte) error
+
+// PsiphonUploader uploads with tunnel-core's feedback mechanism, which
+// encrypts the report to Psiphon's key. It uses the FeedbackUploadURLs and
+// FeedbackEncryptionPublicKey of the Psiphon network config.
+func PsiphonUploader(psiphonConfig []byte) Uploader {
+	return func(ctx context.Context, diagnostics []byte) error {
+		var configJSON map[string]any
+		if err := json.Unmarshal(psiphonConfig, &configJSON); err != nil {
+			return fmt.Errorf("failed to parse psiphon config: %w", err)
+		}
+		for _, field := range []string{"FeedbackUploadURLs", "FeedbackEncryptionPublicKey"} {
+			if configJSON[field] == nil {
+				return fmt.Errorf("the psiphon config has no %s, so feedback can't be uploaded; save a bundle instead", field)
+			}
+		}
+
+		// Keep tunnel-core's files away from a running station's data dir
+		dataDir, err := os.MkdirTemp("", "conduit-feedback-")
+		if err != nil {
+			return err
+		}
+		defer os.RemoveAll(dataDir)
+		configJSON["DataRootDirectory"] = dataDir
+		configJSON["ClientVersion"] = "1"
+
+		configData, err := json.Marshal(configJSON)
+		if err != nil {
+			return fmt.Errorf("failed to serialize config: %w", err)
+		}
+		config, err := psiphon.LoadConfig(configData)
+		if err != nil {
+			return fmt.Errorf("failed to load config: %w", err)
+		}
+		if err := config.Commit(true); err != nil {
+			return fmt.Errorf("failed to commit config: %w", err)
+		}
+		return psiphon.SendFeedback(ctx, config, string(diagnostics), "")
+	}
+}
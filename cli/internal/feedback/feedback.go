@@ -0,0 +1,258 @@
+/*
+ * Copyright (c) 2026, Psiphon Inc.
+ * All rights reserved.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+// Package feedback collects diagnostics for bug reports, in the layout of
+// the Psiphon apps' feedback, and seals or uploads them
+package feedback
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+	"time"
+
+	"github.com/Psiphon-Inc/conduit/cli/internal/config"
+	"github.com/Psiphon-Inc/conduit/cli/internal/debug"
+	"github.com/Psiphon-Labs/psiphon-tunnel-core/psiphon/common/buildinfo"
+)
+
+// DefaultHistoryLines is how many stats history lines a report includes
+const DefaultHistoryLines = 200
+
+// reportVersion is the Metadata version of the Psiphon feedback format
+const reportVersion = 1
+
+// Report is a diagnostics report
+type Report struct {
+	Metadata       Metadata       `json:"Metadata"`
+	DiagnosticInfo DiagnosticInfo `json:"DiagnosticInfo"`
+	Feedback       *Feedback      `json:"Feedback,omitempty"`
+}
+
+// Metadata identifies a report
+type Metadata struct {
+	Platform string `json:"platform"`
+	Version  int    `json:"version"`
+	ID       string `json:"id"`
+	AppName  string `json:"appName"`
+	Time     string `json:"time"`
+}
+
+// DiagnosticInfo is what a report collected from the station
+type DiagnosticInfo struct {
+	SystemInformation SystemInformation `json:"SystemInformation"`
+	Config            any               `json:"Config,omitempty"`
+	Stats             json.RawMessage   `json:"Stats,omitempty"`
+	StatsHistory      []string          `json:"StatsHistory,omitempty"`
+	DiagnosticHistory []json.RawMessage `json:"DiagnosticHistory"` // recent psiphon notices
+	CollectionErrors  []string          `json:"CollectionErrors,omitempty"`
+}
+
+// SystemInformation describes the binary and host
+type SystemInformation struct {
+	Version   string               `json:"version"`
+	Build     *buildinfo.BuildInfo `json:"build"`
+	OS        string               `json:"os"`
+	Arch      string               `json:"arch"`
+	CPUs      int                  `json:"cpus"`
+	Container bool                 `json:"container"`
+	Systemd   bool                 `json:"systemd"`
+}
+
+// Feedback is the operator's description of the problem
+type Feedback struct {
+	Message struct {
+		Text string `json:"text"`
+	} `json:"Message"`
+}
+
+// Sources configures what a report collects
+type Sources struct {
+	Version      string
+	Message      string
+	DataDir      string
+	Debug        debug.Client // debug server of a running station, for notices, stats and config
+	StatsFile    string       // stats file, read when no station is reachable
+	StatsHistory string       // stats history file
+	HistoryLines int
+}
+
+// Collect gathers a report. Missing sources are noted in CollectionErrors
+// rather than failing the report.
+func Collect(ctx context.Context, src Sources) (*Report, error) {
+	id := make([]byte, 8)
+	if _, err := rand.Read(id); err != nil {
+		return nil, fmt.Errorf("failed to generate report id: %w", err)
+	}
+	r := &Report{
+		Metadata: Metadata{
+			Platform: "conduit-cli_" + runtime.GOOS,
+			Version:  reportVersion,
+			ID:       hex.EncodeToString(id),
+			AppName:  "conduit",
+			Time:     time.Now().UTC().Format(time.RFC3339),
+		},
+	}
+	r.DiagnosticInfo.SystemInformation = systemInformation(src.Version)
+	r.DiagnosticInfo.DiagnosticHistory = []json.RawMessage{}
+	if src.Message != "" {
+		r.Feedback = &Feedback{}
+		r.Feedback.Message.Text = src.Message
+	}
+
+	info := &r.DiagnosticInfo
+	fail := func(format string, args ...any) {
+		info.CollectionErrors = append(info.CollectionErrors, fmt.Sprintf(format, args...))
+	}
+
+	// A running station has the recent notices and the configuration in effect
+	station := false
+	if src.Debug.Addr != "" {
+		var err error
+		if station, err = collectStation(ctx, src.Debug, info); err != nil {
+			fail("station not reachable at %s (start it with --debug-addr to include notices): %v", src.Debug.Addr, err)
+		}
+	}
+	if !station {
+		name, err := config.LoadStationName(src.DataDir)
+		if err != nil {
+			fail("station name: %v", err)
+		}
+		info.Config = map[string]string{"dataDir": src.DataDir, "stationName": name}
+		if src.StatsFile != "" {
+			if data, err := os.ReadFile(src.StatsFile); err == nil && json.Valid(data) {
+				info.Stats = data
+			}
+		}
+	}
+
+	if src.StatsHistory != "" {
+		lines := src.HistoryLines
+		if lines == 0 {
+			lines = DefaultHistoryLines
+		}
+		history, err := tail(src.StatsHistory, lines)
+		if err != nil {
+			fail("stats history: %v", err)
+		}
+		info.StatsHistory = history
+	}
+	return r, nil
+}
+
+// collectStation reads notices, stats and configuration from a station's
+// debug server
+func collectStation(ctx context.Context, client debug.Client, info *DiagnosticInfo) (bool, error) {
+	get := func(path string) ([]byte, error) {
+		ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+		resp, err := client.Get(ctx, path)
+		if err != nil {
+			return nil, err
+		}
+		defer resp.Body.Close()
+		return io.ReadAll(resp.Body)
+	}
+
+	data, err := get("/debug/config")
+	if err != nil {
+		return false, err
+	}
+	var cfg any
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return false, fmt.Errorf("invalid config: %w", err)
+	}
+	info.Config = cfg
+
+	// Stats are unavailable while the service restarts
+	if data, err := get("/debug/stats"); err == nil && json.Valid(data) {
+		info.Stats = data
+	}
+
+	data, err = get("/debug/notices")
+	if err != nil {
+		info.CollectionErrors = append(info.CollectionErrors, "notices: "+err.Error())
+		return true, nil
+	}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(nil, 1<<20)
+	for scanner.Scan() {
+		if line := scanner.Bytes(); json.Valid(line) {
+			info.DiagnosticHistory = append(info.DiagnosticHistory, append(json.RawMessage(nil), line...))
+		}
+	}
+	return true, nil
+}
+
+func systemInformation(version string) SystemInformation {
+	_, dockerErr := os.Stat("/.dockerenv")
+	return SystemInformation{
+		Version:   version,
+		Build:     buildinfo.GetBuildInfo(),
+		OS:        runtime.GOOS,
+		Arch:      runtime.GOARCH,
+		CPUs:      runtime.NumCPU(),
+		Container: dockerErr == nil || os.Getenv("container") != "",
+		Systemd:   os.Getenv("INVOCATION_ID") != "",
+	}
+}
+
+// tail returns the last n lines of a file
+func tail(path string, n int) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	if n <= 0 {
+		return nil, nil
+	}
+
+	// Keep the last n lines in a ring, overwriting the oldest
+	lines := make([]string, n)
+	count := 0
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(nil, 1<<20)
+	for scanner.Scan() {
+		lines[count%n] = scanner.Text()
+		count++
+	}
+	if count < n {
+		return lines[:count], scanner.Err()
+	}
+	next := count % n
+	return append(lines[next:], lines[:next]...), scanner.Err()
+}
+
+// Marshal encodes the report with IP addresses redacted
+func (r *Report) Marshal() ([]byte, error) {
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return debug.RedactAddresses(data), nil
+}
@@ -0,0 +1,139 @@
+/*
+ * Copyright (c) 2026, Psiphon Inc.
+ * All rights reserved.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package feedback
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Psiphon-Inc/conduit/cli/internal/debug"
+)
+
+func TestCollectFromStation(t *testing.T) {
+	notices := debug.NewNotices(10)
+	notices.Add([]byte(`{"noticeType":"Warning","data":{"message":"dial 198.51.100.1:443 failed"}}`))
+	station := httptest.NewServer(debug.Handler(debug.Sources{
+		Stats:   func() (any, bool) { return map[string]int{"connectedClients": 7}, true },
+		Config:  map[string]string{"stationName": "berlin"},
+		Notices: notices,
+	}))
+	defer station.Close()
+
+	history := filepath.Join(t.TempDir(), "history.jsonl")
+	var lines []string
+	for i := 0; i < 5; i++ {
+		lines = append(lines, fmt.Sprintf(`{"connectedClients":%d}`, i))
+	}
+	if err := os.WriteFile(history, []byte(strings.Join(lines, "\n")+"\n"), 0600); err != nil {
+		t.Fatalf("write history: %v", err)
+	}
+
+	report, err := Collect(context.Background(), Sources{
+		Version:      "1.2.3",
+		Message:      "clients drop after an hour from 203.0.113.9",
+		DataDir:      t.TempDir(),
+		Debug:        debug.Client{Addr: strings.TrimPrefix(station.URL, "http://")},
+		StatsHistory: history,
+		HistoryLines: 3,
+	})
+	if err != nil {
+		t.Fatalf("Collect: %v", err)
+	}
+	if len(report.DiagnosticInfo.CollectionErrors) > 0 {
+		t.Fatalf("collection errors: %v", report.DiagnosticInfo.CollectionErrors)
+	}
+	if len(report.DiagnosticInfo.DiagnosticHistory) != 1 || len(report.DiagnosticInfo.StatsHistory) != 3 ||
+		report.DiagnosticInfo.StatsHistory[0] != `{"connectedClients":2}` || report.DiagnosticInfo.StatsHistory[2] != `{"connectedClients":4}` {
+		t.Fatalf("unexpected notices or history: %+v", report.DiagnosticInfo)
+	}
+
+	data, err := report.Marshal()
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	for _, ip := range []string{"198.51.100.1", "203.0.113.9"} {
+		if strings.Contains(string(data), ip) {
+			t.Fatalf("report contains %s", ip)
+		}
+	}
+	var decoded map[string]any
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("report is not JSON: %v", err)
+	}
+	if !strings.Contains(string(data), `"stationName": "berlin"`) || !strings.Contains(string(data), `"connectedClients": 7`) {
+		t.Fatalf("report is missing the station's config or stats: %s", data)
+	}
+}
+
+func TestCollectWithoutStation(t *testing.T) {
+	dataDir := t.TempDir()
+	statsFile := filepath.Join(dataDir, "custom.json")
+	os.WriteFile(statsFile, []byte(`{"connectedClients":3}`), 0600)
+
+	// Nothing listens on this address
+	station := httptest.NewServer(nil)
+	addr := strings.TrimPrefix(station.URL, "http://")
+	station.Close()
+
+	report, err := Collect(context.Background(), Sources{DataDir: dataDir, StatsFile: statsFile, Debug: debug.Client{Addr: addr}})
+	if err != nil {
+		t.Fatalf("Collect: %v", err)
+	}
+	if len(report.DiagnosticInfo.CollectionErrors) != 1 || !strings.Contains(report.DiagnosticInfo.CollectionErrors[0], "--debug-addr") {
+		t.Fatalf("collection errors: %v", report.DiagnosticInfo.CollectionErrors)
+	}
+	if string(report.DiagnosticInfo.Stats) != `{"connectedClients":3}` {
+		t.Fatalf("stats = %s", report.DiagnosticInfo.Stats)
+	}
+}
+
+func TestSealOpen(t *testing.T) {
+	report := []byte(`{"Metadata":{}}`)
+	sealed, err := Seal(report, "abc", []byte("passphrase"))
+	if err != nil {
+		t.Fatalf("Seal: %v", err)
+	}
+	if strings.Contains(string(sealed), "Metadata") {
+		t.Fatalf("bundle is not encrypted")
+	}
+	opened, err := Open(sealed, []byte("passphrase"))
+	if err != nil || string(opened) != string(report) {
+		t.Fatalf("Open = %s, %v", opened, err)
+	}
+	if _, err := Open(sealed, []byte("wrong")); err == nil {
+		t.Fatalf("Open with a wrong passphrase succeeded")
+	}
+	if _, err := Open([]byte(`{"format":"other"}`), []byte("passphrase")); err == nil {
+		t.Fatalf("Open of a non-bundle succeeded")
+	}
+}
+
+func TestPsiphonUploaderRequiresFeedbackParameters(t *testing.T) {
+	upload := PsiphonUploader([]byte(`{"PropagationChannelId":"x","SponsorId":"y"}`))
+	if err := upload(context.Background(), []byte(`{}`)); err == nil || !strings.Contains(err.Error(), "FeedbackUploadURLs") {
+		t.Fatalf("upload without feedback parameters = %v", err)
+	}
+}
@@ -98,23 +98,24 @@ func (c *Collector) Stop() error {
 	return nil
 }
 
-// ConnectIP records a new connection from an IP (call when connection opens)
-func (c *Collector) ConnectIP(ipStr string) {
+// ConnectIP records a new connection from an IP (call when connection opens).
+// Returns the country code, or "" if unknown.
+func (c *Collector) ConnectIP(ipStr string) string {
 	ip := net.ParseIP(ipStr)
 	if ip == nil || isPrivateIP(ip) {
-		return
+		return ""
 	}
 
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
 	if c.db == nil {
-		return
+		return ""
 	}
 
 	record, err := c.db.Country(ip)
 	if err != nil || record.Country.IsoCode == "" {
-		return
+		return ""
 	}
 
 	code := record.Country.IsoCode
@@ -133,25 +134,27 @@ func (c *Collector) ConnectIP(ipStr string) {
 
 	cd.live++
 	cd.totalIPs[ipStr] = struct{}{}
+	return code
 }
 
-// DisconnectIP records bandwidth and closes connection (call when connection closes)
-func (c *Collector) DisconnectIP(ipStr string, bytesUp, bytesDown int64) {
+// DisconnectIP records bandwidth and closes connection (call when connection closes).
+// Returns the country code, or "" if unknown.
+func (c *Collector) DisconnectIP(ipStr string, bytesUp, bytesDown int64) string {
 	ip := net.ParseIP(ipStr)
 	if ip == nil || isPrivateIP(ip) {
-		return
+		return ""
 	}
 
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
 	if c.db == nil {
-		return
+		return ""
 	}
 
 	record, err := c.db.Country(ip)
 	if err != nil || record.Country.IsoCode == "" {
-		return
+		return ""
 	}
 
 	code := record.Country.IsoCode
@@ -175,6 +178,7 @@ func (c *Collector) DisconnectIP(ipStr string, bytesUp, bytesDown int64) {
 	cd.totalIPs[ipStr] = struct{}{}
 	cd.bytesUp += bytesUp
 	cd.bytesDown += bytesDown
+	return code
 }
 
 // ConnectRelay records a new relay connection (call when connection opens)
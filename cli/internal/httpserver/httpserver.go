@@ -0,0 +1,311 @@
+/*
+ * Copyright (c) 2026, Psiphon Inc.
+ * All rights reserved.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+// Package httpserver serves HTTP endpoints with optional TLS, authentication
+// and a source address allowlist
+package httpserver
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"net"
+	"net/http"
+	"net/netip"
+	"os"
+	"time"
+
+	"github.com/Psiphon-Inc/conduit/cli/internal/config"
+)
+
+// selfSignedValidity is how long a generated certificate is valid. It is
+// regenerated when it expires within selfSignedRenewBefore.
+const (
+	selfSignedValidity    = 825 * 24 * time.Hour
+	selfSignedRenewBefore = 30 * 24 * time.Hour
+)
+
+// Options secures an endpoint. The zero value serves plain HTTP to anyone.
+type Options struct {
+	TLSCert       string // Serve HTTPS with this certificate and key
+	TLSKey        string
+	TLSSelfSigned bool   // Generate a self-signed TLS certificate and key at TLSCert and TLSKey
+	ClientCA      string // Require client certificates signed by this CA (mTLS)
+	BearerToken   string
+	Username      string // Basic auth
+	Password      string
+	Allow         []netip.Prefix // Source addresses allowed to connect (empty = all)
+}
+
+// Validate reports inconsistent options for an endpoint listening on addr.
+// Credentials are only accepted over TLS, unless addr is a loopback address.
+func (o Options) Validate(addr string) error {
+	if (o.TLSCert == "") != (o.TLSKey == "") {
+		return errors.New("a TLS certificate and key must be set together")
+	}
+	if o.ClientCA != "" && o.TLSCert == "" {
+		return errors.New("client certificate auth requires TLS")
+	}
+	if o.Username != "" && o.Password == "" {
+		return errors.New("basic auth requires a password")
+	}
+	if o.BearerToken != "" && o.Username != "" {
+		return errors.New("use either a bearer token or basic auth, not both")
+	}
+	if (o.BearerToken != "" || o.Username != "") && !o.TLS() && !Loopback(addr) {
+		return errors.New("credentials would be sent in plaintext: enable TLS or listen on a loopback address")
+	}
+	return nil
+}
+
+// TLS reports whether the endpoint is served over HTTPS
+func (o Options) TLS() bool {
+	return o.TLSCert != ""
+}
+
+// tlsConfig loads the certificate, and the client CA for mTLS
+func (o Options) tlsConfig() (*tls.Config, error) {
+	cert, err := tls.LoadX509KeyPair(o.TLSCert, o.TLSKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load TLS certificate: %w", err)
+	}
+	config := &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS12,
+	}
+	if o.ClientCA != "" {
+		data, err := os.ReadFile(o.ClientCA)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read client CA: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(data) {
+			return nil, fmt.Errorf("no certificates found in client CA %s", o.ClientCA)
+		}
+		config.ClientCAs = pool
+		config.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+	return config, nil
+}
+
+// protect wraps a handler with the source allowlist and authentication
+func (o Options) protect(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if len(o.Allow) > 0 && !o.allowed(r.RemoteAddr) {
+			http.Error(w, "Forbidden", http.StatusForbidden)
+			return
+		}
+
+		switch {
+		case o.BearerToken != "":
+			if !equal(r.Header.Get("Authorization"), "Bearer "+o.BearerToken) {
+				w.Header().Set("WWW-Authenticate", `Bearer realm="conduit"`)
+				http.Error(w, "Unauthorized", http.StatusUnauthorized)
+				return
+			}
+		case o.Username != "":
+			username, password, ok := r.BasicAuth()
+			// Check both, so a wrong username takes as long as a wrong password
+			userOK := equal(username, o.Username)
+			passwordOK := equal(password, o.Password)
+			if !ok || !userOK || !passwordOK {
+				w.Header().Set("WWW-Authenticate", `Basic realm="conduit"`)
+				http.Error(w, "Unauthorized", http.StatusUnauthorized)
+				return
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (o Options) allowed(remoteAddr string) bool {
+	addrPort, err := netip.ParseAddrPort(remoteAddr)
+	if err != nil {
+		return false
+	}
+	addr := addrPort.Addr().Unmap()
+	for _, prefix := range o.Allow {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// Authenticated reports whether clients must authenticate
+func (o Options) Authenticated() bool {
+	return o.BearerToken != "" || o.Username != "" || o.ClientCA != ""
+}
+
+// equal compares secrets in constant time
+func equal(a, b string) bool {
+	return subtle.ConstantTimeCompare([]byte(a), []byte(b)) == 1
+}
+
+// ParseAllow parses allowlist entries, as CIDRs or single addresses
+func ParseAllow(entries []string) ([]netip.Prefix, error) {
+	var prefixes []netip.Prefix
+	for _, entry := range entries {
+		prefix, err := netip.ParsePrefix(entry)
+		if err != nil {
+			addr, addrErr := netip.ParseAddr(entry)
+			if addrErr != nil {
+				return nil, fmt.Errorf("invalid address or CIDR %q", entry)
+			}
+			prefix = netip.PrefixFrom(addr, addr.BitLen())
+		}
+		prefixes = append(prefixes, prefix.Masked())
+	}
+	return prefixes, nil
+}
+
+// SelfSignedCertificate creates a self-signed certificate and key at the
+// given paths, unless a valid pair is already there, so that scrapers can pin
+// it across restarts. It returns the certificate's SHA-256 fingerprint.
+func SelfSignedCertificate(certPath, keyPath string) (string, error) {
+	// The pair is checked together, so a crash between writing the key and
+	// the certificate just creates a new pair on the next start
+	if pair, err := tls.LoadX509KeyPair(certPath, keyPath); err == nil && time.Until(pair.Leaf.NotAfter) > selfSignedRenewBefore {
+		return fingerprint(pair.Leaf.Raw), nil
+	}
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate TLS key: %w", err)
+	}
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return "", fmt.Errorf("failed to generate TLS certificate: %w", err)
+	}
+	names := []string{"localhost"}
+	if hostname, err := os.Hostname(); err == nil && hostname != "" && hostname != "localhost" {
+		names = append(names, hostname)
+	}
+	now := time.Now()
+	template := &x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{CommonName: "conduit"},
+		NotBefore:             now.Add(-time.Hour),
+		NotAfter:              now.Add(selfSignedValidity),
+		KeyUsage:              x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+		DNSNames:              names,
+		IPAddresses:           []net.IP{net.IPv4(127, 0, 0, 1), net.IPv6loopback},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate TLS certificate: %w", err)
+	}
+	keyDER, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode TLS key: %w", err)
+	}
+
+	if err := config.WriteFileAtomic(keyPath, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDER}), 0600); err != nil {
+		return "", fmt.Errorf("failed to write TLS key: %w", err)
+	}
+	if err := config.WriteFileAtomic(certPath, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0644); err != nil {
+		return "", fmt.Errorf("failed to write TLS certificate: %w", err)
+	}
+	return fingerprint(der), nil
+}
+
+func fingerprint(der []byte) string {
+	sum := sha256.Sum256(der)
+	return hex.EncodeToString(sum[:])
+}
+
+// Loopback reports whether a listen address only accepts local connections
+func Loopback(addr string) bool {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return false
+	}
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// Server is an HTTP server protected by Options
+type Server struct {
+	server *http.Server
+}
+
+// Start validates opts and serves handler on addr in the background. name
+// is used in log messages, e.g. "Metrics".
+func Start(name, addr string, handler http.Handler, opts Options) (*Server, error) {
+	if err := opts.Validate(addr); err != nil {
+		return nil, err
+	}
+
+	s := &Server{server: &http.Server{
+		Addr:              addr,
+		Handler:           opts.protect(handler),
+		ReadHeaderTimeout: 10 * time.Second,
+	}}
+	if opts.TLSSelfSigned {
+		fingerprint, err := SelfSignedCertificate(opts.TLSCert, opts.TLSKey)
+		if err != nil {
+			return nil, err
+		}
+		fmt.Printf("[INFO] %s TLS certificate SHA-256 fingerprint: %s\n", name, fingerprint)
+	}
+	if opts.TLS() {
+		tlsConfig, err := opts.tlsConfig()
+		if err != nil {
+			return nil, err
+		}
+		s.server.TLSConfig = tlsConfig
+	}
+
+	// Create a listener to verify the port is available before starting the server
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to bind to %s: %w", addr, err)
+	}
+	if s.server.TLSConfig != nil {
+		listener = tls.NewListener(listener, s.server.TLSConfig)
+	}
+
+	go func() {
+		if err := s.server.Serve(listener); err != nil && err != http.ErrServerClosed {
+			fmt.Printf("[ERROR] %s server error: %v\n", name, err)
+		}
+	}()
+	return s, nil
+}
+
+// Shutdown gracefully shuts down the server
+func (s *Server) Shutdown(ctx context.Context) error {
+	return s.server.Shutdown(ctx)
+}
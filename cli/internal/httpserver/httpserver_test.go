@@ -0,0 +1,242 @@
+/*
+ * Copyright (c) 2026, Psiphon Inc.
+ * All rights reserved.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package httpserver
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestOptionsValidate(t *testing.T) {
+	tests := []struct {
+		name  string
+		opts  Options
+		addr  string
+		valid bool
+	}{
+		{"plain", Options{}, ":9090", true},
+		{"tls", Options{TLSCert: "c", TLSKey: "k"}, ":9090", true},
+		{"cert without key", Options{TLSCert: "c"}, ":9090", false},
+		{"mtls", Options{TLSCert: "c", TLSKey: "k", ClientCA: "ca"}, ":9090", true},
+		{"mtls without tls", Options{ClientCA: "ca"}, ":9090", false},
+		{"basic over tls", Options{TLSCert: "c", TLSKey: "k", Username: "u", Password: "p"}, ":9090", true},
+		{"basic on loopback", Options{Username: "u", Password: "p"}, "127.0.0.1:9090", true},
+		{"basic in plaintext", Options{Username: "u", Password: "p"}, ":9090", false},
+		{"token in plaintext", Options{BearerToken: "t"}, "192.0.2.1:9090", false},
+		{"token on loopback", Options{BearerToken: "t"}, "[::1]:9090", true},
+		{"basic without password", Options{Username: "u"}, "127.0.0.1:9090", false},
+		{"token and basic", Options{BearerToken: "t", Username: "u", Password: "p"}, "127.0.0.1:9090", false},
+	}
+	for _, tt := range tests {
+		if err := tt.opts.Validate(tt.addr); (err == nil) != tt.valid {
+			t.Fatalf("%s: Validate() = %v, expected valid=%v", tt.name, err, tt.valid)
+		}
+	}
+}
+
+func TestProtect(t *testing.T) {
+	allow, err := ParseAllow([]string{"10.0.0.0/8", "192.168.1.5", "fd00::/8"})
+	if err != nil {
+		t.Fatalf("ParseAllow: %v", err)
+	}
+	if _, err := ParseAllow([]string{"10.0.0.0/33"}); err == nil {
+		t.Fatalf("expected an invalid CIDR to fail")
+	}
+
+	tests := []struct {
+		name       string
+		opts       Options
+		remoteAddr string
+		setup      func(r *http.Request)
+		status     int
+	}{
+		{"open", Options{}, "203.0.113.1:1234", nil, http.StatusOK},
+		{"allowed cidr", Options{Allow: allow}, "10.1.2.3:1234", nil, http.StatusOK},
+		{"allowed address", Options{Allow: allow}, "192.168.1.5:1234", nil, http.StatusOK},
+		{"allowed ipv6", Options{Allow: allow}, "[fd00::1]:1234", nil, http.StatusOK},
+		{"mapped ipv4", Options{Allow: allow}, "[::ffff:10.1.2.3]:1234", nil, http.StatusOK},
+		{"denied address", Options{Allow: allow}, "192.168.1.6:1234", nil, http.StatusForbidden},
+		{"token", Options{BearerToken: "secret"}, "203.0.113.1:1234",
+			func(r *http.Request) { r.Header.Set("Authorization", "Bearer secret") }, http.StatusOK},
+		{"wrong token", Options{BearerToken: "secret"}, "203.0.113.1:1234",
+			func(r *http.Request) { r.Header.Set("Authorization", "Bearer nope") }, http.StatusUnauthorized},
+		{"missing token", Options{BearerToken: "secret"}, "203.0.113.1:1234", nil, http.StatusUnauthorized},
+		{"basic", Options{Username: "prom", Password: "pw"}, "203.0.113.1:1234",
+			func(r *http.Request) { r.SetBasicAuth("prom", "pw") }, http.StatusOK},
+		{"wrong password", Options{Username: "prom", Password: "pw"}, "203.0.113.1:1234",
+			func(r *http.Request) { r.SetBasicAuth("prom", "nope") }, http.StatusUnauthorized},
+		{"allowlist before auth", Options{Allow: allow, BearerToken: "secret"}, "203.0.113.1:1234",
+			func(r *http.Request) { r.Header.Set("Authorization", "Bearer secret") }, http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		handler := tt.opts.protect(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+		r := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+		r.RemoteAddr = tt.remoteAddr
+		if tt.setup != nil {
+			tt.setup(r)
+		}
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		if w.Code != tt.status {
+			t.Fatalf("%s: status %d, expected %d", tt.name, w.Code, tt.status)
+		}
+		if w.Code == http.StatusUnauthorized && w.Header().Get("WWW-Authenticate") == "" {
+			t.Fatalf("%s: missing WWW-Authenticate", tt.name)
+		}
+	}
+}
+
+func TestSelfSignedCertificate(t *testing.T) {
+	dir := t.TempDir()
+	cert, key := filepath.Join(dir, "tls.crt"), filepath.Join(dir, "tls.key")
+
+	first, err := SelfSignedCertificate(cert, key)
+	if err != nil {
+		t.Fatalf("SelfSignedCertificate: %v", err)
+	}
+	if info, err := os.Stat(key); err != nil || info.Mode().Perm() != 0600 {
+		t.Fatalf("key file mode = %v, %v", info, err)
+	}
+
+	// Kept across restarts, so scrapers can pin it
+	second, err := SelfSignedCertificate(cert, key)
+	if err != nil || second != first {
+		t.Fatalf("second fingerprint = %s, %v, expected %s", second, err, first)
+	}
+
+	// Replaced when unreadable
+	os.WriteFile(cert, []byte("garbage"), 0644)
+	third, err := SelfSignedCertificate(cert, key)
+	if err != nil || third == first {
+		t.Fatalf("regenerated fingerprint = %s, %v", third, err)
+	}
+	if _, err := tls.LoadX509KeyPair(cert, key); err != nil {
+		t.Fatalf("LoadX509KeyPair: %v", err)
+	}
+
+	// Replaced when the key doesn't match, as after a crash between writes,
+	// and the key's mode is tightened
+	other := t.TempDir()
+	if _, err := SelfSignedCertificate(filepath.Join(other, "tls.crt"), filepath.Join(other, "tls.key")); err != nil {
+		t.Fatalf("SelfSignedCertificate: %v", err)
+	}
+	otherKey, _ := os.ReadFile(filepath.Join(other, "tls.key"))
+	os.WriteFile(key, otherKey, 0644)
+	fourth, err := SelfSignedCertificate(cert, key)
+	if err != nil || fourth == third {
+		t.Fatalf("fingerprint after key mismatch = %s, %v", fourth, err)
+	}
+	if _, err := tls.LoadX509KeyPair(cert, key); err != nil {
+		t.Fatalf("LoadX509KeyPair: %v", err)
+	}
+	if info, err := os.Stat(key); err != nil || info.Mode().Perm() != 0600 {
+		t.Fatalf("key file mode = %v, %v", info, err)
+	}
+}
+
+// writeCA writes a CA certificate and returns a client certificate signed by it
+func writeCA(t *testing.T, path string) tls.Certificate {
+	caKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	ca := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	caDER, err := x509.CreateCertificate(rand.Reader, ca, ca, &caKey.PublicKey, caKey)
+	if err != nil {
+		t.Fatalf("create CA: %v", err)
+	}
+	if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: caDER}), 0644); err != nil {
+		t.Fatalf("write CA: %v", err)
+	}
+	ca, _ = x509.ParseCertificate(caDER)
+
+	clientKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	client := &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: "prometheus"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	clientDER, err := x509.CreateCertificate(rand.Reader, client, ca, &clientKey.PublicKey, caKey)
+	if err != nil {
+		t.Fatalf("create client certificate: %v", err)
+	}
+	return tls.Certificate{Certificate: [][]byte{clientDER}, PrivateKey: clientKey}
+}
+
+func TestServerTLS(t *testing.T) {
+	dir := t.TempDir()
+	opts := Options{
+		TLSCert:       filepath.Join(dir, "tls.crt"),
+		TLSKey:        filepath.Join(dir, "tls.key"),
+		TLSSelfSigned: true,
+		ClientCA:      filepath.Join(dir, "ca.crt"),
+	}
+	clientCert := writeCA(t, opts.ClientCA)
+	if _, err := SelfSignedCertificate(opts.TLSCert, opts.TLSKey); err != nil {
+		t.Fatalf("SelfSignedCertificate: %v", err)
+	}
+	tlsConfig, err := opts.tlsConfig()
+	if err != nil {
+		t.Fatalf("tlsConfig: %v", err)
+	}
+
+	server := httptest.NewUnstartedServer(opts.protect(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})))
+	server.TLS = tlsConfig
+	server.StartTLS()
+	defer server.Close()
+
+	get := func(certs ...tls.Certificate) error {
+		client := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{
+			InsecureSkipVerify: true,
+			Certificates:       certs,
+		}}}
+		resp, err := client.Get(server.URL + "/metrics")
+		if err == nil {
+			resp.Body.Close()
+		}
+		return err
+	}
+	if err := get(clientCert); err != nil {
+		t.Fatalf("request with client certificate: %v", err)
+	}
+	if err := get(); err == nil {
+		t.Fatalf("request without client certificate succeeded")
+	}
+}
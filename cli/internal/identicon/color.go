@@ -0,0 +1,166 @@
+/*
+ * Copyright (c) 2026, Psiphon Inc.
+ * All rights reserved.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package identicon
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+)
+
+// colorTheme returns the dark gray, mid color, light gray, light color and
+// dark color of an icon
+func colorTheme(hue float64) []string {
+	hue = hueFunction(hue)
+	return []string{
+		correctedHsl(hue, grayscaleSaturation, grayscaleLightness(0)),
+		correctedHsl(hue, colorSaturation, colorLightness(0.5)),
+		correctedHsl(hue, grayscaleSaturation, grayscaleLightness(1)),
+		correctedHsl(hue, colorSaturation, colorLightness(1)),
+		correctedHsl(hue, colorSaturation, colorLightness(0)),
+	}
+}
+
+// hueFunction restricts a hue in [0, 1] to one of the palette hues, returned
+// in turns
+func hueFunction(originalHue float64) float64 {
+	hue := hues[int(trunc(0.999*originalHue*float64(len(hues))))]
+	return math.Mod(math.Mod(hue/360, 1)+1, 1)
+}
+
+func lightness(lo, hi float64) func(float64) float64 {
+	return func(value float64) float64 {
+		value = lo + value*(hi-lo)
+		return math.Min(math.Max(value, 0), 1)
+	}
+}
+
+var (
+	colorLightness     = lightness(0.4, 0.8)
+	grayscaleLightness = lightness(0.15, 0.9)
+)
+
+func correctedHsl(hue, saturation, lightness float64) string {
+	// The corrector specifies the perceived middle lightness for each hue
+	correctors := []float64{0.55, 0.5, 0.5, 0.46, 0.6, 0.55, 0.55}
+	corrector := correctors[int(trunc(hue*6+0.5))]
+
+	// Adjust the input lightness relative to the corrector
+	if lightness < 0.5 {
+		lightness = lightness * corrector * 2
+	} else {
+		lightness = corrector + (lightness-0.5)*(1-corrector)*2
+	}
+	return hsl(hue, saturation, lightness)
+}
+
+func hsl(hue, saturation, lightness float64) string {
+	if saturation == 0 {
+		partial := decToHex(lightness * 255)
+		return "#" + partial + partial + partial
+	}
+	var m2 float64
+	if lightness <= 0.5 {
+		m2 = lightness * (saturation + 1)
+	} else {
+		m2 = lightness + saturation - lightness*saturation
+	}
+	m1 := lightness*2 - m2
+	return "#" + hueToRgb(m1, m2, hue*6+2) + hueToRgb(m1, m2, hue*6) + hueToRgb(m1, m2, hue*6-2)
+}
+
+func hueToRgb(m1, m2, h float64) string {
+	if h < 0 {
+		h += 6
+	} else if h > 6 {
+		h -= 6
+	}
+	var v float64
+	switch {
+	case h < 1:
+		v = m1 + (m2-m1)*h
+	case h < 3:
+		v = m2
+	case h < 4:
+		v = m1 + (m2-m1)*(4-h)
+	default:
+		v = m1
+	}
+	return decToHex(255 * v)
+}
+
+func decToHex(f float64) string {
+	v := int(trunc(f))
+	switch {
+	case v < 0:
+		return "00"
+	case v < 256:
+		return fmt.Sprintf("%02x", v)
+	default:
+		return "ff"
+	}
+}
+
+// hexToHueDegrees returns the hue of a #rrggbb color in whole degrees
+func hexToHueDegrees(color string) float64 {
+	rgb, err := parseColor(color)
+	if err != nil {
+		return 0
+	}
+	r, g, b := float64(rgb[0])/255, float64(rgb[1])/255, float64(rgb[2])/255
+	hi := math.Max(r, math.Max(g, b))
+	lo := math.Min(r, math.Min(g, b))
+
+	// "Defining hue in terms of RGB" from wikipedia
+	var h float64
+	if hi != lo {
+		d := hi - lo
+		switch hi {
+		case r:
+			h = (g - b) / d
+			if g < b {
+				h += 6
+			}
+		case g:
+			h = (b-r)/d + 2
+		case b:
+			h = (r-g)/d + 4
+		}
+		h /= 6
+	}
+	// JavaScript's Math.round rounds halves up
+	return math.Floor(360*h + 0.5)
+}
+
+// parseColor parses a #rrggbb color
+func parseColor(color string) ([3]uint8, error) {
+	var rgb [3]uint8
+	if len(color) != 7 || color[0] != '#' {
+		return rgb, fmt.Errorf("invalid color %q", color)
+	}
+	for i := range rgb {
+		v, err := strconv.ParseUint(color[1+2*i:3+2*i], 16, 8)
+		if err != nil {
+			return rgb, fmt.Errorf("invalid color %q", color)
+		}
+		rgb[i] = uint8(v)
+	}
+	return rgb, nil
+}
@@ -0,0 +1,376 @@
+/*
+ * Copyright (c) 2026, Psiphon Inc.
+ * All rights reserved.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+// Package identicon renders station identicons, ported from the mobile app
+// (src/common/identicon.ts, a jdenticon derivative) so that CLI and app
+// stations with the same proxy ID look the same.
+package identicon
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"math"
+	"strconv"
+)
+
+// Palette colors used by the app for the identicon hues and the badge
+// background gradient (src/styles.ts)
+const (
+	colorRed          = "#d54028"
+	colorRedShade1    = "#bf3924"
+	colorRedShade2    = "#952c1c"
+	colorBlue         = "#3b7a96"
+	colorBlueShade1   = "#356d87"
+	colorBlueShade2   = "#2f6178"
+	colorPurple       = "#4E3677"
+	colorPurpleShade1 = "#533b5a"
+	colorPurpleShade2 = "#412e46"
+
+	ColorPeach = "#f5a086"
+	ColorMauve = "#9d81c9"
+)
+
+var hues = []float64{
+	hexToHueDegrees(colorRed),
+	hexToHueDegrees(colorBlue),
+	hexToHueDegrees(colorPurple),
+	hexToHueDegrees(colorRedShade1),
+	hexToHueDegrees(colorBlueShade1),
+	hexToHueDegrees(colorPurpleShade1),
+	hexToHueDegrees(colorRedShade2),
+	hexToHueDegrees(colorBlueShade2),
+	hexToHueDegrees(colorPurpleShade2),
+}
+
+const (
+	colorSaturation     = 0.5
+	grayscaleSaturation = 0.5
+	iconPadding         = 0.08
+)
+
+// Point is a position in icon coordinates
+type Point struct {
+	X, Y float64
+}
+
+// Shape is a polygon or a circle. Inverted shapes wind the other way, so they
+// cut holes in the shapes of the same color (nonzero fill rule).
+type Shape struct {
+	Points []Point // Polygon vertices, nil for a circle
+
+	Center   Point // Top left of the circle's bounding box
+	Diameter float64
+	Inverted bool // Circles only: drawn counterclockwise
+}
+
+// Path is all shapes of one color
+type Path struct {
+	Color  string
+	Shapes []Shape
+}
+
+// Icon is a rendered identicon
+type Icon struct {
+	Size  int
+	Paths []Path
+}
+
+// New renders the identicon of value (the app uses the proxy ID) at size x size
+func New(value string, size int) *Icon {
+	sum := sha1.Sum([]byte(value))
+	icon := &Icon{Size: size}
+	generate(icon, hex.EncodeToString(sum[:]))
+	return icon
+}
+
+// path returns the path for color, keeping paths in first-use order like the
+// app's SVG renderer
+func (icon *Icon) path(color string) *Path {
+	for i := range icon.Paths {
+		if icon.Paths[i].Color == color {
+			return &icon.Paths[i]
+		}
+	}
+	icon.Paths = append(icon.Paths, Path{Color: color})
+	return &icon.Paths[len(icon.Paths)-1]
+}
+
+// transform positions and rotates a shape within its cell
+type transform struct {
+	x, y, size float64
+	rotation   int
+}
+
+func (t transform) point(x, y, w, h float64) Point {
+	right := t.x + t.size
+	bottom := t.y + t.size
+	switch t.rotation {
+	case 1:
+		return Point{right - y - h, t.y + x}
+	case 2:
+		return Point{right - x - w, bottom - y - h}
+	case 3:
+		return Point{t.x + y, bottom - x - w}
+	default:
+		return Point{t.x + x, t.y + y}
+	}
+}
+
+// graphics adds transformed shapes to the current path
+type graphics struct {
+	path      *Path
+	transform transform
+}
+
+func (g *graphics) addPolygon(points []float64, invert bool) {
+	var transformed []Point
+	if invert {
+		for i := len(points) - 2; i >= 0; i -= 2 {
+			transformed = append(transformed, g.transform.point(points[i], points[i+1], 0, 0))
+		}
+	} else {
+		for i := 0; i < len(points); i += 2 {
+			transformed = append(transformed, g.transform.point(points[i], points[i+1], 0, 0))
+		}
+	}
+	g.path.Shapes = append(g.path.Shapes, Shape{Points: transformed})
+}
+
+func (g *graphics) addCircle(x, y, size float64, invert bool) {
+	g.path.Shapes = append(g.path.Shapes, Shape{
+		Center:   g.transform.point(x, y, size, size),
+		Diameter: size,
+		Inverted: invert,
+	})
+}
+
+func (g *graphics) addRectangle(x, y, w, h float64, invert bool) {
+	g.addPolygon([]float64{x, y, x + w, y, x + w, y + h, x, y + h}, invert)
+}
+
+// addTriangle adds a right triangle; r selects which corner of the box is cut
+func (g *graphics) addTriangle(x, y, w, h float64, r int, invert bool) {
+	points := []float64{x + w, y, x + w, y + h, x, y + h, x, y}
+	i := (r % 4) * 2
+	points = append(points[:i], points[i+2:]...)
+	g.addPolygon(points, invert)
+}
+
+func (g *graphics) addRhombus(x, y, w, h float64, invert bool) {
+	g.addPolygon([]float64{x + w/2, y, x + w, y + h/2, x + w/2, y + h, x, y + h/2}, invert)
+}
+
+// trunc mirrors JavaScript's "0 | x", including NaN becoming 0
+func trunc(x float64) float64 {
+	if math.IsNaN(x) || math.IsInf(x, 0) {
+		return 0
+	}
+	return float64(int32(x))
+}
+
+func centerShape(index int, g *graphics, cell float64, positionIndex int) {
+	switch index % 14 {
+	case 0:
+		k := cell * 0.42
+		g.addPolygon([]float64{0, 0, cell, 0, cell, cell - k*2, cell - k, cell, 0, cell}, false)
+	case 1:
+		w := trunc(cell * 0.5)
+		h := trunc(cell * 0.8)
+		g.addTriangle(cell-w, 0, w, h, 2, false)
+	case 2:
+		w := trunc(cell / 3)
+		g.addRectangle(w, w, cell-w, cell-w, false)
+	case 3:
+		inner := cell * 0.1
+		// Use fixed outer border widths in small icons to ensure the border is drawn
+		var outer float64
+		switch {
+		case cell < 6:
+			outer = 1
+		case cell < 8:
+			outer = 2
+		default:
+			outer = trunc(cell * 0.25)
+		}
+		if inner > 1 {
+			inner = trunc(inner)
+		} else if inner > 0.5 {
+			inner = 1
+		}
+		g.addRectangle(outer, outer, cell-inner-outer, cell-inner-outer, false)
+	case 4:
+		m := trunc(cell * 0.15)
+		w := trunc(cell * 0.5)
+		g.addCircle(cell-w-m, cell-w-m, w, false)
+	case 5:
+		inner := cell * 0.1
+		outer := inner * 4
+		// Align edge to nearest pixel in large icons
+		if outer > 3 {
+			outer = trunc(outer)
+		}
+		g.addRectangle(0, 0, cell, cell, false)
+		g.addPolygon([]float64{outer, outer, cell - inner, outer, outer + (cell-outer-inner)/2, cell - inner}, true)
+	case 6:
+		g.addPolygon([]float64{0, 0, cell, 0, cell, cell * 0.7, cell * 0.4, cell * 0.4, cell * 0.7, cell, 0, cell}, false)
+	case 7:
+		g.addTriangle(cell/2, cell/2, cell/2, cell/2, 3, false)
+	case 8:
+		g.addRectangle(0, 0, cell, cell/2, false)
+		g.addRectangle(0, cell/2, cell/2, cell/2, false)
+		g.addTriangle(cell/2, cell/2, cell/2, cell/2, 1, false)
+	case 9:
+		inner := cell * 0.14
+		// Use fixed outer border widths in small icons to ensure the border is drawn
+		var outer float64
+		switch {
+		case cell < 4:
+			outer = 1
+		case cell < 6:
+			outer = 2
+		default:
+			outer = trunc(cell * 0.35)
+		}
+		if cell >= 8 {
+			inner = trunc(inner)
+		}
+		g.addRectangle(0, 0, cell, cell, false)
+		g.addRectangle(outer, outer, cell-outer-inner, cell-outer-inner, true)
+	case 10:
+		inner := cell * 0.12
+		outer := inner * 3
+		g.addRectangle(0, 0, cell, cell, false)
+		g.addCircle(outer, outer, cell-inner-outer, true)
+	case 11:
+		g.addTriangle(cell/2, cell/2, cell/2, cell/2, 3, false)
+	case 12:
+		m := cell * 0.25
+		g.addRectangle(0, 0, cell, cell, false)
+		g.addRhombus(m, m, cell-m, cell-m, true)
+	case 13:
+		if positionIndex == 0 {
+			m := cell * 0.4
+			w := cell * 1.2
+			g.addCircle(m, m, w, false)
+		}
+	}
+}
+
+func outerShape(index int, g *graphics, cell float64, _ int) {
+	switch index % 4 {
+	case 0:
+		g.addTriangle(0, 0, cell, cell, 0, false)
+	case 1:
+		g.addTriangle(0, cell/2, cell, cell/2, 0, false)
+	case 2:
+		g.addRhombus(0, 0, cell, cell, false)
+	case 3:
+		m := cell / 6
+		g.addCircle(m, m, cell-2*m, false)
+	}
+}
+
+// parseHex parses octets hex digits of hash at start, like the app's
+// parseHex: an empty substring yields NaN.
+func parseHex(hash string, start, octets int) float64 {
+	end := start + octets
+	// JavaScript's substring clamps negative indexes to 0
+	start = max(start, 0)
+	end = min(max(end, 0), len(hash))
+	if start >= end {
+		return math.NaN()
+	}
+	v, err := strconv.ParseUint(hash[start:end], 16, 64)
+	if err != nil {
+		return math.NaN()
+	}
+	return float64(v)
+}
+
+func generate(icon *Icon, hash string) {
+	// Calculate padding and round to nearest integer
+	size := float64(icon.Size)
+	padding := trunc(0.5 + size*iconPadding)
+	size -= padding * 2
+
+	// Calculate cell size and ensure it is an integer
+	cell := trunc(size / 4)
+
+	// Since the cell size is integer based, the actual icon will be slightly
+	// smaller than specified => center icon
+	x := trunc(padding + size/2 - cell*2)
+	y := trunc(padding + size/2 - cell*2)
+
+	// The app reads the hue from parseHex(hash, -7), which is always NaN and
+	// so always selects the first hue. Kept as is so icons match the app.
+	hue := parseHex(hash, -7, 1) / 0xfffffff
+	availableColors := colorTheme(hue)
+
+	var selectedColorIndexes []int
+	isDuplicate := func(index int, values ...int) bool {
+		found := false
+		for _, v := range values {
+			if v == index {
+				found = true
+			}
+		}
+		if !found {
+			return false
+		}
+		for _, v := range values {
+			for _, selected := range selectedColorIndexes {
+				if v == selected {
+					return true
+				}
+			}
+		}
+		return false
+	}
+	for i := 0; i < 3; i++ {
+		index := int(parseHex(hash, 8+i, 1)) % len(availableColors)
+		// Disallow dark gray and dark color combo, and light gray and light color combo
+		if isDuplicate(index, 0, 4) || isDuplicate(index, 2, 3) {
+			index = 1
+		}
+		selectedColorIndexes = append(selectedColorIndexes, index)
+	}
+
+	g := &graphics{}
+	renderShape := func(colorIndex int, shapes func(int, *graphics, float64, int), index, rotationIndex int, positions [][2]float64) {
+		shapeIndex := int(parseHex(hash, index, 1))
+		r := 0
+		if rotationIndex != 0 {
+			r = int(parseHex(hash, rotationIndex, 1))
+		}
+
+		g.path = icon.path(availableColors[selectedColorIndexes[colorIndex]])
+		for i, pos := range positions {
+			g.transform = transform{x + pos[0]*cell, y + pos[1]*cell, cell, r % 4}
+			r++
+			shapes(shapeIndex, g, cell, i)
+		}
+	}
+
+	// Sides
+	renderShape(0, outerShape, 2, 3, [][2]float64{{1, 0}, {2, 0}, {2, 3}, {1, 3}, {0, 1}, {3, 1}, {3, 2}, {0, 2}})
+	// Corners
+	renderShape(1, outerShape, 4, 5, [][2]float64{{0, 0}, {3, 0}, {3, 3}, {0, 3}})
+	// Center
+	renderShape(2, centerShape, 1, 0, [][2]float64{{1, 1}, {2, 1}, {2, 2}, {1, 2}})
+}
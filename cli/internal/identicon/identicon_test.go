@@ -0,0 +1,71 @@
+/*
+ * Copyright (c) 2026, Psiphon Inc.
+ * All rights reserved.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package identicon
+
+import (
+	"strings"
+	"testing"
+)
+
+// Expected SVGs were generated with the app's identicon() in src/common/identicon.ts
+func TestSVGMatchesApp(t *testing.T) {
+	tests := []struct {
+		value string
+		size  int
+		svg   string
+	}{
+		{
+			"DRXjR0TA91PMSr0Ve+/NPV1nkUbVdpgke0+dVKgmscA", 22,
+			`<svg xmlns="http://www.w3.org/2000/svg" width="22" height="22" viewBox="0 0 22 22"><path fill="#d18175" d="M11 3L11 7L7 7ZM15 7L11 7L11 3ZM11 19L11 15L15 15ZM7 15L11 15L11 19ZM7 7L7 11L3 11ZM19 11L15 11L15 7ZM15 15L15 11L19 11ZM3 11L7 11L7 15ZM5 3L7 5L5 7L3 5ZM19 5L17 7L15 5L17 3ZM17 19L15 17L17 15L19 17ZM3 17L5 15L7 17L5 19Z"/><path fill="#f3dfdc" d="M7 7L11 7L11 11L7 11ZM8.4 9.5a1,1 0 1,0 2.1,0a1,1 0 1,0 -2.1,0M15 7L15 11L11 11L11 7ZM11.5 9.5a1,1 0 1,0 2.1,0a1,1 0 1,0 -2.1,0M15 15L11 15L11 11L15 11ZM11.5 12.5a1,1 0 1,0 2.1,0a1,1 0 1,0 -2.1,0M7 15L7 11L11 11L11 15ZM8.4 12.5a1,1 0 1,0 2.1,0a1,1 0 1,0 -2.1,0"/></svg>`,
+		},
+		{
+			"zasLRmzRbK3/F4/s0NZwxlme7cc0nqiBUOKAQXzdZR8", 99,
+			`<svg xmlns="http://www.w3.org/2000/svg" width="99" height="99" viewBox="0 0 99 99"><path fill="#d18175" d="M29 9L49 9L49 29ZM69 9L69 29L49 29ZM69 89L49 89L49 69ZM29 89L29 69L49 69ZM9 29L29 29L29 49ZM89 29L89 49L69 49ZM89 69L69 69L69 49ZM9 69L9 49L29 49ZM37 49a12,12 0 1,1 24,0a12,12 0 1,1 -24,0"/><path fill="#3f1a15" d="M29 9L29 29L9 29ZM89 29L69 29L69 9ZM69 89L69 69L89 69ZM9 69L29 69L29 89Z"/></svg>`,
+		},
+		{
+			"klpHysjURumd4MyWeX1cejJI6FtOI45tp/XduK1mlZM", 31,
+			`<svg xmlns="http://www.w3.org/2000/svg" width="31" height="31" viewBox="0 0 31 31"><path fill="#d18175" d="M9 9L9 3L12 3ZM15 3L21 3L21 6ZM21 21L21 27L18 27ZM15 27L9 27L9 24ZM3 15L3 9L6 9ZM21 9L27 9L27 12ZM27 15L27 21L24 21ZM9 21L3 21L3 18ZM9 3L9 9L6 9ZM27 9L21 9L21 6ZM21 27L21 21L24 21ZM3 21L9 21L9 24ZM15 12L15 15L12 15ZM18 15L15 15L15 12ZM15 18L15 15L18 15ZM12 15L15 15L15 18Z"/></svg>`,
+		},
+	}
+
+	for _, tt := range tests {
+		if got := New(tt.value, tt.size).SVG(); got != tt.svg {
+			t.Fatalf("SVG(%s, %d) =\n%s\nexpected\n%s", tt.value, tt.size, got, tt.svg)
+		}
+	}
+}
+
+func TestBadge(t *testing.T) {
+	const value = "zasLRmzRbK3/F4/s0NZwxlme7cc0nqiBUOKAQXzdZR8"
+	img := Badge(value, 64)
+
+	// Corners are outside the circle, the center is covered
+	if img.RGBAAt(0, 0).A != 0 {
+		t.Fatalf("corner should be transparent")
+	}
+	if img.RGBAAt(32, 32).A != 255 {
+		t.Fatalf("center should be opaque")
+	}
+
+	lines := strings.Split(strings.TrimSuffix(Terminal(value, 16), "\n"), "\n")
+	if len(lines) != 8 {
+		t.Fatalf("Terminal rendered %d lines, expected 8", len(lines))
+	}
+}
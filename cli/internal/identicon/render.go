@@ -0,0 +1,248 @@
+/*
+ * Copyright (c) 2026, Psiphon Inc.
+ * All rights reserved.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package identicon
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// svgRound rounds to one decimal like the app's SVG renderer
+func svgRound(v float64) float64 {
+	return trunc(v*10+0.5) / 10
+}
+
+// svgNumber formats like JavaScript, which prints -0 as "0"
+func svgNumber(v float64) string {
+	return strconv.FormatFloat(v+0, 'f', -1, 64)
+}
+
+func svgValue(v float64) string {
+	return svgNumber(svgRound(v))
+}
+
+// SVG returns the icon as the same SVG document the app renders
+func (icon *Icon) SVG() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d">`,
+		icon.Size, icon.Size, icon.Size, icon.Size)
+	for _, path := range icon.Paths {
+		fmt.Fprintf(&b, `<path fill="%s" d="`, path.Color)
+		for _, shape := range path.Shapes {
+			if shape.Points != nil {
+				for i, p := range shape.Points {
+					if i == 0 {
+						b.WriteString("M")
+					} else {
+						b.WriteString("L")
+					}
+					b.WriteString(svgValue(p.X) + " " + svgValue(p.Y))
+				}
+				b.WriteString("Z")
+				continue
+			}
+			sweep := "1"
+			if shape.Inverted {
+				sweep = "0"
+			}
+			radius := svgValue(shape.Diameter / 2)
+			diameter := svgRound(shape.Diameter)
+			arc := "a" + radius + "," + radius + " 0 1," + sweep + " "
+			fmt.Fprintf(&b, "M%s %s%s%s,0%s%s,0",
+				svgValue(shape.Center.X), svgValue(shape.Center.Y+shape.Diameter/2),
+				arc, svgNumber(diameter), arc, svgNumber(-diameter))
+		}
+		b.WriteString(`"/>`)
+	}
+	b.WriteString("</svg>")
+	return b.String()
+}
+
+// circleSegments is the number of polygon edges approximating a circle when
+// rasterizing
+const circleSegments = 64
+
+// polygons returns the shapes of a path as polygons, with circles
+// approximated. Winding direction is preserved.
+func (p *Path) polygons() [][]Point {
+	polygons := make([][]Point, 0, len(p.Shapes))
+	for _, shape := range p.Shapes {
+		if shape.Points != nil {
+			polygons = append(polygons, shape.Points)
+			continue
+		}
+		r := shape.Diameter / 2
+		cx, cy := shape.Center.X+r, shape.Center.Y+r
+		circle := make([]Point, circleSegments)
+		for i := range circle {
+			a := 2 * math.Pi * float64(i) / circleSegments
+			if shape.Inverted {
+				a = -a
+			}
+			circle[i] = Point{cx + r*math.Cos(a), cy + r*math.Sin(a)}
+		}
+		polygons = append(polygons, circle)
+	}
+	return polygons
+}
+
+// winding returns the nonzero-rule winding number of polygons at (x, y)
+func winding(polygons [][]Point, x, y float64) int {
+	n := 0
+	for _, poly := range polygons {
+		for i := range poly {
+			a, b := poly[i], poly[(i+1)%len(poly)]
+			cross := (b.X-a.X)*(y-a.Y) - (x-a.X)*(b.Y-a.Y)
+			if a.Y <= y {
+				if b.Y > y && cross > 0 {
+					n++
+				}
+			} else if b.Y <= y && cross < 0 {
+				n--
+			}
+		}
+	}
+	return n
+}
+
+// samples is the supersampling grid size per pixel when rasterizing
+const samples = 4
+
+// Draw paints the icon over dst, offset by (x, y)
+func (icon *Icon) Draw(dst *image.RGBA, x, y int) {
+	for _, path := range icon.Paths {
+		rgb, err := parseColor(path.Color)
+		if err != nil {
+			continue
+		}
+		polygons := path.polygons()
+		for py := 0; py < icon.Size; py++ {
+			for px := 0; px < icon.Size; px++ {
+				covered := 0
+				for sy := 0; sy < samples; sy++ {
+					for sx := 0; sx < samples; sx++ {
+						fx := float64(px) + (float64(sx)+0.5)/samples
+						fy := float64(py) + (float64(sy)+0.5)/samples
+						if winding(polygons, fx, fy) != 0 {
+							covered++
+						}
+					}
+				}
+				if covered > 0 {
+					blend(dst, x+px, y+py, rgb, float64(covered)/(samples*samples))
+				}
+			}
+		}
+	}
+}
+
+// blend composites an opaque color with coverage alpha over dst at (x, y)
+func blend(dst *image.RGBA, x, y int, rgb [3]uint8, alpha float64) {
+	if !(image.Point{x, y}.In(dst.Bounds())) {
+		return
+	}
+	bg := dst.RGBAAt(x, y)
+	mix := func(fg, bg uint8) uint8 {
+		return uint8(float64(fg)*alpha + float64(bg)*(1-alpha) + 0.5)
+	}
+	dst.SetRGBA(x, y, color.RGBA{
+		R: mix(rgb[0], bg.R),
+		G: mix(rgb[1], bg.G),
+		B: mix(rgb[2], bg.B),
+		A: uint8(float64(bg.A)*(1-alpha) + 255*alpha + 0.5),
+	})
+}
+
+// Badge renders value like the app's proxy ID badge: the identicon at 80% on
+// a peach to mauve gradient circle, size x size pixels
+func Badge(value string, size int) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, size, size))
+	peach, _ := parseColor(ColorPeach)
+	mauve, _ := parseColor(ColorMauve)
+
+	// Gradient from bottom left (peach) to top right (mauve)
+	r := float64(size) / 2
+	for py := 0; py < size; py++ {
+		for px := 0; px < size; px++ {
+			covered := 0
+			for sy := 0; sy < samples; sy++ {
+				for sx := 0; sx < samples; sx++ {
+					dx := float64(px) + (float64(sx)+0.5)/samples - r
+					dy := float64(py) + (float64(sy)+0.5)/samples - r
+					if dx*dx+dy*dy <= r*r {
+						covered++
+					}
+				}
+			}
+			if covered == 0 {
+				continue
+			}
+			t := (float64(px) + float64(size-1-py)) / float64(2*max(size-1, 1))
+			var c [3]uint8
+			for i := range c {
+				c[i] = uint8(float64(peach[i])*(1-t) + float64(mauve[i])*t + 0.5)
+			}
+			blend(img, px, py, c, float64(covered)/(samples*samples))
+		}
+	}
+
+	inner := int(float64(size) * 0.8)
+	offset := int(float64(size) * 0.1)
+	New(value, inner).Draw(img, offset, offset)
+	return img
+}
+
+// Terminal renders the badge with 24-bit ANSI colors, two pixels per
+// character cell using half blocks. width is in characters.
+func Terminal(value string, width int) string {
+	img := Badge(value, width)
+	var b strings.Builder
+	for y := 0; y < width; y += 2 {
+		for x := 0; x < width; x++ {
+			top := unpremultiply(img.RGBAAt(x, y))
+			bottom := unpremultiply(img.RGBAAt(x, y+1))
+			switch {
+			case top.A < 128 && bottom.A < 128:
+				b.WriteString("\x1b[0m ")
+			case bottom.A < 128:
+				fmt.Fprintf(&b, "\x1b[0;38;2;%d;%d;%dm▀", top.R, top.G, top.B)
+			case top.A < 128:
+				fmt.Fprintf(&b, "\x1b[0;38;2;%d;%d;%dm▄", bottom.R, bottom.G, bottom.B)
+			default:
+				fmt.Fprintf(&b, "\x1b[38;2;%d;%d;%d;48;2;%d;%d;%dm▀", top.R, top.G, top.B, bottom.R, bottom.G, bottom.B)
+			}
+		}
+		b.WriteString("\x1b[0m\n")
+	}
+	return b.String()
+}
+
+// unpremultiply returns the straight color of a premultiplied pixel
+func unpremultiply(c color.RGBA) color.RGBA {
+	if c.A == 0 || c.A == 255 {
+		return c
+	}
+	scale := func(v uint8) uint8 { return uint8(min(int(v)*255/int(c.A), 255)) }
+	return color.RGBA{scale(c.R), scale(c.G), scale(c.B), c.A}
+}
@@ -22,10 +22,9 @@ package metrics
 
 import (
 	"context"
-	"fmt"
-	"net"
 	"net/http"
 
+	"github.com/Psiphon-Inc/conduit/cli/internal/httpserver"
 	"github.com/Psiphon-Labs/psiphon-tunnel-core/psiphon/common/buildinfo"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/collectors"
@@ -34,6 +33,69 @@ import (
 
 const namespace = "conduit"
 
+// Options of the metrics served by the endpoint. The stats textfile reuses
+// them so both expose the same names and help text.
+var (
+	ConnectingClientsOpts = prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "connecting_clients",
+		Help:      "Number of clients currently connecting to the proxy",
+	}
+	ConnectedClientsOpts = prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "connected_clients",
+		Help:      "Number of clients currently connected to the proxy",
+	}
+	IsLiveOpts = prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "is_live",
+		Help:      "Whether the service is connected to the Psiphon broker (1 = connected, 0 = disconnected)",
+	}
+	MaxClientsOpts = prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "max_clients",
+		Help:      "Maximum number of proxy clients allowed",
+	}
+	BandwidthLimitOpts = prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "bandwidth_limit_bytes_per_second",
+		Help:      "Configured bandwidth limit in bytes per second (0 = unlimited)",
+	}
+	BytesUploadedOpts = prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "bytes_uploaded",
+		Help:      "Total number of bytes uploaded through the proxy",
+	}
+	BytesDownloadedOpts = prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "bytes_downloaded",
+		Help:      "Total number of bytes downloaded through the proxy",
+	}
+	BuildInfoOpts = prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "build_info",
+		Help:      "Build information about the Conduit service",
+	}
+	StationInfoOpts = prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "station_info",
+		Help:      "Identity of the station, as shown in the Conduit app and Ryve",
+	}
+	UptimeSecondsOpts = prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "uptime_seconds",
+		Help:      "Number of seconds since the service started",
+	}
+	IdleSecondsOpts = prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "idle_seconds",
+		Help:      "Number of seconds the proxy has been idle (0 connecting and 0 connected clients)",
+	}
+
+	// StationInfoLabels are the labels of conduit_station_info
+	StationInfoLabels = []string{"proxy_id", "station_name"}
+)
+
 // Metrics holds all Prometheus metrics for the Conduit service
 type Metrics struct {
 	// Gauges
@@ -46,10 +108,11 @@ type Metrics struct {
 	BytesDownloaded   prometheus.Gauge
 
 	// Info
-	BuildInfo *prometheus.GaugeVec
+	BuildInfo   *prometheus.GaugeVec
+	StationInfo *prometheus.GaugeVec
 
 	registry *prometheus.Registry
-	server   *http.Server
+	server   *httpserver.Server
 }
 
 // GaugeFuncs holds functions that compute metrics at scrape time
@@ -67,83 +130,27 @@ func New(gaugeFuncs GaugeFuncs) *Metrics {
 	registry.MustRegister(collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}))
 
 	m := &Metrics{
-		ConnectingClients: prometheus.NewGauge(
-			prometheus.GaugeOpts{
-				Namespace: namespace,
-				Name:      "connecting_clients",
-				Help:      "Number of clients currently connecting to the proxy",
-			},
-		),
-		ConnectedClients: prometheus.NewGauge(
-			prometheus.GaugeOpts{
-				Namespace: namespace,
-				Name:      "connected_clients",
-				Help:      "Number of clients currently connected to the proxy",
-			},
-		),
-		IsLive: prometheus.NewGauge(
-			prometheus.GaugeOpts{
-				Namespace: namespace,
-				Name:      "is_live",
-				Help:      "Whether the service is connected to the Psiphon broker (1 = connected, 0 = disconnected)",
-			},
-		),
-		MaxClients: prometheus.NewGauge(
-			prometheus.GaugeOpts{
-				Namespace: namespace,
-				Name:      "max_clients",
-				Help:      "Maximum number of proxy clients allowed",
-			},
-		),
-		BandwidthLimit: prometheus.NewGauge(
-			prometheus.GaugeOpts{
-				Namespace: namespace,
-				Name:      "bandwidth_limit_bytes_per_second",
-				Help:      "Configured bandwidth limit in bytes per second (0 = unlimited)",
-			},
-		),
-		BytesUploaded: prometheus.NewGauge(
-			prometheus.GaugeOpts{
-				Namespace: namespace,
-				Name:      "bytes_uploaded",
-				Help:      "Total number of bytes uploaded through the proxy",
-			},
-		),
-		BytesDownloaded: prometheus.NewGauge(
-			prometheus.GaugeOpts{
-				Namespace: namespace,
-				Name:      "bytes_downloaded",
-				Help:      "Total number of bytes downloaded through the proxy",
-			},
-		),
+		ConnectingClients: prometheus.NewGauge(ConnectingClientsOpts),
+		ConnectedClients:  prometheus.NewGauge(ConnectedClientsOpts),
+		IsLive:            prometheus.NewGauge(IsLiveOpts),
+		MaxClients:        prometheus.NewGauge(MaxClientsOpts),
+		BandwidthLimit:    prometheus.NewGauge(BandwidthLimitOpts),
+		BytesUploaded:     prometheus.NewGauge(BytesUploadedOpts),
+		BytesDownloaded:   prometheus.NewGauge(BytesDownloadedOpts),
 		BuildInfo: prometheus.NewGaugeVec(
-			prometheus.GaugeOpts{
-				Namespace: namespace,
-				Name:      "build_info",
-				Help:      "Build information about the Conduit service",
-			},
-			[]string{"build_repo", "build_rev", "go_version", "values_rev"},
+			BuildInfoOpts,
+			[]string{"build_repo", "build_rev", "go_version", "values_rev", "version", "config_source", "config_fingerprint", "propagation_channel_id", "sponsor_id", "config_version"},
+		),
+		StationInfo: prometheus.NewGaugeVec(
+			StationInfoOpts,
+			StationInfoLabels,
 		),
 		registry: registry,
 	}
 
 	// Create GaugeFunc metrics (computed at scrape time)
-	uptimeSeconds := prometheus.NewGaugeFunc(
-		prometheus.GaugeOpts{
-			Namespace: namespace,
-			Name:      "uptime_seconds",
-			Help:      "Number of seconds since the service started",
-		},
-		gaugeFuncs.GetUptimeSeconds,
-	)
-	idleSeconds := prometheus.NewGaugeFunc(
-		prometheus.GaugeOpts{
-			Namespace: namespace,
-			Name:      "idle_seconds",
-			Help:      "Number of seconds the proxy has been idle (0 connecting and 0 connected clients)",
-		},
-		gaugeFuncs.GetIdleSeconds,
-	)
+	uptimeSeconds := prometheus.NewGaugeFunc(UptimeSecondsOpts, gaugeFuncs.GetUptimeSeconds)
+	idleSeconds := prometheus.NewGaugeFunc(IdleSecondsOpts, gaugeFuncs.GetIdleSeconds)
 
 	// Register all metrics
 	registry.MustRegister(m.ConnectingClients)
@@ -156,11 +163,10 @@ func New(gaugeFuncs GaugeFuncs) *Metrics {
 	registry.MustRegister(m.BytesUploaded)
 	registry.MustRegister(m.BytesDownloaded)
 	registry.MustRegister(m.BuildInfo)
+	registry.MustRegister(m.StationInfo)
 
 	// Set build info
-
-	buildInfo := buildinfo.GetBuildInfo()
-	m.BuildInfo.WithLabelValues(buildInfo.BuildRepo, buildInfo.BuildRev, buildInfo.GoVersion, buildInfo.ValuesRev).Set(1)
+	m.SetBuildInfo(BuildLabels{})
 
 	return m
 }
@@ -171,6 +177,31 @@ func (m *Metrics) SetConfig(maxClients int, bandwidthBytesPerSecond int) {
 	m.BandwidthLimit.Set(float64(bandwidthBytesPerSecond))
 }
 
+// BuildLabels are the conduit_build_info labels that describe the CLI and
+// the Psiphon config, alongside tunnel-core's build info
+type BuildLabels struct {
+	Version              string
+	ConfigSource         string // "embedded", "file" or "remote"
+	ConfigFingerprint    string
+	PropagationChannelID string
+	SponsorID            string
+	ConfigVersion        string
+}
+
+// SetBuildInfo sets the build info labels
+func (m *Metrics) SetBuildInfo(labels BuildLabels) {
+	buildInfo := buildinfo.GetBuildInfo()
+	m.BuildInfo.Reset()
+	m.BuildInfo.WithLabelValues(buildInfo.BuildRepo, buildInfo.BuildRev, buildInfo.GoVersion, buildInfo.ValuesRev,
+		labels.Version, labels.ConfigSource, labels.ConfigFingerprint, labels.PropagationChannelID, labels.SponsorID, labels.ConfigVersion).Set(1)
+}
+
+// SetStationInfo sets the station identity labels
+func (m *Metrics) SetStationInfo(proxyID, name string) {
+	m.StationInfo.Reset()
+	m.StationInfo.WithLabelValues(proxyID, name).Set(1)
+}
+
 // SetConnectingClients updates the connecting clients gauge
 func (m *Metrics) SetConnectingClients(count int) {
 	m.ConnectingClients.Set(float64(count))
@@ -201,27 +232,17 @@ func (m *Metrics) SetBytesDownloaded(bytes float64) {
 }
 
 // StartServer starts the HTTP server for Prometheus metrics
-func (m *Metrics) StartServer(addr string) error {
+func (m *Metrics) StartServer(addr string, opts httpserver.Options) error {
 	mux := http.NewServeMux()
 	mux.Handle("/metrics", promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{
 		EnableOpenMetrics: true,
 	}))
 
-	m.server = &http.Server{Addr: addr, Handler: mux}
-
-	// Create a listener to verify the port is available before starting the server
-	listener, err := net.Listen("tcp", addr)
+	server, err := httpserver.Start("Metrics", addr, mux, opts)
 	if err != nil {
-		return fmt.Errorf("failed to bind to %s: %w", addr, err)
+		return err
 	}
-
-	// Start server in background with the pre-created listener
-	go func() {
-		if err := m.server.Serve(listener); err != nil && err != http.ErrServerClosed {
-			fmt.Printf("[ERROR] Metrics server error: %v\n", err)
-		}
-	}()
-
+	m.server = server
 	return nil
 }
 
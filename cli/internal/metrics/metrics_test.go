@@ -0,0 +1,62 @@
+/*
+ * Copyright (c) 2026, Psiphon Inc.
+ * All rights reserved.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package metrics
+
+import (
+	"testing"
+)
+
+func TestSetBuildInfo(t *testing.T) {
+	m := newTestMetrics()
+	m.SetBuildInfo(BuildLabels{Version: "1.2.0", ConfigSource: "remote", ConfigFingerprint: "abc", SponsorID: "0123"})
+	m.SetBuildInfo(BuildLabels{Version: "1.3.0", ConfigSource: "embedded", ConfigFingerprint: "def", PropagationChannelID: "4567", SponsorID: "0123", ConfigVersion: "7"})
+
+	families, err := m.registry.Gather()
+	if err != nil {
+		t.Fatalf("Gather: %v", err)
+	}
+	for _, family := range families {
+		if family.GetName() != "conduit_build_info" {
+			continue
+		}
+		// A new config replaces the series rather than adding one
+		if len(family.Metric) != 1 {
+			t.Fatalf("conduit_build_info has %d series, expected 1", len(family.Metric))
+		}
+		labels := map[string]string{}
+		for _, label := range family.Metric[0].Label {
+			labels[label.GetName()] = label.GetValue()
+		}
+		for name, expected := range map[string]string{
+			"version":                "1.3.0",
+			"config_source":          "embedded",
+			"config_fingerprint":     "def",
+			"propagation_channel_id": "4567",
+			"sponsor_id":             "0123",
+			"config_version":         "7",
+		} {
+			if labels[name] != expected {
+				t.Fatalf("label %s = %q, expected %q", name, labels[name], expected)
+			}
+		}
+		return
+	}
+	t.Fatalf("conduit_build_info not registered")
+}
@@ -0,0 +1,177 @@
+/*
+ * Copyright (c) 2026, Psiphon Inc.
+ * All rights reserved.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package metrics
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	prometheusbridge "go.opentelemetry.io/contrib/bridges/prometheus"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetricgrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetrichttp"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+// OTLP protocols, as in OTEL_EXPORTER_OTLP_PROTOCOL
+const (
+	protocolGRPC = "grpc"
+	protocolHTTP = "http/protobuf"
+)
+
+const tracerName = "github.com/Psiphon-Inc/conduit/cli"
+
+// OTLP exports the metrics registry and traces to an OpenTelemetry collector
+type OTLP struct {
+	meters  *sdkmetric.MeterProvider
+	tracers *sdktrace.TracerProvider
+}
+
+// otlpSignal reads the standard OTEL_* environment variables for a signal
+// ("metrics" or "traces"). A signal is exported when an OTLP endpoint is set
+// for it, unless the SDK or the signal's exporter is disabled.
+func otlpSignal(signal string) (enabled bool, protocol string, err error) {
+	upper := strings.ToUpper(signal)
+	if strings.EqualFold(os.Getenv("OTEL_SDK_DISABLED"), "true") ||
+		os.Getenv("OTEL_"+upper+"_EXPORTER") == "none" {
+		return false, "", nil
+	}
+	if os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT") == "" && os.Getenv("OTEL_EXPORTER_OTLP_"+upper+"_ENDPOINT") == "" {
+		return false, "", nil
+	}
+
+	protocol = os.Getenv("OTEL_EXPORTER_OTLP_" + upper + "_PROTOCOL")
+	if protocol == "" {
+		protocol = os.Getenv("OTEL_EXPORTER_OTLP_PROTOCOL")
+	}
+	switch protocol {
+	case "":
+		protocol = protocolHTTP
+	case protocolGRPC, protocolHTTP:
+	default:
+		return false, "", fmt.Errorf("unsupported OTLP protocol %q for %s (use grpc or http/protobuf)", protocol, signal)
+	}
+	return true, protocol, nil
+}
+
+// OTLPConfigured reports whether the environment configures OTLP export. It
+// fails if an OTLP endpoint is set with a protocol we can't export.
+func OTLPConfigured() (bool, error) {
+	metrics, _, err := otlpSignal("metrics")
+	if err != nil {
+		return false, err
+	}
+	traces, _, err := otlpSignal("traces")
+	if err != nil {
+		return false, err
+	}
+	return metrics || traces, nil
+}
+
+// StartOTLP starts exporting all registry metrics, and provides a tracer,
+// as configured by the OTEL_* environment variables. attrs are added to the
+// resource, after which OTEL_RESOURCE_ATTRIBUTES and OTEL_SERVICE_NAME apply.
+func (m *Metrics) StartOTLP(ctx context.Context, attrs ...attribute.KeyValue) (*OTLP, error) {
+	res, err := resource.New(ctx,
+		resource.WithAttributes(attribute.String("service.name", "conduit")),
+		resource.WithAttributes(attrs...),
+		resource.WithTelemetrySDK(),
+		resource.WithFromEnv(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create OTLP resource: %w", err)
+	}
+
+	o := &OTLP{}
+
+	enabled, protocol, err := otlpSignal("metrics")
+	if err != nil {
+		return nil, err
+	}
+	if enabled {
+		var exporter sdkmetric.Exporter
+		if protocol == protocolGRPC {
+			exporter, err = otlpmetricgrpc.New(ctx)
+		} else {
+			exporter, err = otlpmetrichttp.New(ctx)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to create OTLP metrics exporter: %w", err)
+		}
+		// The Prometheus bridge mirrors every gauge in the registry
+		reader := sdkmetric.NewPeriodicReader(exporter,
+			sdkmetric.WithProducer(prometheusbridge.NewMetricProducer(prometheusbridge.WithGatherer(m.registry))))
+		o.meters = sdkmetric.NewMeterProvider(sdkmetric.WithResource(res), sdkmetric.WithReader(reader))
+	}
+
+	enabled, protocol, err = otlpSignal("traces")
+	if err != nil {
+		o.Shutdown(ctx)
+		return nil, err
+	}
+	if enabled {
+		var exporter sdktrace.SpanExporter
+		if protocol == protocolGRPC {
+			exporter, err = otlptracegrpc.New(ctx)
+		} else {
+			exporter, err = otlptracehttp.New(ctx)
+		}
+		if err != nil {
+			o.Shutdown(ctx)
+			return nil, fmt.Errorf("failed to create OTLP trace exporter: %w", err)
+		}
+		o.tracers = sdktrace.NewTracerProvider(sdktrace.WithResource(res), sdktrace.WithBatcher(exporter))
+	}
+
+	return o, nil
+}
+
+// Tracer returns the tracer for Conduit spans, which does nothing if traces
+// aren't exported
+func (o *OTLP) Tracer() trace.Tracer {
+	if o == nil || o.tracers == nil {
+		return noop.NewTracerProvider().Tracer(tracerName)
+	}
+	return o.tracers.Tracer(tracerName)
+}
+
+// Shutdown exports pending metrics and spans and stops exporting
+func (o *OTLP) Shutdown(ctx context.Context) error {
+	if o == nil {
+		return nil
+	}
+	var errs []error
+	if o.tracers != nil {
+		errs = append(errs, o.tracers.Shutdown(ctx))
+	}
+	if o.meters != nil {
+		errs = append(errs, o.meters.Shutdown(ctx))
+	}
+	return errors.Join(errs...)
+}
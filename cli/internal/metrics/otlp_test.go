@@ -0,0 +1,207 @@
+/*
+ * Copyright (c) 2026, Psiphon Inc.
+ * All rights reserved.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package metrics
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"go.opentelemetry.io/otel/attribute"
+	collectormetrics "go.opentelemetry.io/proto/otlp/collector/metrics/v1"
+	collectortrace "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	metricspb "go.opentelemetry.io/proto/otlp/metrics/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/proto"
+)
+
+// receiver is an in-process OTLP receiver for HTTP and gRPC
+type receiver struct {
+	collectormetrics.UnimplementedMetricsServiceServer
+
+	mu      sync.Mutex
+	metrics []*metricspb.ResourceMetrics
+	spans   []string
+}
+
+func (r *receiver) Export(ctx context.Context, req *collectormetrics.ExportMetricsServiceRequest) (*collectormetrics.ExportMetricsServiceResponse, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.metrics = append(r.metrics, req.ResourceMetrics...)
+	return &collectormetrics.ExportMetricsServiceResponse{}, nil
+}
+
+func (r *receiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	body, _ := io.ReadAll(req.Body)
+	switch req.URL.Path {
+	case "/v1/metrics":
+		var export collectormetrics.ExportMetricsServiceRequest
+		if err := proto.Unmarshal(body, &export); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		r.Export(req.Context(), &export)
+	case "/v1/traces":
+		var export collectortrace.ExportTraceServiceRequest
+		if err := proto.Unmarshal(body, &export); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		r.mu.Lock()
+		for _, rs := range export.ResourceSpans {
+			for _, ss := range rs.ScopeSpans {
+				for _, span := range ss.Spans {
+					r.spans = append(r.spans, span.Name)
+				}
+			}
+		}
+		r.mu.Unlock()
+	default:
+		http.NotFound(w, req)
+		return
+	}
+	w.Header().Set("Content-Type", "application/x-protobuf")
+}
+
+// gauge returns the value of a gauge and whether the resource had the station attribute
+func (r *receiver) gauge(name string) (value float64, found, station bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, rm := range r.metrics {
+		for _, attr := range rm.Resource.Attributes {
+			if attr.Key == "conduit.station.name" && attr.Value.GetStringValue() == "berlin-1" {
+				station = true
+			}
+		}
+		for _, sm := range rm.ScopeMetrics {
+			for _, m := range sm.Metrics {
+				if m.Name == name && m.GetGauge() != nil && len(m.GetGauge().DataPoints) > 0 {
+					return m.GetGauge().DataPoints[0].GetAsDouble(), true, station
+				}
+			}
+		}
+	}
+	return 0, false, station
+}
+
+func newTestMetrics() *Metrics {
+	m := New(GaugeFuncs{
+		GetUptimeSeconds: func() float64 { return 42 },
+		GetIdleSeconds:   func() float64 { return 0 },
+	})
+	m.SetConnectedClients(7)
+	return m
+}
+
+func TestOTLPHTTP(t *testing.T) {
+	r := &receiver{}
+	server := httptest.NewServer(r)
+	defer server.Close()
+	t.Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", server.URL)
+
+	if configured, err := OTLPConfigured(); !configured || err != nil {
+		t.Fatalf("OTLPConfigured() = %v, %v with OTEL_EXPORTER_OTLP_ENDPOINT set", configured, err)
+	}
+	o, err := newTestMetrics().StartOTLP(context.Background(), attribute.String("conduit.station.name", "berlin-1"))
+	if err != nil {
+		t.Fatalf("StartOTLP: %v", err)
+	}
+	_, span := o.Tracer().Start(context.Background(), "conduit.controller")
+	span.End()
+	if err := o.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown: %v", err)
+	}
+
+	for name, expected := range map[string]float64{"conduit_connected_clients": 7, "conduit_uptime_seconds": 42} {
+		value, found, station := r.gauge(name)
+		if !found || value != expected || !station {
+			t.Fatalf("%s = %v (found %v, station %v), expected %v", name, value, found, station, expected)
+		}
+	}
+	if len(r.spans) != 1 || r.spans[0] != "conduit.controller" {
+		t.Fatalf("received spans %v", r.spans)
+	}
+}
+
+func TestOTLPGRPC(t *testing.T) {
+	r := &receiver{}
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen: %v", err)
+	}
+	server := grpc.NewServer()
+	collectormetrics.RegisterMetricsServiceServer(server, r)
+	go server.Serve(listener)
+	defer server.Stop()
+
+	t.Setenv("OTEL_EXPORTER_OTLP_METRICS_ENDPOINT", "http://"+listener.Addr().String())
+	t.Setenv("OTEL_EXPORTER_OTLP_PROTOCOL", "grpc")
+	o, err := newTestMetrics().StartOTLP(context.Background(), attribute.String("conduit.station.name", "berlin-1"))
+	if err != nil {
+		t.Fatalf("StartOTLP: %v", err)
+	}
+	if err := o.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown: %v", err)
+	}
+	if value, found, _ := r.gauge("conduit_connected_clients"); !found || value != 7 {
+		t.Fatalf("conduit_connected_clients = %v (found %v), expected 7", value, found)
+	}
+}
+
+func TestOTLPSignal(t *testing.T) {
+	tests := []struct {
+		env      map[string]string
+		enabled  bool
+		protocol string
+		err      bool
+	}{
+		{map[string]string{}, false, "", false},
+		{map[string]string{"OTEL_EXPORTER_OTLP_ENDPOINT": "http://c:4318"}, true, protocolHTTP, false},
+		{map[string]string{"OTEL_EXPORTER_OTLP_ENDPOINT": "http://c:4317", "OTEL_EXPORTER_OTLP_PROTOCOL": "grpc"}, true, protocolGRPC, false},
+		{map[string]string{"OTEL_EXPORTER_OTLP_ENDPOINT": "http://c", "OTEL_EXPORTER_OTLP_PROTOCOL": "grpc", "OTEL_EXPORTER_OTLP_METRICS_PROTOCOL": "http/protobuf"}, true, protocolHTTP, false},
+		{map[string]string{"OTEL_EXPORTER_OTLP_ENDPOINT": "http://c", "OTEL_EXPORTER_OTLP_PROTOCOL": "http/json"}, false, "", true},
+		{map[string]string{"OTEL_EXPORTER_OTLP_ENDPOINT": "http://c", "OTEL_METRICS_EXPORTER": "none"}, false, "", false},
+		{map[string]string{"OTEL_EXPORTER_OTLP_ENDPOINT": "http://c", "OTEL_SDK_DISABLED": "true"}, false, "", false},
+		{map[string]string{"OTEL_EXPORTER_OTLP_TRACES_ENDPOINT": "http://c"}, false, "", false},
+	}
+	for _, tt := range tests {
+		for _, name := range []string{"OTEL_EXPORTER_OTLP_ENDPOINT", "OTEL_EXPORTER_OTLP_METRICS_ENDPOINT", "OTEL_EXPORTER_OTLP_TRACES_ENDPOINT",
+			"OTEL_EXPORTER_OTLP_PROTOCOL", "OTEL_EXPORTER_OTLP_METRICS_PROTOCOL", "OTEL_METRICS_EXPORTER", "OTEL_SDK_DISABLED"} {
+			t.Setenv(name, tt.env[name])
+		}
+		enabled, protocol, err := otlpSignal("metrics")
+		if enabled != tt.enabled || protocol != tt.protocol || (err != nil) != tt.err {
+			t.Fatalf("otlpSignal(metrics) with %v = %v, %q, %v", tt.env, enabled, protocol, err)
+		}
+	}
+}
+
+func TestOTLPConfiguredUnsupportedProtocol(t *testing.T) {
+	t.Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", "http://c:4318")
+	t.Setenv("OTEL_EXPORTER_OTLP_PROTOCOL", "http/json")
+
+	if _, err := OTLPConfigured(); err == nil {
+		t.Fatalf("OTLPConfigured() accepted an unsupported protocol")
+	}
+}
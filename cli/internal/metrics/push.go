@@ -0,0 +1,179 @@
+/*
+ * Copyright (c) 2026, Psiphon Inc.
+ * All rights reserved.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/Psiphon-Inc/conduit/cli/internal/config"
+	"github.com/Psiphon-Inc/conduit/cli/internal/periodic"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/push"
+	dto "github.com/prometheus/client_model/go"
+)
+
+// DefaultPushInterval is how often metrics are pushed when unset
+const DefaultPushInterval = 30 * time.Second
+
+// pushJob is the job label of pushed metrics
+const pushJob = "conduit"
+
+// Environment variables that supply push credentials, so they stay out of
+// command lines
+const (
+	PushPasswordEnv = "CONDUIT_METRICS_PUSH_PASSWORD"
+	PushTokenEnv    = "CONDUIT_METRICS_PUSH_TOKEN"
+)
+
+// PushOptions configures pushing the metrics registry
+type PushOptions struct {
+	Pushgateway string // Pushgateway URL (empty = disabled)
+	RemoteWrite string // Prometheus remote-write URL (empty = disabled)
+	Interval    time.Duration
+	Labels      map[string]string // Grouping labels added to every series, e.g. station and proxy_id
+	Username    string            // Basic auth (default: from the URL)
+	Password    string
+	BearerToken string
+}
+
+// Push periodically sends the metrics registry to a Pushgateway and/or a
+// remote-write endpoint, for stations that can't be scraped
+type Push struct {
+	options     PushOptions
+	gatherer    prometheus.Gatherer
+	gateway     *push.Pusher
+	remoteWrite string
+	client      *http.Client
+	stop        context.CancelFunc
+	done        chan struct{}
+}
+
+// StartPush starts pushing metrics every interval until Shutdown
+func (m *Metrics) StartPush(options PushOptions) (*Push, error) {
+	if options.Interval == 0 {
+		options.Interval = DefaultPushInterval
+	}
+	p := &Push{
+		options:  options,
+		gatherer: m.registry,
+		client:   &http.Client{Timeout: 10 * time.Second},
+	}
+
+	if options.Pushgateway != "" {
+		target, err := p.parseURL(options.Pushgateway)
+		if err != nil {
+			return nil, err
+		}
+		// The grouping labels identify the station, so its info metric would
+		// only repeat them (and the Pushgateway rejects the duplicate label)
+		gatherer := prometheus.GathererFunc(func() ([]*dto.MetricFamily, error) {
+			families, err := m.registry.Gather()
+			var kept []*dto.MetricFamily
+			for _, f := range families {
+				if f.GetName() != namespace+"_station_info" {
+					kept = append(kept, f)
+				}
+			}
+			return kept, err
+		})
+		p.gateway = push.New(target, pushJob).Gatherer(gatherer).Client(p.client)
+		for name, value := range options.Labels {
+			if value != "" {
+				p.gateway.Grouping(name, value)
+			}
+		}
+		if p.options.Username != "" {
+			p.gateway.BasicAuth(p.options.Username, p.options.Password)
+		} else if p.options.BearerToken != "" {
+			p.gateway.Header(http.Header{"Authorization": {"Bearer " + p.options.BearerToken}})
+		}
+	}
+
+	if options.RemoteWrite != "" {
+		target, err := p.parseURL(options.RemoteWrite)
+		if err != nil {
+			return nil, err
+		}
+		p.remoteWrite = target
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	p.stop = cancel
+	p.done = make(chan struct{})
+	go p.run(ctx)
+	return p, nil
+}
+
+// parseURL validates a push URL, taking basic auth credentials out of it
+func (p *Push) parseURL(raw string) (string, error) {
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return "", fmt.Errorf("invalid metrics push URL %q", config.RedactURL(raw))
+	}
+	if u.User != nil {
+		if p.options.Username == "" {
+			p.options.Username = u.User.Username()
+		}
+		if password, ok := u.User.Password(); ok && p.options.Password == "" {
+			p.options.Password = password
+		}
+		u.User = nil
+	}
+	return u.String(), nil
+}
+
+func (p *Push) run(ctx context.Context) {
+	defer close(p.done)
+	periodic.Task{
+		Interval:  p.options.Interval,
+		Immediate: true,
+		Failed:    "Failed to push metrics",
+		Recovered: "Pushing metrics again",
+	}.Run(ctx, p.push)
+}
+
+// push sends the current metrics to every target
+func (p *Push) push(ctx context.Context) error {
+	if p.gateway != nil {
+		if err := p.gateway.PushContext(ctx); err != nil {
+			return fmt.Errorf("pushgateway: %w", err)
+		}
+	}
+	if p.remoteWrite != "" {
+		if err := p.sendRemoteWrite(ctx, time.Now()); err != nil {
+			return fmt.Errorf("remote-write: %w", err)
+		}
+	}
+	return nil
+}
+
+// Shutdown stops pushing, after pushing the final values
+func (p *Push) Shutdown(ctx context.Context) error {
+	if p == nil {
+		return nil
+	}
+	p.stop()
+	<-p.done
+	return p.push(ctx)
+}
@@ -0,0 +1,60 @@
+/*
+ * Copyright (c) 2026, Psiphon Inc.
+ * All rights reserved.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+// Package periodic runs background tasks on an interval
+package periodic
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Task describes a task run every Interval
+type Task struct {
+	Interval  time.Duration
+	Immediate bool   // Run once right away, not only after the first interval
+	Failed    string // Logged as "[WARN] <Failed>: <error>" when the task starts failing
+	Recovered string // Logged as "[INFO] <Recovered>" when it succeeds again
+}
+
+// Run calls fn every interval until ctx is done. Failures are logged when the
+// task starts failing and when it recovers, not every interval.
+func (t Task) Run(ctx context.Context, fn func(context.Context) error) {
+	ticker := time.NewTicker(t.Interval)
+	defer ticker.Stop()
+	failing := false
+	for run := t.Immediate; ; run = true {
+		if run {
+			err := fn(ctx)
+			if err != nil && !failing && ctx.Err() == nil {
+				fmt.Printf("[WARN] %s: %v\n", t.Failed, err)
+			} else if err == nil && failing {
+				fmt.Printf("[INFO] %s\n", t.Recovered)
+			}
+			failing = err != nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
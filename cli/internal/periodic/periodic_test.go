@@ -0,0 +1,80 @@
+/*
+ * Copyright (c) 2026, Psiphon Inc.
+ * All rights reserved.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package periodic
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestRunLogsStateChanges(t *testing.T) {
+	results := []error{errors.New("down"), errors.New("still down"), nil, nil, errors.New("down again")}
+	ctx, cancel := context.WithCancel(context.Background())
+	calls := 0
+
+	output := captureStdout(t, func() {
+		Task{
+			Interval:  time.Millisecond,
+			Immediate: true,
+			Failed:    "Failed to push",
+			Recovered: "Pushing again",
+		}.Run(ctx, func(context.Context) error {
+			err := results[calls]
+			calls++
+			if calls == len(results) {
+				cancel()
+			}
+			return err
+		})
+	})
+
+	want := "[WARN] Failed to push: down\n[INFO] Pushing again\n"
+	if output != want {
+		t.Errorf("output = %q, want %q", output, want)
+	}
+}
+
+func TestRunWaitsForFirstInterval(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	Task{Interval: time.Hour}.Run(ctx, func(context.Context) error {
+		t.Error("task ran before the first interval")
+		return nil
+	})
+}
+
+func captureStdout(t *testing.T, fn func()) string {
+	t.Helper()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout := os.Stdout
+	os.Stdout = w
+	fn()
+	os.Stdout = stdout
+	w.Close()
+	data, _ := io.ReadAll(r)
+	return string(data)
+}
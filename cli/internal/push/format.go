@@ -0,0 +1,145 @@
+/*
+ * Copyright (c) 2026, Psiphon Inc.
+ * All rights reserved.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package push
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Psiphon-Inc/conduit/cli/internal/conduit"
+)
+
+// gauge is a single value of a stats sample
+type gauge struct {
+	name  string
+	value int64
+}
+
+func gauges(stats conduit.StatsJSON) []gauge {
+	isLive := int64(0)
+	if stats.IsLive {
+		isLive = 1
+	}
+	return []gauge{
+		{"connecting_clients", int64(stats.ConnectingClients)},
+		{"connected_clients", int64(stats.ConnectedClients)},
+		{"bytes_uploaded", stats.TotalBytesUp},
+		{"bytes_downloaded", stats.TotalBytesDown},
+		{"uptime_seconds", stats.UptimeSeconds},
+		{"idle_seconds", stats.IdleSeconds},
+		{"is_live", isLive},
+	}
+}
+
+// statsdLines formats stats as StatsD gauges. DogStatsD gauges are tagged
+// with the station and country; plain StatsD has no tags, so geo gauges
+// carry the country in their name.
+func statsdLines(stats conduit.StatsJSON, prefix string, tagged bool) []string {
+	var tags []string
+	if tagged {
+		if stats.StationName != "" {
+			tags = append(tags, "station:"+dogTag(stats.StationName))
+		}
+		if stats.ProxyID != "" {
+			tags = append(tags, "proxy_id:"+dogTag(stats.ProxyID))
+		}
+	}
+	line := func(name string, value int64, tags []string) string {
+		s := fmt.Sprintf("%s.%s:%d|g", prefix, name, value)
+		if len(tags) > 0 {
+			s += "|#" + strings.Join(tags, ",")
+		}
+		return s
+	}
+
+	var lines []string
+	for _, g := range gauges(stats) {
+		lines = append(lines, line(g.name, g.value, tags))
+	}
+	for _, g := range stats.Geo {
+		values := []gauge{{"connected_clients", int64(g.Count)}, {"bytes_uploaded", g.BytesUp}, {"bytes_downloaded", g.BytesDown}}
+		for _, v := range values {
+			if tagged {
+				lines = append(lines, line("geo."+v.name, v.value, append(tags[:len(tags):len(tags)], "country:"+dogTag(g.Code))))
+			} else {
+				lines = append(lines, line("geo."+v.name+"."+g.Code, v.value, nil))
+			}
+		}
+	}
+	return lines
+}
+
+// dogTag removes the characters that separate DogStatsD tags and fields
+func dogTag(s string) string {
+	return strings.NewReplacer(",", "_", "|", "_", "#", "_", "\n", "_").Replace(s)
+}
+
+// influxLines formats stats in InfluxDB line protocol: a "conduit"
+// measurement, and a "conduit_geo" measurement per country
+func influxLines(stats conduit.StatsJSON, now time.Time, precision string) []byte {
+	var tags string
+	if stats.StationName != "" {
+		tags += ",station=" + influxTag(stats.StationName)
+	}
+	if stats.ProxyID != "" {
+		tags += ",proxy_id=" + influxTag(stats.ProxyID)
+	}
+	timestamp := influxTimestamp(now, precision)
+
+	var b strings.Builder
+	b.WriteString("conduit" + tags + " ")
+	for i, g := range gauges(stats) {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		if g.name == "is_live" {
+			b.WriteString("is_live=" + strconv.FormatBool(stats.IsLive))
+		} else {
+			fmt.Fprintf(&b, "%s=%di", g.name, g.value)
+		}
+	}
+	b.WriteString(" " + timestamp + "\n")
+
+	for _, g := range stats.Geo {
+		fmt.Fprintf(&b, "conduit_geo%s,country=%s connected_clients=%di,bytes_uploaded=%di,bytes_downloaded=%di %s\n",
+			tags, influxTag(g.Code), g.Count, g.BytesUp, g.BytesDown, timestamp)
+	}
+	return []byte(b.String())
+}
+
+// influxTag escapes a tag value
+func influxTag(s string) string {
+	return strings.NewReplacer(`\`, `\\`, ",", `\,`, "=", `\=`, " ", `\ `, "\n", " ").Replace(s)
+}
+
+// influxTimestamp formats a timestamp in the precision of the write URL
+func influxTimestamp(t time.Time, precision string) string {
+	switch precision {
+	case "s":
+		return strconv.FormatInt(t.Unix(), 10)
+	case "ms":
+		return strconv.FormatInt(t.UnixMilli(), 10)
+	case "us", "u":
+		return strconv.FormatInt(t.UnixMicro(), 10)
+	}
+	return strconv.FormatInt(t.UnixNano(), 10)
+}
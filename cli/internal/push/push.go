@@ -0,0 +1,185 @@
+/*
+ * Copyright (c) 2026, Psiphon Inc.
+ * All rights reserved.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+// Package push periodically sends station stats to push-based monitoring:
+// StatsD/DogStatsD over UDP, or InfluxDB line protocol over HTTP
+package push
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/Psiphon-Inc/conduit/cli/internal/conduit"
+	"github.com/Psiphon-Inc/conduit/cli/internal/periodic"
+)
+
+// Defaults for unset options
+const (
+	DefaultInterval = 10 * time.Second
+	DefaultPrefix   = "conduit"
+)
+
+// TokenEnv supplies the InfluxDB API token
+const TokenEnv = "CONDUIT_PUSH_TOKEN"
+
+// maxPacketSize keeps StatsD packets within a typical MTU
+const maxPacketSize = 1432
+
+// StatsFunc returns the current stats, or false while no service is running
+type StatsFunc func() (conduit.StatsJSON, bool)
+
+// Options configures a Pusher
+type Options struct {
+	Interval time.Duration
+	Prefix   string // StatsD metric name prefix
+	Token    string // InfluxDB API token
+}
+
+// Pusher sends stats to a single target
+type Pusher struct {
+	target  *url.URL
+	options Options
+	client  *http.Client
+	conn    net.Conn
+}
+
+// New creates a pusher for a target URL:
+//
+//	statsd://host:8125      StatsD gauges, without tags
+//	dogstatsd://host:8125   DogStatsD gauges, tagged with station and proxy_id
+//	http(s)://host/write... InfluxDB line protocol, tagged with station and proxy_id
+func New(target string, options Options) (*Pusher, error) {
+	u, err := url.Parse(target)
+	if err != nil {
+		return nil, fmt.Errorf("invalid push target %q: %w", target, err)
+	}
+	if options.Interval == 0 {
+		options.Interval = DefaultInterval
+	}
+	if options.Prefix == "" {
+		options.Prefix = DefaultPrefix
+	}
+
+	p := &Pusher{target: u, options: options}
+	switch u.Scheme {
+	case "statsd", "dogstatsd":
+		if u.Port() == "" {
+			return nil, fmt.Errorf("push target %q needs a port", target)
+		}
+		if p.conn, err = net.Dial("udp", u.Host); err != nil {
+			return nil, fmt.Errorf("failed to resolve push target: %w", err)
+		}
+	case "http", "https":
+		if u.Host == "" {
+			return nil, fmt.Errorf("invalid push target %q", target)
+		}
+		p.client = &http.Client{Timeout: 10 * time.Second}
+	default:
+		return nil, fmt.Errorf("unsupported push target %q (use statsd://, dogstatsd:// or an InfluxDB http(s):// write URL)", target)
+	}
+	return p, nil
+}
+
+// Start pushes stats every interval in the background until ctx is done
+func (p *Pusher) Start(ctx context.Context, stats StatsFunc) {
+	go func() {
+		if p.conn != nil {
+			defer p.conn.Close()
+		}
+		periodic.Task{
+			Interval:  p.options.Interval,
+			Failed:    "Failed to push stats",
+			Recovered: "Pushing stats again",
+		}.Run(ctx, func(ctx context.Context) error {
+			s, ok := stats()
+			if !ok {
+				return nil
+			}
+			return p.Push(ctx, s, time.Now())
+		})
+	}()
+}
+
+// Push sends a single stats sample
+func (p *Pusher) Push(ctx context.Context, stats conduit.StatsJSON, now time.Time) error {
+	if p.conn != nil {
+		return p.sendPackets(statsdLines(stats, p.options.Prefix, p.target.Scheme == "dogstatsd"))
+	}
+	return p.post(ctx, influxLines(stats, now, p.target.Query().Get("precision")))
+}
+
+// sendPackets sends lines in as few packets as possible
+func (p *Pusher) sendPackets(lines []string) error {
+	var packet bytes.Buffer
+	flush := func() error {
+		if packet.Len() == 0 {
+			return nil
+		}
+		_, err := p.conn.Write(packet.Bytes())
+		packet.Reset()
+		return err
+	}
+	for _, line := range lines {
+		if packet.Len() > 0 && packet.Len()+1+len(line) > maxPacketSize {
+			if err := flush(); err != nil {
+				return err
+			}
+		}
+		if packet.Len() > 0 {
+			packet.WriteByte('\n')
+		}
+		packet.WriteString(line)
+	}
+	return flush()
+}
+
+func (p *Pusher) post(ctx context.Context, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.target.String(), bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "text/plain; charset=utf-8")
+	req.Header.Set("User-Agent", "conduit")
+	if p.options.Token != "" {
+		req.Header.Set("Authorization", "Token "+p.options.Token)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("server returned %s: %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+	return nil
+}
+
+// String returns the target without credentials or query parameters
+func (p *Pusher) String() string {
+	return p.target.Scheme + "://" + p.target.Host + p.target.Path
+}
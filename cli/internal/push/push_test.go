@@ -0,0 +1,253 @@
+/*
+ * Copyright (c) 2026, Psiphon Inc.
+ * All rights reserved.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package push
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Psiphon-Inc/conduit/cli/internal/conduit"
+	"github.com/Psiphon-Inc/conduit/cli/internal/geo"
+)
+
+var testStats = conduit.StatsJSON{
+	StationName:       "berlin 1",
+	ProxyID:           "proxy=id",
+	ConnectingClients: 1,
+	ConnectedClients:  7,
+	TotalBytesUp:      1000,
+	TotalBytesDown:    2000,
+	UptimeSeconds:     60,
+	IsLive:            true,
+	Geo:               []geo.Result{{Code: "IR", Count: 5, BytesUp: 300, BytesDown: 400}},
+}
+
+func TestStatsdLines(t *testing.T) {
+	tests := []struct {
+		tagged   bool
+		expected []string
+	}{
+		{false, []string{
+			"conduit.connecting_clients:1|g",
+			"conduit.connected_clients:7|g",
+			"conduit.bytes_uploaded:1000|g",
+			"conduit.bytes_downloaded:2000|g",
+			"conduit.uptime_seconds:60|g",
+			"conduit.idle_seconds:0|g",
+			"conduit.is_live:1|g",
+			"conduit.geo.connected_clients.IR:5|g",
+			"conduit.geo.bytes_uploaded.IR:300|g",
+			"conduit.geo.bytes_downloaded.IR:400|g",
+		}},
+		{true, []string{
+			"conduit.connecting_clients:1|g|#station:berlin 1,proxy_id:proxy=id",
+			"conduit.connected_clients:7|g|#station:berlin 1,proxy_id:proxy=id",
+			"conduit.bytes_uploaded:1000|g|#station:berlin 1,proxy_id:proxy=id",
+			"conduit.bytes_downloaded:2000|g|#station:berlin 1,proxy_id:proxy=id",
+			"conduit.uptime_seconds:60|g|#station:berlin 1,proxy_id:proxy=id",
+			"conduit.idle_seconds:0|g|#station:berlin 1,proxy_id:proxy=id",
+			"conduit.is_live:1|g|#station:berlin 1,proxy_id:proxy=id",
+			"conduit.geo.connected_clients:5|g|#station:berlin 1,proxy_id:proxy=id,country:IR",
+			"conduit.geo.bytes_uploaded:300|g|#station:berlin 1,proxy_id:proxy=id,country:IR",
+			"conduit.geo.bytes_downloaded:400|g|#station:berlin 1,proxy_id:proxy=id,country:IR",
+		}},
+	}
+	for _, tt := range tests {
+		lines := statsdLines(testStats, "conduit", tt.tagged)
+		if strings.Join(lines, "\n") != strings.Join(tt.expected, "\n") {
+			t.Fatalf("statsdLines(tagged=%v) =\n%s\nexpected\n%s", tt.tagged, strings.Join(lines, "\n"), strings.Join(tt.expected, "\n"))
+		}
+	}
+}
+
+func TestInfluxLines(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	tests := []struct {
+		precision string
+		timestamp string
+	}{
+		{"", "1700000000000000000"},
+		{"ns", "1700000000000000000"},
+		{"ms", "1700000000000"},
+		{"s", "1700000000"},
+	}
+	for _, tt := range tests {
+		expected := `conduit,station=berlin\ 1,proxy_id=proxy\=id connecting_clients=1i,connected_clients=7i,bytes_uploaded=1000i,bytes_downloaded=2000i,uptime_seconds=60i,idle_seconds=0i,is_live=true ` + tt.timestamp + "\n" +
+			`conduit_geo,station=berlin\ 1,proxy_id=proxy\=id,country=IR connected_clients=5i,bytes_uploaded=300i,bytes_downloaded=400i ` + tt.timestamp + "\n"
+		if got := string(influxLines(testStats, now, tt.precision)); got != expected {
+			t.Fatalf("influxLines(precision=%q) =\n%s\nexpected\n%s", tt.precision, got, expected)
+		}
+	}
+
+	// Empty tags are omitted
+	got := string(influxLines(conduit.StatsJSON{}, now, "s"))
+	if !strings.HasPrefix(got, "conduit connecting_clients=0i,") {
+		t.Fatalf("influxLines without identity = %q", got)
+	}
+}
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		target string
+		valid  bool
+	}{
+		{"statsd://127.0.0.1:8125", true},
+		{"dogstatsd://127.0.0.1:8125", true},
+		{"statsd://127.0.0.1", false},
+		{"http://influx:8086/api/v2/write?org=o&bucket=b", true},
+		{"https://influx/write?db=conduit", true},
+		{"http:///write", false},
+		{"graphite://127.0.0.1:2003", false},
+	}
+	for _, tt := range tests {
+		p, err := New(tt.target, Options{})
+		if (err == nil) != tt.valid {
+			t.Fatalf("New(%q) = %v, expected valid=%v", tt.target, err, tt.valid)
+		}
+		if p != nil && p.conn != nil {
+			p.conn.Close()
+		}
+	}
+}
+
+func TestPushStatsd(t *testing.T) {
+	listener, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	defer listener.Close()
+
+	p, err := New("dogstatsd://"+listener.LocalAddr().String(), Options{Prefix: "test"})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	defer p.conn.Close()
+
+	// Enough countries to need more than one packet
+	stats := testStats
+	for i := 0; i < 50; i++ {
+		stats.Geo = append(stats.Geo, geo.Result{Code: string(rune('A'+i/26)) + string(rune('A'+i%26)), Count: i})
+	}
+	if err := p.Push(context.Background(), stats, time.Now()); err != nil {
+		t.Fatalf("Push: %v", err)
+	}
+
+	var received []string
+	buf := make([]byte, 65536)
+	expected := len(statsdLines(stats, "test", true))
+	for len(received) < expected {
+		listener.SetReadDeadline(time.Now().Add(5 * time.Second))
+		n, _, err := listener.ReadFrom(buf)
+		if err != nil {
+			t.Fatalf("read after %d lines: %v", len(received), err)
+		}
+		if n > maxPacketSize {
+			t.Fatalf("packet of %d bytes exceeds %d", n, maxPacketSize)
+		}
+		received = append(received, strings.Split(string(buf[:n]), "\n")...)
+	}
+	if received[1] != "test.connected_clients:7|g|#station:berlin 1,proxy_id:proxy=id" {
+		t.Fatalf("unexpected line %q", received[1])
+	}
+}
+
+func TestPushInflux(t *testing.T) {
+	var body, auth, precision string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		body, auth, precision = string(b), r.Header.Get("Authorization"), r.URL.Query().Get("precision")
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	p, err := New(server.URL+"/api/v2/write?org=o&bucket=b&precision=s", Options{Token: "secret"})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if err := p.Push(context.Background(), testStats, time.Unix(1700000000, 0)); err != nil {
+		t.Fatalf("Push: %v", err)
+	}
+	if auth != "Token secret" || precision != "s" {
+		t.Fatalf("Authorization = %q, precision = %q", auth, precision)
+	}
+	if !strings.Contains(body, "connected_clients=7i") || !strings.HasSuffix(body, " 1700000000\n") {
+		t.Fatalf("unexpected body %q", body)
+	}
+	if p.String() != server.URL+"/api/v2/write" {
+		t.Fatalf("String() = %q", p.String())
+	}
+
+	// Errors include the server's message
+	rejecting := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "unauthorized access", http.StatusUnauthorized)
+	}))
+	defer rejecting.Close()
+	p, _ = New(rejecting.URL+"/write?db=conduit", Options{})
+	if err := p.Push(context.Background(), testStats, time.Now()); err == nil || !strings.Contains(err.Error(), "unauthorized access") {
+		t.Fatalf("Push to rejecting server = %v", err)
+	}
+}
+
+func TestStart(t *testing.T) {
+	received := make(chan string, 10)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		received <- string(b)
+	}))
+	defer server.Close()
+
+	p, err := New(server.URL+"/write?db=conduit", Options{Interval: 10 * time.Millisecond})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	running := make(chan bool, 1)
+	p.Start(ctx, func() (conduit.StatsJSON, bool) {
+		// Nothing is pushed until a service is running
+		select {
+		case <-running:
+			return testStats, true
+		default:
+			return conduit.StatsJSON{}, false
+		}
+	})
+
+	select {
+	case body := <-received:
+		t.Fatalf("pushed before the service was running: %q", body)
+	case <-time.After(50 * time.Millisecond):
+	}
+	running <- true
+	select {
+	case body := <-received:
+		if !strings.Contains(body, "station=berlin\\ 1") {
+			t.Fatalf("unexpected body %q", body)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("no push received")
+	}
+}
@@ -0,0 +1,69 @@
+/*
+ * Copyright (c) 2026, Psiphon Inc.
+ * All rights reserved.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package remoteconfig
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/Psiphon-Inc/conduit/cli/internal/config"
+)
+
+// cached is the last good config with its signature, in one file so the
+// two are replaced together
+type cached struct {
+	URL       string    `json:"url"`
+	Fetched   time.Time `json:"fetched"`
+	Config    []byte    `json:"config"`
+	Signature []byte    `json:"signature"`
+}
+
+// save caches a verified config and its signature
+func (s *Source) save(data, sig []byte) error {
+	out, err := json.Marshal(cached{URL: s.opts.URL, Fetched: time.Now().UTC(), Config: data, Signature: sig})
+	if err != nil {
+		return err
+	}
+	return config.WriteFileAtomic(s.CachePath(), out, 0600)
+}
+
+// loadCache returns the cached config and its version, if it was fetched
+// from the configured URL and its signature still verifies against the
+// pinned key
+func (s *Source) loadCache() ([]byte, int64, error) {
+	raw, err := os.ReadFile(s.CachePath())
+	if err != nil {
+		return nil, 0, err
+	}
+	var c cached
+	if err := json.Unmarshal(raw, &c); err != nil {
+		return nil, 0, fmt.Errorf("corrupt cache %s: %w", s.CachePath(), err)
+	}
+	if c.URL != s.opts.URL {
+		return nil, 0, fmt.Errorf("cache %s is for another config URL (%s)", s.CachePath(), c.URL)
+	}
+	version, err := Verify(s.opts.PublicKey, c.Config, c.Signature)
+	if err != nil {
+		return nil, 0, fmt.Errorf("cache %s: %w", s.CachePath(), err)
+	}
+	return c.Config, version, nil
+}
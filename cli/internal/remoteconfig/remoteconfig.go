@@ -0,0 +1,281 @@
+/*
+ * Copyright (c) 2026, Psiphon Inc.
+ * All rights reserved.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+// Package remoteconfig fetches the Psiphon config from a URL, so stations
+// pick up new broker specs without a new binary. Configs are signed with
+// Ed25519 and verified against a pinned public key; the last good config is
+// cached in the data dir for when the URL is unreachable. The signature
+// covers the config's ConfigVersion, which must increase with every config
+// published, so an old config can't be replayed.
+package remoteconfig
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Psiphon-Inc/conduit/cli/internal/config"
+	"github.com/Psiphon-Inc/conduit/cli/internal/crypto"
+	"github.com/Psiphon-Inc/conduit/cli/internal/periodic"
+)
+
+const (
+	// DefaultInterval is how often the config is refreshed
+	DefaultInterval = time.Hour
+
+	// MinInterval keeps stations from polling the config host too often
+	MinInterval = time.Minute
+
+	cacheFileName   = "psiphon_config.remote.json"
+	maxConfigSize   = 4 * 1024 * 1024
+	downloadTimeout = 30 * time.Second
+)
+
+// Options configures a Source
+type Options struct {
+	URL       string
+	PublicKey ed25519.PublicKey
+	Interval  time.Duration
+	DataDir   string       // Where the last good config is cached
+	Client    *http.Client // Optional
+}
+
+// Source is a Psiphon config fetched from a URL
+type Source struct {
+	opts    Options
+	sigURL  string
+	mu      sync.Mutex
+	data    []byte
+	version int64 // ConfigVersion of data
+	updated chan struct{}
+}
+
+// New returns a Source for opts. The signature is fetched from the config
+// URL with ".sig" appended to the path. The URL must be HTTPS: the signature
+// stops a server from forging configs, but only TLS stops a network attacker
+// from withholding new ones.
+func New(opts Options) (*Source, error) {
+	u, err := url.Parse(opts.URL)
+	if err != nil || u.Scheme != "https" || u.Host == "" {
+		return nil, fmt.Errorf("invalid config URL %q: must be an https URL", opts.URL)
+	}
+	if len(opts.PublicKey) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("a remote config requires an Ed25519 public key")
+	}
+	if opts.Interval == 0 {
+		opts.Interval = DefaultInterval
+	}
+	if opts.Interval < MinInterval {
+		return nil, fmt.Errorf("config refresh interval must be at least %s", MinInterval)
+	}
+	if opts.Client == nil {
+		opts.Client = &http.Client{Timeout: downloadTimeout}
+	}
+	u.Path += ".sig"
+	u.RawPath = ""
+	return &Source{opts: opts, sigURL: u.String(), updated: make(chan struct{}, 1)}, nil
+}
+
+// Verify checks a config against its signature, and that the config is
+// usable. It returns the config's ConfigVersion.
+func Verify(key ed25519.PublicKey, data, signature []byte) (int64, error) {
+	if err := crypto.VerifySignature(key, data, signature); err != nil {
+		return 0, err
+	}
+	for _, issue := range config.ValidatePsiphonConfig(data, nil) {
+		if issue.Severity == config.SeverityError {
+			return 0, fmt.Errorf("invalid config: %s", strings.TrimSpace(issue.Key+" "+issue.Message))
+		}
+	}
+	return configVersion(data)
+}
+
+// configVersion returns the ConfigVersion of a remote config: an integer,
+// or a string holding one, e.g. 2026101801
+func configVersion(data []byte) (int64, error) {
+	var fields struct {
+		ConfigVersion json.RawMessage
+	}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return 0, fmt.Errorf("invalid config: %w", err)
+	}
+	raw := string(fields.ConfigVersion)
+	if unquoted, err := strconv.Unquote(raw); err == nil {
+		raw = unquoted
+	}
+	version, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil || version < 0 {
+		return 0, fmt.Errorf("invalid config: remote configs must set ConfigVersion to an integer that increases with every config")
+	}
+	return version, nil
+}
+
+// String returns the config URL
+func (s *Source) String() string {
+	return s.opts.URL
+}
+
+// CachePath returns where the last good config is kept
+func (s *Source) CachePath() string {
+	return filepath.Join(s.opts.DataDir, cacheFileName)
+}
+
+// Load fetches and verifies the config, falling back to the last good
+// config in the cache. A fetched config older than the cached one is
+// rejected. fromCache is set when the fetch failed; the fetch error is
+// returned with it.
+func (s *Source) Load(ctx context.Context) (data []byte, fromCache bool, err error) {
+	cachedData, cachedVersion, cacheErr := s.loadCache()
+
+	data, sig, version, fetchErr := s.fetch(ctx)
+	if fetchErr == nil && cacheErr == nil {
+		fetchErr = newer(data, version, cachedData, cachedVersion)
+	}
+	if fetchErr == nil {
+		s.set(data, version)
+		if err := s.save(data, sig); err != nil {
+			fmt.Printf("[WARN] Failed to cache Psiphon config: %v\n", err)
+		}
+		return data, false, nil
+	}
+
+	if cacheErr != nil {
+		return nil, false, fmt.Errorf("%w (no cached config: %v)", fetchErr, cacheErr)
+	}
+	s.set(cachedData, cachedVersion)
+	return cachedData, true, fetchErr
+}
+
+// newer checks that a fetched config may replace the current one: it is the
+// same config, or its version is higher
+func newer(data []byte, version int64, current []byte, currentVersion int64) error {
+	if bytes.Equal(data, current) || version > currentVersion {
+		return nil
+	}
+	return fmt.Errorf("rejected config with ConfigVersion %d: not newer than the current version %d", version, currentVersion)
+}
+
+// Config returns the current config
+func (s *Source) Config() []byte {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.data
+}
+
+// Updated receives when Start fetched a config that differs from the
+// current one
+func (s *Source) Updated() <-chan struct{} {
+	return s.updated
+}
+
+// Start refreshes the config every interval until ctx is cancelled
+func (s *Source) Start(ctx context.Context) {
+	go periodic.Task{
+		Interval:  s.opts.Interval,
+		Failed:    "Failed to refresh Psiphon config, keeping the current one",
+		Recovered: "Refreshing Psiphon config again",
+	}.Run(ctx, func(ctx context.Context) error {
+		changed, err := s.Refresh(ctx)
+		if changed {
+			select {
+			case s.updated <- struct{}{}:
+			default:
+			}
+		}
+		return err
+	})
+}
+
+// Refresh fetches the config once and reports whether it changed. Configs
+// that are not newer than the current one are rejected.
+func (s *Source) Refresh(ctx context.Context) (changed bool, err error) {
+	data, sig, version, err := s.fetch(ctx)
+	if err != nil {
+		return false, err
+	}
+	s.mu.Lock()
+	current, currentVersion := s.data, s.version
+	s.mu.Unlock()
+	if bytes.Equal(data, current) {
+		return false, nil
+	}
+	if current != nil {
+		if err := newer(data, version, current, currentVersion); err != nil {
+			return false, err
+		}
+	}
+	s.set(data, version)
+	if err := s.save(data, sig); err != nil {
+		fmt.Printf("[WARN] Failed to cache Psiphon config: %v\n", err)
+	}
+	return true, nil
+}
+
+func (s *Source) set(data []byte, version int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.data, s.version = data, version
+}
+
+// fetch downloads and verifies the config and its signature
+func (s *Source) fetch(ctx context.Context) (data, sig []byte, version int64, err error) {
+	if data, err = s.get(ctx, s.opts.URL); err != nil {
+		return nil, nil, 0, err
+	}
+	if sig, err = s.get(ctx, s.sigURL); err != nil {
+		return nil, nil, 0, err
+	}
+	if version, err = Verify(s.opts.PublicKey, data, sig); err != nil {
+		return nil, nil, 0, fmt.Errorf("rejected config from %s: %w", s.opts.URL, err)
+	}
+	return data, sig, version, nil
+}
+
+func (s *Source) get(ctx context.Context, rawURL string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := s.opts.Client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch %s: %w", rawURL, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch %s: %s", rawURL, resp.Status)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxConfigSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch %s: %w", rawURL, err)
+	}
+	if len(data) > maxConfigSize {
+		return nil, fmt.Errorf("failed to fetch %s: larger than %d bytes", rawURL, maxConfigSize)
+	}
+	return data, nil
+}
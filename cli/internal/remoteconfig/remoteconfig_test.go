@@ -0,0 +1,225 @@
+/*
+ * Copyright (c) 2026, Psiphon Inc.
+ * All rights reserved.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package remoteconfig
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+)
+
+const (
+	configA = `{"PropagationChannelId":"0123456789ABCDEF","SponsorId":"0123456789ABCDEF","AdditionalParameters":"a","ConfigVersion":1}`
+	configB = `{"PropagationChannelId":"0123456789ABCDEF","SponsorId":"0123456789ABCDEF","AdditionalParameters":"b","ConfigVersion":"2"}`
+)
+
+// configServer serves a config at /config.json and its signature at
+// /config.json.sig
+type configServer struct {
+	mu   sync.Mutex
+	data string
+	sig  string
+	down bool
+}
+
+func (c *configServer) set(data, sig string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.data, c.sig = data, sig
+}
+
+func (c *configServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	switch {
+	case c.down:
+		http.Error(w, "unavailable", http.StatusServiceUnavailable)
+	case r.URL.Path == "/config.json":
+		w.Write([]byte(c.data))
+	case r.URL.Path == "/config.json.sig":
+		w.Write([]byte(c.sig))
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func sign(key ed25519.PrivateKey, data string) string {
+	return base64.StdEncoding.EncodeToString(ed25519.Sign(key, []byte(data))) + "\n"
+}
+
+func newTestSource(t *testing.T) (*Source, *configServer, ed25519.PrivateKey) {
+	public, private, _ := ed25519.GenerateKey(nil)
+	cs := &configServer{}
+	cs.set(configA, sign(private, configA))
+	server := httptest.NewTLSServer(cs)
+	t.Cleanup(server.Close)
+
+	source, err := New(Options{URL: server.URL + "/config.json", PublicKey: public, DataDir: t.TempDir(), Client: server.Client()})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	return source, cs, private
+}
+
+func TestLoad(t *testing.T) {
+	source, cs, _ := newTestSource(t)
+
+	data, fromCache, err := source.Load(context.Background())
+	if err != nil || fromCache || string(data) != configA {
+		t.Fatalf("Load = %q, %v, %v", data, fromCache, err)
+	}
+	if info, err := os.Stat(source.CachePath()); err != nil || info.Mode().Perm() != 0600 {
+		t.Fatalf("cache not written: %v", err)
+	}
+
+	// Unreachable: the last good config is used
+	cs.mu.Lock()
+	cs.down = true
+	cs.mu.Unlock()
+	data, fromCache, err = source.Load(context.Background())
+	if err == nil || !fromCache || string(data) != configA {
+		t.Fatalf("Load while down = %q, %v, %v", data, fromCache, err)
+	}
+
+	// A tampered cache is not used
+	raw, _ := os.ReadFile(source.CachePath())
+	tampered := strings.Replace(string(raw), base64.StdEncoding.EncodeToString([]byte(configA)), base64.StdEncoding.EncodeToString([]byte(configB)), 1)
+	os.WriteFile(source.CachePath(), []byte(tampered), 0600)
+	if data, _, err := source.Load(context.Background()); err == nil || data != nil {
+		t.Fatalf("Load with tampered cache = %q, %v", data, err)
+	}
+}
+
+func TestLoadRejects(t *testing.T) {
+	_, otherKey, _ := ed25519.GenerateKey(nil)
+	const configNoVersion = `{"PropagationChannelId":"0123456789ABCDEF","SponsorId":"0123456789ABCDEF","AdditionalParameters":"c"}`
+	tests := []struct {
+		name string
+		data string
+		sig  func(key ed25519.PrivateKey) string
+	}{
+		{"wrong key", configA, func(ed25519.PrivateKey) string { return sign(otherKey, configA) }},
+		{"signature of another config", configA, func(key ed25519.PrivateKey) string { return sign(key, configB) }},
+		{"malformed signature", configA, func(ed25519.PrivateKey) string { return "not a signature" }},
+		{"invalid config", `{"SponsorId":"0123"}`, func(key ed25519.PrivateKey) string { return sign(key, `{"SponsorId":"0123"}`) }},
+		{"no ConfigVersion", configNoVersion, func(key ed25519.PrivateKey) string { return sign(key, configNoVersion) }},
+	}
+	for _, tt := range tests {
+		source, cs, key := newTestSource(t)
+		cs.set(tt.data, tt.sig(key))
+		data, _, err := source.Load(context.Background())
+		if err == nil || data != nil {
+			t.Fatalf("%s: Load = %q, %v", tt.name, data, err)
+		}
+		if _, err := os.Stat(source.CachePath()); !os.IsNotExist(err) {
+			t.Fatalf("%s: rejected config was cached", tt.name)
+		}
+	}
+}
+
+func TestRefresh(t *testing.T) {
+	source, cs, key := newTestSource(t)
+	if _, _, err := source.Load(context.Background()); err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+
+	if changed, err := source.Refresh(context.Background()); changed || err != nil {
+		t.Fatalf("Refresh without change = %v, %v", changed, err)
+	}
+
+	// A bad update keeps the current config
+	cs.set(configB, sign(key, configA))
+	if changed, err := source.Refresh(context.Background()); changed || err == nil || string(source.Config()) != configA {
+		t.Fatalf("Refresh with bad signature = %v, %v, config %q", changed, err, source.Config())
+	}
+
+	cs.set(configB, sign(key, configB))
+	if changed, err := source.Refresh(context.Background()); !changed || err != nil || string(source.Config()) != configB {
+		t.Fatalf("Refresh with update = %v, %v, config %q", changed, err, source.Config())
+	}
+	cached, version, err := source.loadCache()
+	if err != nil || string(cached) != configB || version != 2 {
+		t.Fatalf("cache = %q, %d, %v", cached, version, err)
+	}
+
+	// An older, validly signed config is not replayed
+	cs.set(configA, sign(key, configA))
+	if changed, err := source.Refresh(context.Background()); changed || err == nil || string(source.Config()) != configB {
+		t.Fatalf("Refresh with older config = %v, %v, config %q", changed, err, source.Config())
+	}
+}
+
+func TestLoadRejectsOlderThanCache(t *testing.T) {
+	source, cs, key := newTestSource(t)
+	cs.set(configB, sign(key, configB))
+	if _, _, err := source.Load(context.Background()); err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+
+	// On restart, an older config from the URL loses to the cache
+	cs.set(configA, sign(key, configA))
+	data, fromCache, err := source.Load(context.Background())
+	if err == nil || !fromCache || string(data) != configB {
+		t.Fatalf("Load with older config = %q, %v, %v", data, fromCache, err)
+	}
+}
+
+func TestCacheForAnotherURL(t *testing.T) {
+	source, cs, _ := newTestSource(t)
+	if _, _, err := source.Load(context.Background()); err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+
+	// The station is moved to another URL, which is down
+	moved, err := New(Options{URL: source.opts.URL + "?moved", PublicKey: source.opts.PublicKey, DataDir: source.opts.DataDir, Client: source.opts.Client})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	cs.mu.Lock()
+	cs.down = true
+	cs.mu.Unlock()
+	if data, _, err := moved.Load(context.Background()); err == nil || data != nil {
+		t.Fatalf("Load used the cache of another URL: %q, %v", data, err)
+	}
+}
+
+func TestNew(t *testing.T) {
+	public, _, _ := ed25519.GenerateKey(nil)
+	source, err := New(Options{URL: "https://example.com/conduit/config.json?v=1", PublicKey: public})
+	if err != nil || source.sigURL != "https://example.com/conduit/config.json.sig?v=1" {
+		t.Fatalf("New = %v, %v", source, err)
+	}
+	for _, opts := range []Options{
+		{URL: "ftp://example.com/config.json", PublicKey: public},
+		{URL: "http://example.com/config.json", PublicKey: public},
+		{URL: "https://example.com/config.json"},
+		{URL: "https://example.com/config.json", PublicKey: public, Interval: 1},
+	} {
+		if _, err := New(opts); err == nil {
+			t.Fatalf("New(%+v) succeeded", opts)
+		}
+	}
+}
@@ -0,0 +1,155 @@
+/*
+ * Copyright (c) 2026, Psiphon Inc.
+ * All rights reserved.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+// Package ryve builds the claim payloads used to associate a station with the Ryve app
+package ryve
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Psiphon-Inc/conduit/cli/internal/crypto"
+)
+
+// ClaimURIPrefix is the Ryve deep link that claim payloads are appended to
+const ClaimURIPrefix = "network.ryve.app://(app)/conduits?claim="
+
+// Claim payload versions
+const (
+	VersionKey    = 1 // Contains the station private key
+	VersionSigned = 2 // Contains only the public key and a signed ownership statement
+)
+
+// statementPrefix domain-separates claim signatures from anything else the key signs
+const statementPrefix = "conduit-ryve-claim-v2"
+
+// Payload is the JSON structure encoded in a claim
+type Payload struct {
+	Version int             `json:"version"`
+	Data    json.RawMessage `json:"data"`
+}
+
+// KeyClaim is the data of a version 1 claim
+type KeyClaim struct {
+	Name string `json:"name"`
+	Key  string `json:"key"`
+}
+
+// SignedClaim is the data of a version 2 claim
+type SignedClaim struct {
+	Name      string `json:"name"`
+	PublicKey string `json:"publicKey"`
+	Timestamp string `json:"timestamp"`
+	Challenge string `json:"challenge,omitempty"`
+	Signature string `json:"signature"`
+}
+
+// NewKeyClaim builds a version 1 claim. It reveals the station private key.
+func NewKeyClaim(kp *crypto.KeyPair, name string) ([]byte, error) {
+	keyData, err := crypto.KeyPairToBase64NoPad(kp)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get keypair data: %w", err)
+	}
+	return marshalPayload(VersionKey, KeyClaim{Name: name, Key: keyData})
+}
+
+// NewSignedClaim builds a version 2 claim: the station signs a statement binding
+// its public key to name, the current time and an optional challenge from Ryve
+func NewSignedClaim(kp *crypto.KeyPair, name, challenge string, now time.Time) ([]byte, error) {
+	if kp == nil || len(kp.PrivateKey) != ed25519.PrivateKeySize {
+		return nil, errors.New("invalid key pair")
+	}
+
+	claim := SignedClaim{
+		Name:      name,
+		PublicKey: base64.RawStdEncoding.EncodeToString(kp.PublicKey),
+		Timestamp: now.UTC().Format(time.RFC3339),
+		Challenge: challenge,
+	}
+	signature := ed25519.Sign(ed25519.PrivateKey(kp.PrivateKey), claim.statement())
+	claim.Signature = base64.RawStdEncoding.EncodeToString(signature)
+
+	return marshalPayload(VersionSigned, claim)
+}
+
+// VerifySignedClaim checks the signature of a version 2 claim payload and
+// returns its data
+func VerifySignedClaim(payloadJSON []byte) (*SignedClaim, error) {
+	var payload Payload
+	if err := json.Unmarshal(payloadJSON, &payload); err != nil {
+		return nil, fmt.Errorf("failed to parse claim: %w", err)
+	}
+	if payload.Version != VersionSigned {
+		return nil, fmt.Errorf("unsupported claim version %d", payload.Version)
+	}
+
+	var claim SignedClaim
+	if err := json.Unmarshal(payload.Data, &claim); err != nil {
+		return nil, fmt.Errorf("failed to parse claim data: %w", err)
+	}
+
+	publicKey, err := base64.RawStdEncoding.DecodeString(claim.PublicKey)
+	if err != nil || len(publicKey) != ed25519.PublicKeySize {
+		return nil, errors.New("invalid public key")
+	}
+	signature, err := base64.RawStdEncoding.DecodeString(claim.Signature)
+	if err != nil {
+		return nil, errors.New("invalid signature encoding")
+	}
+	if !ed25519.Verify(publicKey, claim.statement(), signature) {
+		return nil, errors.New("invalid signature")
+	}
+	return &claim, nil
+}
+
+// statement returns the bytes signed for a version 2 claim. Fields are
+// newline-separated, and newlines are stripped from the free-form fields so
+// the encoding is unambiguous.
+func (c SignedClaim) statement() []byte {
+	clean := func(s string) string { return strings.ReplaceAll(s, "\n", " ") }
+	return []byte(strings.Join([]string{
+		statementPrefix,
+		c.PublicKey,
+		clean(c.Name),
+		c.Timestamp,
+		clean(c.Challenge),
+	}, "\n"))
+}
+
+// URI returns the Ryve deep link for a claim payload
+func URI(payloadJSON []byte) string {
+	return ClaimURIPrefix + base64.URLEncoding.EncodeToString(payloadJSON)
+}
+
+func marshalPayload(version int, data any) ([]byte, error) {
+	dataJSON, err := json.Marshal(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal claim data: %w", err)
+	}
+	payloadJSON, err := json.Marshal(Payload{Version: version, Data: dataJSON})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal claim: %w", err)
+	}
+	return payloadJSON, nil
+}
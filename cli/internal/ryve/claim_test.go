@@ -0,0 +1,118 @@
+package ryve
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Psiphon-Inc/conduit/cli/internal/crypto"
+)
+
+func TestSignedClaim(t *testing.T) {
+	kp, err := crypto.GenerateKeyPair()
+	if err != nil {
+		t.Fatalf("GenerateKeyPair: %v", err)
+	}
+	now := time.Date(2026, 1, 25, 15, 44, 0, 0, time.UTC)
+
+	payload, err := NewSignedClaim(kp, "my station", "abc123", now)
+	if err != nil {
+		t.Fatalf("NewSignedClaim: %v", err)
+	}
+
+	// The private key (or its seed half) must never be in a signed claim
+	privateKey, _ := crypto.KeyPairToBase64NoPad(kp)
+	seed := base64.RawStdEncoding.EncodeToString(kp.PrivateKey[:32])
+	if strings.Contains(string(payload), privateKey) || strings.Contains(string(payload), seed) {
+		t.Fatalf("signed claim contains private key material: %s", payload)
+	}
+
+	claim, err := VerifySignedClaim(payload)
+	if err != nil {
+		t.Fatalf("VerifySignedClaim: %v", err)
+	}
+	if claim.Name != "my station" || claim.Timestamp != "2026-01-25T15:44:00Z" || claim.Challenge != "abc123" {
+		t.Fatalf("unexpected claim data: %+v", claim)
+	}
+	if claim.PublicKey != crypto.PublicKeyBase64(kp) {
+		t.Fatalf("claim public key = %s, expected %s", claim.PublicKey, crypto.PublicKeyBase64(kp))
+	}
+
+	// Any change to the signed fields invalidates the claim
+	for _, field := range []string{"name", "timestamp", "challenge"} {
+		var p struct {
+			Version int            `json:"version"`
+			Data    map[string]any `json:"data"`
+		}
+		if err := json.Unmarshal(payload, &p); err != nil {
+			t.Fatalf("unmarshal: %v", err)
+		}
+		p.Data[field] = "tampered"
+		tampered, _ := json.Marshal(p)
+		if _, err := VerifySignedClaim(tampered); err == nil {
+			t.Fatalf("tampered %s was accepted", field)
+		}
+	}
+}
+
+func TestKeyClaim(t *testing.T) {
+	kp, err := crypto.GenerateKeyPair()
+	if err != nil {
+		t.Fatalf("GenerateKeyPair: %v", err)
+	}
+
+	payload, err := NewKeyClaim(kp, "station")
+	if err != nil {
+		t.Fatalf("NewKeyClaim: %v", err)
+	}
+
+	var p struct {
+		Version int      `json:"version"`
+		Data    KeyClaim `json:"data"`
+	}
+	if err := json.Unmarshal(payload, &p); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	expectedKey, _ := crypto.KeyPairToBase64NoPad(kp)
+	if p.Version != VersionKey || p.Data.Name != "station" || p.Data.Key != expectedKey {
+		t.Fatalf("unexpected v1 claim: %s", payload)
+	}
+
+	if _, err := VerifySignedClaim(payload); err == nil {
+		t.Fatalf("VerifySignedClaim accepted a version 1 claim")
+	}
+
+	uri := URI(payload)
+	if !strings.HasPrefix(uri, ClaimURIPrefix) {
+		t.Fatalf("unexpected URI: %s", uri)
+	}
+	decoded, err := base64.URLEncoding.DecodeString(strings.TrimPrefix(uri, ClaimURIPrefix))
+	if err != nil || string(decoded) != string(payload) {
+		t.Fatalf("URI does not round-trip: %v", err)
+	}
+}
+
+func TestQRCodeSVG(t *testing.T) {
+	qr, err := NewQRCode(ClaimURIPrefix + "test")
+	if err != nil {
+		t.Fatalf("NewQRCode: %v", err)
+	}
+
+	svg := string(qr.SVG())
+	size := len(qr.q.Bitmap())
+	if !strings.HasPrefix(svg, "<svg ") || !strings.HasSuffix(svg, "</svg>\n") {
+		t.Fatalf("malformed SVG: %s", svg)
+	}
+	if !strings.Contains(svg, fmt.Sprintf(`viewBox="0 0 %d %d"`, size, size)) {
+		t.Fatalf("SVG viewBox doesn't match the QR size %d", size)
+	}
+
+	png, err := qr.PNG(64)
+	if err != nil || !bytes.HasPrefix(png, []byte("\x89PNG")) {
+		t.Fatalf("PNG: %v", err)
+	}
+}
@@ -0,0 +1,77 @@
+/*
+ * Copyright (c) 2026, Psiphon Inc.
+ * All rights reserved.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package ryve
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/skip2/go-qrcode"
+)
+
+// QRCode renders a claim URI in the formats supported by 'conduit ryve-claim'
+type QRCode struct {
+	q *qrcode.QRCode
+}
+
+// NewQRCode encodes a claim URI
+func NewQRCode(uri string) (*QRCode, error) {
+	q, err := qrcode.New(uri, qrcode.Low)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate QR code: %w", err)
+	}
+	return &QRCode{q: q}, nil
+}
+
+// PNG returns the QR code as a size x size PNG image
+func (c *QRCode) PNG(size int) ([]byte, error) {
+	return c.q.PNG(size)
+}
+
+// Terminal returns the QR code drawn with unicode half blocks
+func (c *QRCode) Terminal() string {
+	return c.q.ToSmallString(false)
+}
+
+// SVG returns the QR code as an SVG image, one unit per module
+func (c *QRCode) SVG() []byte {
+	bitmap := c.q.Bitmap()
+	size := len(bitmap)
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, `<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 %d %d" shape-rendering="crispEdges">`, size, size)
+	fmt.Fprintf(&buf, `<rect width="%d" height="%d" fill="#fff"/><path fill="#000" d="`, size, size)
+	for y, row := range bitmap {
+		for x := 0; x < len(row); x++ {
+			if !row[x] {
+				continue
+			}
+			// Merge horizontal runs into one rectangle
+			start := x
+			for x+1 < len(row) && row[x+1] {
+				x++
+			}
+			fmt.Fprintf(&buf, "M%d %dh%dv1h-%dz", start, y, x-start+1, x-start+1)
+		}
+	}
+	buf.WriteString(`"/></svg>`)
+	buf.WriteByte('\n')
+	return buf.Bytes()
+}
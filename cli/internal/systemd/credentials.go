@@ -0,0 +1,48 @@
+/*
+ * Copyright (c) 2026, Psiphon Inc.
+ * All rights reserved.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package systemd
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// Credential names looked up in $CREDENTIALS_DIRECTORY (see LoadCredential= in systemd.exec(5))
+const (
+	PsiphonConfigCredential = "psiphon-config"
+	KeyCredential           = "conduit-key"
+	KeyPassphraseCredential = "conduit-key-passphrase"
+)
+
+// CredentialPath returns the path of a credential passed with LoadCredential=
+// or SetCredential=, and whether it exists
+func CredentialPath(name string) (string, bool) {
+	dir := os.Getenv("CREDENTIALS_DIRECTORY")
+	if dir == "" || name == "" {
+		return "", false
+	}
+
+	path := filepath.Join(dir, name)
+	info, err := os.Stat(path)
+	if err != nil || info.IsDir() {
+		return "", false
+	}
+	return path, true
+}
@@ -0,0 +1,124 @@
+/*
+ * Copyright (c) 2026, Psiphon Inc.
+ * All rights reserved.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+// Package systemd implements the parts of the systemd service protocol used by Conduit
+package systemd
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Notifier sends service state updates to systemd over $NOTIFY_SOCKET.
+// A nil Notifier is valid and silently drops all updates, so callers
+// don't need to check whether they are running under systemd.
+type Notifier struct {
+	mu               sync.Mutex
+	addr             *net.UnixAddr
+	watchdogInterval time.Duration
+}
+
+// NewNotifier returns a Notifier for the socket in $NOTIFY_SOCKET,
+// or nil if the process was not started by systemd with Type=notify
+func NewNotifier() *Notifier {
+	socket := os.Getenv("NOTIFY_SOCKET")
+	if socket == "" {
+		return nil
+	}
+
+	// Abstract sockets are passed with a leading '@', which net handles natively
+	n := &Notifier{
+		addr: &net.UnixAddr{Name: socket, Net: "unixgram"},
+	}
+	n.watchdogInterval = watchdogIntervalFromEnv()
+
+	return n
+}
+
+// watchdogIntervalFromEnv returns the watchdog timeout requested via
+// WatchdogSec=, or 0 if the watchdog is disabled or meant for another process
+func watchdogIntervalFromEnv() time.Duration {
+	usec, err := strconv.ParseInt(os.Getenv("WATCHDOG_USEC"), 10, 64)
+	if err != nil || usec <= 0 {
+		return 0
+	}
+	if pid := os.Getenv("WATCHDOG_PID"); pid != "" {
+		if p, err := strconv.Atoi(pid); err != nil || p != os.Getpid() {
+			return 0
+		}
+	}
+	return time.Duration(usec) * time.Microsecond
+}
+
+// Notify sends one or more raw state assignments (e.g. "READY=1")
+func (n *Notifier) Notify(states ...string) error {
+	if n == nil || len(states) == 0 {
+		return nil
+	}
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	conn, err := net.DialUnix("unixgram", nil, n.addr)
+	if err != nil {
+		return fmt.Errorf("failed to connect to notify socket: %w", err)
+	}
+	defer conn.Close()
+
+	if _, err := conn.Write([]byte(strings.Join(states, "\n"))); err != nil {
+		return fmt.Errorf("failed to write to notify socket: %w", err)
+	}
+	return nil
+}
+
+// Ready tells systemd that startup is complete
+func (n *Notifier) Ready() error {
+	return n.Notify("READY=1")
+}
+
+// Status sets the free-form status line shown by systemctl status
+func (n *Notifier) Status(status string) error {
+	// STATUS= is a single line; newlines would start a new assignment
+	return n.Notify("STATUS=" + strings.ReplaceAll(status, "\n", " "))
+}
+
+// Stopping tells systemd that the service is shutting down
+func (n *Notifier) Stopping() error {
+	return n.Notify("STOPPING=1")
+}
+
+// Watchdog sends a keep-alive ping to the systemd watchdog
+func (n *Notifier) Watchdog() error {
+	return n.Notify("WATCHDOG=1")
+}
+
+// WatchdogInterval returns how often Watchdog should be called,
+// or 0 if the watchdog is not enabled for this process
+func (n *Notifier) WatchdogInterval() time.Duration {
+	if n == nil || n.watchdogInterval == 0 {
+		return 0
+	}
+	// Ping at half the timeout, as recommended by sd_watchdog_enabled(3)
+	return n.watchdogInterval / 2
+}
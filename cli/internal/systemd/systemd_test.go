@@ -0,0 +1,252 @@
+/*
+ * Copyright (c) 2026, Psiphon Inc.
+ * All rights reserved.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package systemd
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// listenFakeNotifySocket creates a unixgram socket standing in for systemd's
+// notify socket and points $NOTIFY_SOCKET at it
+func listenFakeNotifySocket(t *testing.T) *net.UnixConn {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "notify.sock")
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: path, Net: "unixgram"})
+	if err != nil {
+		t.Fatalf("listen notify socket: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	t.Setenv("NOTIFY_SOCKET", path)
+	return conn
+}
+
+func readDatagram(t *testing.T, conn *net.UnixConn) string {
+	t.Helper()
+	buf := make([]byte, 4096)
+	if err := conn.SetReadDeadline(time.Now().Add(2 * time.Second)); err != nil {
+		t.Fatalf("set deadline: %v", err)
+	}
+	n, err := conn.Read(buf)
+	if err != nil {
+		t.Fatalf("read notify socket: %v", err)
+	}
+	return string(buf[:n])
+}
+
+func TestNotifierSendsStates(t *testing.T) {
+	conn := listenFakeNotifySocket(t)
+
+	n := NewNotifier()
+	if n == nil {
+		t.Fatalf("NewNotifier returned nil with NOTIFY_SOCKET set")
+	}
+
+	tests := []struct {
+		name     string
+		send     func() error
+		expected string
+	}{
+		{"ready", n.Ready, "READY=1"},
+		{"status", func() error { return n.Status("Connected: 3\nConnecting: 1") }, "STATUS=Connected: 3 Connecting: 1"},
+		{"watchdog", n.Watchdog, "WATCHDOG=1"},
+		{"stopping", n.Stopping, "STOPPING=1"},
+		{"multiple", func() error { return n.Notify("READY=1", "STATUS=ok") }, "READY=1\nSTATUS=ok"},
+	}
+
+	for _, test := range tests {
+		if err := test.send(); err != nil {
+			t.Fatalf("%s: send failed: %v", test.name, err)
+		}
+		if got := readDatagram(t, conn); got != test.expected {
+			t.Fatalf("%s: got %q, expected %q", test.name, got, test.expected)
+		}
+	}
+}
+
+func TestNotifierDisabled(t *testing.T) {
+	t.Setenv("NOTIFY_SOCKET", "")
+
+	n := NewNotifier()
+	if n != nil {
+		t.Fatalf("expected nil Notifier without NOTIFY_SOCKET")
+	}
+	// All methods must be safe on a nil Notifier
+	if err := n.Ready(); err != nil {
+		t.Fatalf("Ready on nil Notifier: %v", err)
+	}
+	if err := n.Status("x"); err != nil {
+		t.Fatalf("Status on nil Notifier: %v", err)
+	}
+	if got := n.WatchdogInterval(); got != 0 {
+		t.Fatalf("WatchdogInterval on nil Notifier = %v, expected 0", got)
+	}
+}
+
+func TestWatchdogInterval(t *testing.T) {
+	listenFakeNotifySocket(t)
+
+	tests := []struct {
+		name     string
+		usec     string
+		pid      string
+		expected time.Duration
+	}{
+		{"unset", "", "", 0},
+		{"enabled", "60000000", "", 30 * time.Second},
+		{"own_pid", "10000000", strconv.Itoa(os.Getpid()), 5 * time.Second},
+		{"other_pid", "10000000", "1", 0},
+		{"invalid", "abc", "", 0},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Setenv("WATCHDOG_USEC", test.usec)
+			t.Setenv("WATCHDOG_PID", test.pid)
+			if got := NewNotifier().WatchdogInterval(); got != test.expected {
+				t.Fatalf("WatchdogInterval = %v, expected %v", got, test.expected)
+			}
+		})
+	}
+}
+
+func TestCredentialPath(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, PsiphonConfigCredential), []byte("{}"), 0600); err != nil {
+		t.Fatalf("write credential: %v", err)
+	}
+
+	t.Setenv("CREDENTIALS_DIRECTORY", "")
+	if _, ok := CredentialPath(PsiphonConfigCredential); ok {
+		t.Fatalf("expected no credential without CREDENTIALS_DIRECTORY")
+	}
+
+	t.Setenv("CREDENTIALS_DIRECTORY", dir)
+	path, ok := CredentialPath(PsiphonConfigCredential)
+	if !ok || path != filepath.Join(dir, PsiphonConfigCredential) {
+		t.Fatalf("CredentialPath = %q, %v", path, ok)
+	}
+	if _, ok := CredentialPath(KeyCredential); ok {
+		t.Fatalf("expected missing credential to be reported as absent")
+	}
+}
+
+func TestRenderUnit(t *testing.T) {
+	unit, err := RenderUnit(UnitOptions{
+		BinaryPath:        "/usr/local/bin/conduit",
+		PsiphonConfigPath: "/etc/conduit/psiphon_config.json",
+		KeyPassphrasePath: "/etc/conduit/key-passphrase",
+		ExtraArgs:         []string{"--max-clients", "200", "--stats-file", "my stats.json"},
+		WatchdogSec:       120,
+	})
+	if err != nil {
+		t.Fatalf("RenderUnit: %v", err)
+	}
+
+	for _, line := range []string{
+		"Type=notify",
+		`ExecStart=/usr/local/bin/conduit start --data-dir /var/lib/conduit --max-clients 200 --stats-file "my stats.json"`,
+		"WatchdogSec=120",
+		"DynamicUser=yes",
+		"StateDirectory=conduit",
+		"LoadCredential=psiphon-config:/etc/conduit/psiphon_config.json",
+		"LoadCredential=conduit-key-passphrase:/etc/conduit/key-passphrase",
+		"NoNewPrivileges=yes",
+		"ProtectSystem=strict",
+		"CapabilityBoundingSet=",
+		"SystemCallFilter=~@privileged",
+	} {
+		if !strings.Contains(unit, line+"\n") {
+			t.Fatalf("unit missing %q:\n%s", line, unit)
+		}
+	}
+	if strings.Contains(unit, KeyCredential+":") {
+		t.Fatalf("unit should not load a key credential when KeyPath is empty")
+	}
+
+	unit, err = RenderUnit(UnitOptions{BinaryPath: "/usr/bin/conduit", User: "conduit"})
+	if err != nil {
+		t.Fatalf("RenderUnit: %v", err)
+	}
+	if !strings.Contains(unit, "User=conduit\n") || strings.Contains(unit, "DynamicUser") {
+		t.Fatalf("expected explicit user instead of DynamicUser:\n%s", unit)
+	}
+
+	// Geo stats capture packets with tcpdump
+	unit, err = RenderUnit(UnitOptions{BinaryPath: "/usr/bin/conduit", ExtraArgs: []string{"--geo"}})
+	if err != nil {
+		t.Fatalf("RenderUnit: %v", err)
+	}
+	for _, line := range []string{
+		"CapabilityBoundingSet=CAP_NET_RAW CAP_NET_ADMIN",
+		"AmbientCapabilities=CAP_NET_RAW CAP_NET_ADMIN",
+		"RestrictAddressFamilies=AF_UNIX AF_INET AF_INET6 AF_NETLINK AF_PACKET",
+	} {
+		if !strings.Contains(unit, line+"\n") {
+			t.Fatalf("geo unit missing %q:\n%s", line, unit)
+		}
+	}
+	for _, args := range [][]string{{"--geo=false"}, {"--", "--geo"}} {
+		unit, err = RenderUnit(UnitOptions{BinaryPath: "/usr/bin/conduit", ExtraArgs: args})
+		if err != nil {
+			t.Fatalf("RenderUnit: %v", err)
+		}
+		if !strings.Contains(unit, "CapabilityBoundingSet=\n") {
+			t.Fatalf("%v should not grant capabilities:\n%s", args, unit)
+		}
+	}
+
+	// The sandboxed service can't replace its binary
+	for _, args := range [][]string{{"--auto-update"}, {"--geo", "--auto-update=true"}} {
+		if _, err := RenderUnit(UnitOptions{BinaryPath: "/usr/bin/conduit", ExtraArgs: args}); err == nil || !strings.Contains(err.Error(), "conduit update") {
+			t.Fatalf("%v: expected an error pointing to 'conduit update', got %v", args, err)
+		}
+	}
+	for _, args := range [][]string{{"--auto-update=false"}, {"--", "--auto-update"}} {
+		if _, err := RenderUnit(UnitOptions{BinaryPath: "/usr/bin/conduit", ExtraArgs: args}); err != nil {
+			t.Fatalf("%v: RenderUnit: %v", args, err)
+		}
+	}
+
+	if _, err := RenderUnit(UnitOptions{BinaryPath: "conduit"}); err == nil {
+		t.Fatalf("expected error for relative binary path")
+	}
+}
+
+func TestQuoteArg(t *testing.T) {
+	tests := map[string]string{
+		"plain":      "plain",
+		"with 50%":   `"with 50%%"`,
+		"$HOME":      "$$HOME",
+		`a"b`:        `"a\"b"`,
+		"":           `""`,
+		"semi;colon": `"semi;colon"`,
+	}
+	for in, expected := range tests {
+		if got := quoteArg(in); got != expected {
+			t.Fatalf("quoteArg(%q) = %q, expected %q", in, got, expected)
+		}
+	}
+}
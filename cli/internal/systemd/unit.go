@@ -0,0 +1,196 @@
+/*
+ * Copyright (c) 2026, Psiphon Inc.
+ * All rights reserved.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package systemd
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+	"text/template"
+)
+
+// Defaults for generated unit files
+const (
+	DefaultUnitPath = "/etc/systemd/system/conduit.service"
+	StateDirectory  = "conduit"
+	DataDir         = "/var/lib/" + StateDirectory
+)
+
+// UnitOptions configures the generated systemd unit
+type UnitOptions struct {
+	BinaryPath        string   // Absolute path to the conduit binary
+	User              string   // Run as this user (empty = DynamicUser)
+	PsiphonConfigPath string   // Host path passed via LoadCredential (empty = embedded config)
+	KeyPath           string   // Host path to an existing conduit_key.json passed via LoadCredential (optional)
+	KeyPassphrasePath string   // Host path to the key passphrase passed via LoadCredential (optional)
+	ExtraArgs         []string // Additional arguments for 'conduit start'
+	WatchdogSec       int      // Watchdog timeout in seconds (0 = disabled)
+}
+
+// geoCapabilities are needed by the tcpdump capture behind 'start --geo'
+const geoCapabilities = "CAP_NET_RAW CAP_NET_ADMIN"
+
+var unitTemplate = template.Must(template.New("unit").Parse(`[Unit]
+Description=Conduit - Psiphon inproxy station
+Documentation=https://github.com/Psiphon-Inc/conduit
+Wants=network-online.target
+After=network-online.target
+
+[Service]
+Type=notify
+NotifyAccess=main
+ExecStart={{.ExecStart}}
+Restart=on-failure
+RestartSec=10
+TimeoutStopSec=30
+{{- if .WatchdogSec}}
+WatchdogSec={{.WatchdogSec}}
+{{- end}}
+{{- if .User}}
+User={{.User}}
+{{- else}}
+DynamicUser=yes
+{{- end}}
+StateDirectory={{.StateDirectory}}
+StateDirectoryMode=0700
+{{- if .PsiphonConfigPath}}
+LoadCredential={{.PsiphonConfigCredential}}:{{.PsiphonConfigPath}}
+{{- end}}
+{{- if .KeyPath}}
+LoadCredential={{.KeyCredential}}:{{.KeyPath}}
+{{- end}}
+{{- if .KeyPassphrasePath}}
+LoadCredential={{.KeyPassphraseCredential}}:{{.KeyPassphrasePath}}
+{{- end}}
+
+# Hardening
+UMask=0077
+NoNewPrivileges=yes
+{{- if .Geo}}
+CapabilityBoundingSet={{.Capabilities}}
+AmbientCapabilities={{.Capabilities}}
+{{- else}}
+CapabilityBoundingSet=
+AmbientCapabilities=
+{{- end}}
+ProtectSystem=strict
+ProtectHome=yes
+PrivateTmp=yes
+PrivateDevices=yes
+ProtectHostname=yes
+ProtectClock=yes
+ProtectKernelTunables=yes
+ProtectKernelModules=yes
+ProtectKernelLogs=yes
+ProtectControlGroups=yes
+ProtectProc=invisible
+RestrictAddressFamilies=AF_UNIX AF_INET AF_INET6 AF_NETLINK{{if .Geo}} AF_PACKET{{end}}
+RestrictNamespaces=yes
+RestrictRealtime=yes
+RestrictSUIDSGID=yes
+LockPersonality=yes
+MemoryDenyWriteExecute=yes
+SystemCallArchitectures=native
+SystemCallFilter=@system-service
+SystemCallFilter=~@privileged
+
+[Install]
+WantedBy=multi-user.target
+`))
+
+// RenderUnit returns the contents of a hardened systemd unit for 'conduit start'
+func RenderUnit(opts UnitOptions) (string, error) {
+	if opts.BinaryPath == "" {
+		return "", fmt.Errorf("binary path is required")
+	}
+	if !strings.HasPrefix(opts.BinaryPath, "/") {
+		return "", fmt.Errorf("binary path must be absolute: %s", opts.BinaryPath)
+	}
+
+	if flagEnabled(opts.ExtraArgs, "auto-update") {
+		// The unit's sandbox can't write the binary, so updates need root
+		return "", fmt.Errorf("--auto-update doesn't work in the service, which can't replace its binary: run 'conduit update' from a root timer instead")
+	}
+
+	args := []string{opts.BinaryPath, "start", "--data-dir", DataDir}
+	args = append(args, opts.ExtraArgs...)
+
+	quoted := make([]string, len(args))
+	for i, arg := range args {
+		quoted[i] = quoteArg(arg)
+	}
+
+	var buf bytes.Buffer
+	err := unitTemplate.Execute(&buf, struct {
+		UnitOptions
+		ExecStart               string
+		StateDirectory          string
+		PsiphonConfigCredential string
+		KeyCredential           string
+		KeyPassphraseCredential string
+		Geo                     bool
+		Capabilities            string
+	}{
+		UnitOptions:             opts,
+		ExecStart:               strings.Join(quoted, " "),
+		StateDirectory:          StateDirectory,
+		PsiphonConfigCredential: PsiphonConfigCredential,
+		KeyCredential:           KeyCredential,
+		KeyPassphraseCredential: KeyPassphraseCredential,
+		Geo:                     flagEnabled(opts.ExtraArgs, "geo"),
+		Capabilities:            geoCapabilities,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to render unit: %w", err)
+	}
+	return buf.String(), nil
+}
+
+// flagEnabled reports whether the start arguments enable the boolean flag
+// --name
+func flagEnabled(args []string, name string) bool {
+	enabled := false
+	for _, arg := range args {
+		if arg == "--" {
+			break
+		}
+		switch {
+		case arg == "--"+name:
+			enabled = true
+		case strings.HasPrefix(arg, "--"+name+"="):
+			enabled, _ = strconv.ParseBool(strings.TrimPrefix(arg, "--"+name+"="))
+		}
+	}
+	return enabled
+}
+
+// quoteArg quotes a command line argument for ExecStart= if needed.
+// '$' and '%' are escaped so systemd doesn't expand them.
+func quoteArg(arg string) string {
+	arg = strings.ReplaceAll(arg, "%", "%%")
+	arg = strings.ReplaceAll(arg, "$", "$$")
+	if arg != "" && !strings.ContainsAny(arg, " \t\"'\\;") {
+		return arg
+	}
+	arg = strings.ReplaceAll(arg, `\`, `\\`)
+	arg = strings.ReplaceAll(arg, `"`, `\"`)
+	return `"` + arg + `"`
+}
@@ -0,0 +1,466 @@
+/*
+ * Copyright (c) 2026, Psiphon Inc.
+ * All rights reserved.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+// Package tui implements the full-screen terminal UI of 'conduit start --tui'
+package tui
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/Psiphon-Inc/conduit/cli/internal/conduit"
+	"github.com/Psiphon-Inc/conduit/cli/internal/config"
+	"golang.org/x/term"
+)
+
+const (
+	historySize = 120 // Throughput samples kept for sparklines, one per refresh
+	maxEvents   = 200 // Log lines kept for the events panel
+	refresh     = time.Second
+
+	// Limit changes are applied once keys haven't been pressed for this long,
+	// since each change restarts the Psiphon controller
+	applyDelay = 2 * time.Second
+
+	bandwidthStep = 5 * 1000 * 1000 / 8 // 5 Mbps in bytes per second
+)
+
+// Action is a request from the UI to the service loop
+type Action int
+
+const (
+	ActionPause Action = iota
+	ActionResume
+	ActionSetLimits
+)
+
+// Command is sent by the UI when the user pauses, resumes or changes limits
+type Command struct {
+	Action                  Action
+	MaxClients              int
+	BandwidthBytesPerSecond int // 0 = unlimited
+}
+
+// StateFunc returns the state of the running service, or false when no
+// service is running (paused or restarting)
+type StateFunc func() (conduit.DashboardState, bool)
+
+type sample struct {
+	time      time.Time
+	totalUp   int64
+	totalDown int64
+	up, down  float64 // Bytes per second
+}
+
+// UI is the terminal UI. Create it with New, then call Run.
+type UI struct {
+	in    *os.File
+	out   *os.File
+	state StateFunc
+
+	commands chan Command
+	quit     func()
+
+	mu         sync.Mutex
+	events     []string
+	history    []sample
+	paused     bool
+	maxClients int
+	bandwidth  int
+	pending    bool // Limits changed but not applied yet
+	message    string
+}
+
+// New creates a UI drawing to out and reading keys from in. quit is called
+// when the user quits. maxClients and bandwidth are the initial limits.
+func New(in, out *os.File, state StateFunc, quit func(), maxClients, bandwidthBytesPerSecond int) *UI {
+	return &UI{
+		in:         in,
+		out:        out,
+		state:      state,
+		commands:   make(chan Command, 1),
+		quit:       quit,
+		maxClients: maxClients,
+		bandwidth:  bandwidthBytesPerSecond,
+	}
+}
+
+// Supported reports whether in and out are terminals
+func Supported(in, out *os.File) bool {
+	return term.IsTerminal(int(in.Fd())) && term.IsTerminal(int(out.Fd()))
+}
+
+// Commands returns the channel of user commands for the service loop
+func (u *UI) Commands() <-chan Command {
+	return u.commands
+}
+
+// Log adds a line to the events panel
+func (u *UI) Log(line string) {
+	line = strings.TrimSpace(line)
+	// Stats are shown by the UI itself
+	if line == "" || strings.Contains(line, "[STATS]") {
+		return
+	}
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.events = append(u.events, time.Now().Format("15:04:05")+" "+line)
+	if len(u.events) > maxEvents {
+		u.events = u.events[len(u.events)-maxEvents:]
+	}
+}
+
+// CaptureLines reads r line by line into the events panel until EOF
+func (u *UI) CaptureLines(r io.Reader) {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		u.Log(scanner.Text())
+	}
+}
+
+// Run draws the UI until ctx is done. The terminal is restored on return.
+func (u *UI) Run(ctx context.Context) error {
+	fd := int(u.in.Fd())
+	oldState, err := term.MakeRaw(fd)
+	if err != nil {
+		return fmt.Errorf("failed to set terminal to raw mode: %w", err)
+	}
+	defer term.Restore(fd, oldState)
+
+	// Alternate screen, hidden cursor
+	fmt.Fprint(u.out, "\x1b[?1049h\x1b[?25l")
+	defer fmt.Fprint(u.out, "\x1b[?25h\x1b[?1049l")
+
+	keys := make(chan byte)
+	go func() {
+		buf := make([]byte, 16)
+		for {
+			n, err := u.in.Read(buf)
+			if err != nil {
+				return
+			}
+			for _, b := range buf[:n] {
+				select {
+				case keys <- b:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	ticker := time.NewTicker(refresh)
+	defer ticker.Stop()
+	var apply <-chan time.Time
+
+	u.sample()
+	u.draw()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			u.sample()
+		case <-apply:
+			apply = nil
+			u.applyLimits()
+		case key := <-keys:
+			if u.handleKey(key) {
+				apply = time.After(applyDelay)
+			}
+		}
+		u.draw()
+	}
+}
+
+// handleKey handles a key press, returning true when limits changed
+func (u *UI) handleKey(key byte) bool {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	switch key {
+	case 'q', 'Q', 3: // 3 = Ctrl-C, which doesn't raise SIGINT in raw mode
+		u.message = "Shutting down..."
+		go u.quit()
+	case 'p', 'P', ' ':
+		u.paused = !u.paused
+		if u.paused {
+			u.message = "Paused: not accepting clients"
+			u.send(Command{Action: ActionPause})
+		} else {
+			u.message = "Resuming..."
+			u.send(Command{Action: ActionResume})
+		}
+	case '+', '=':
+		u.maxClients = min(u.maxClients+clientStep(u.maxClients), config.MaxClientsLimit)
+		return u.limitsChanged()
+	case '-', '_':
+		u.maxClients = max(u.maxClients-clientStep(u.maxClients-1), 1)
+		return u.limitsChanged()
+	case ']':
+		if u.bandwidth > 0 {
+			u.bandwidth += bandwidthStep
+		}
+		return u.limitsChanged()
+	case '[':
+		switch {
+		case u.bandwidth == 0:
+			u.bandwidth = int(config.DefaultBandwidthMbps * 1000 * 1000 / 8)
+		case u.bandwidth > bandwidthStep:
+			u.bandwidth -= bandwidthStep
+		}
+		return u.limitsChanged()
+	case 'u', 'U':
+		u.bandwidth = 0
+		return u.limitsChanged()
+	}
+	return false
+}
+
+// clientStep is the max clients increment: 1 up to 10, then 10
+func clientStep(n int) int {
+	if n < 10 {
+		return 1
+	}
+	return 10
+}
+
+// limitsChanged marks limits as pending. Must be called with lock held.
+func (u *UI) limitsChanged() bool {
+	u.pending = true
+	u.message = "Limits will be applied in a moment (restarts the proxy)"
+	return true
+}
+
+func (u *UI) applyLimits() {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	if !u.pending {
+		return
+	}
+	u.pending = false
+	u.message = fmt.Sprintf("Applying max clients %d, bandwidth %s", u.maxClients, formatBandwidth(u.bandwidth))
+	u.send(Command{Action: ActionSetLimits, MaxClients: u.maxClients, BandwidthBytesPerSecond: u.bandwidth})
+}
+
+// send queues a command, replacing one the service loop hasn't taken yet.
+// Must be called with lock held.
+func (u *UI) send(cmd Command) {
+	select {
+	case <-u.commands:
+	default:
+	}
+	u.commands <- cmd
+}
+
+// sample records throughput since the previous sample
+func (u *UI) sample() {
+	state, running := u.state()
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	now := time.Now()
+	s := sample{time: now}
+	if running {
+		s.totalUp, s.totalDown = state.Stats.TotalBytesUp, state.Stats.TotalBytesDown
+	}
+	if n := len(u.history); n > 0 {
+		last := u.history[n-1]
+		if elapsed := now.Sub(last.time).Seconds(); elapsed > 0 {
+			// Totals reset when the service restarts
+			s.up = float64(max(s.totalUp-last.totalUp, 0)) / elapsed
+			s.down = float64(max(s.totalDown-last.totalDown, 0)) / elapsed
+		}
+	}
+	u.history = append(u.history, s)
+	if len(u.history) > historySize {
+		u.history = u.history[len(u.history)-historySize:]
+	}
+}
+
+func (u *UI) draw() {
+	width, height, err := term.GetSize(int(u.out.Fd()))
+	if err != nil || width <= 0 || height <= 0 {
+		width, height = 80, 24
+	}
+	state, running := u.state()
+
+	u.mu.Lock()
+	lines := u.render(state, running, width, height)
+	u.mu.Unlock()
+
+	var b strings.Builder
+	b.WriteString("\x1b[H")
+	for i, line := range lines {
+		if i > 0 {
+			b.WriteString("\r\n")
+		}
+		b.WriteString(line)
+		b.WriteString("\x1b[K")
+	}
+	b.WriteString("\x1b[J")
+	io.WriteString(u.out, b.String())
+}
+
+// Text styles
+const (
+	styleBold  = "\x1b[1m"
+	styleDim   = "\x1b[2m"
+	styleRed   = "\x1b[31m"
+	styleGreen = "\x1b[32m"
+	stylePeach = "\x1b[38;2;245;160;134m"
+	styleMauve = "\x1b[38;2;157;129;201m"
+	styleReset = "\x1b[0m"
+)
+
+// line is one row of the screen: text segments with a style each
+type line []segment
+
+type segment struct {
+	style string
+	text  string
+}
+
+// format renders a line truncated to width columns
+func (l line) format(width int) string {
+	var b strings.Builder
+	for _, seg := range l {
+		if width <= 0 {
+			break
+		}
+		text := seg.text
+		if n := utf8.RuneCountInString(text); n > width {
+			text = string([]rune(text)[:width])
+		}
+		width -= utf8.RuneCountInString(text)
+		if seg.style != "" {
+			b.WriteString(seg.style + text + styleReset)
+		} else {
+			b.WriteString(text)
+		}
+	}
+	return b.String()
+}
+
+func plain(text string) segment           { return segment{text: text} }
+func styled(style, text string) segment   { return segment{style: style, text: text} }
+func textf(f string, args ...any) segment { return plain(fmt.Sprintf(f, args...)) }
+
+// render lays out the screen. Must be called with lock held.
+func (u *UI) render(state conduit.DashboardState, running bool, width, height int) []string {
+	stats := state.Stats
+	var lines []line
+
+	status := styled(styleRed, "● Connecting to broker")
+	switch {
+	case u.paused:
+		status = styled(styleDim, "❚❚ Paused")
+	case !running:
+		status = styled(styleDim, "○ Restarting")
+	case stats.IsLive:
+		status = styled(styleGreen, "● Live")
+	}
+	title := line{styled(styleBold+stylePeach, " Conduit")}
+	if stats.StationName != "" {
+		title = append(title, styled(styleBold, "  "+stats.StationName))
+	}
+	title = append(title, plain("   "), status)
+	if running {
+		title = append(title, textf("   up %s", formatDuration(time.Duration(stats.UptimeSeconds)*time.Second)))
+	}
+	lines = append(lines, title)
+	if stats.ProxyID != "" {
+		lines = append(lines, line{styled(styleDim, " Proxy ID "+stats.ProxyID)})
+	}
+	lines = append(lines, nil)
+
+	lines = append(lines,
+		line{textf(" Clients    connected %-5d connecting %-5d", stats.ConnectedClients, stats.ConnectingClients),
+			styled(styleDim, fmt.Sprintf("max %d", u.maxClients))},
+	)
+
+	sparkWidth := max(min(width-50, historySize), 10)
+	var ups, downs []float64
+	for _, s := range u.history {
+		ups = append(ups, s.up)
+		downs = append(downs, s.down)
+	}
+	var rateUp, rateDown float64
+	if n := len(u.history); n > 0 {
+		rateUp, rateDown = u.history[n-1].up, u.history[n-1].down
+	}
+	lines = append(lines,
+		line{textf(" Upload     %-12s ", formatRate(rateUp)), styled(stylePeach, sparkline(ups, sparkWidth)),
+			styled(styleDim, "  total "+formatBytes(stats.TotalBytesUp))},
+		line{textf(" Download   %-12s ", formatRate(rateDown)), styled(styleMauve, sparkline(downs, sparkWidth)),
+			styled(styleDim, "  total "+formatBytes(stats.TotalBytesDown))},
+		line{textf(" Bandwidth  %s", formatBandwidth(u.bandwidth))},
+		nil,
+	)
+
+	// Geo table, when enabled
+	if len(stats.Geo) > 0 {
+		lines = append(lines, line{styled(styleBold, fmt.Sprintf(" %-28s %5s %6s %10s %10s", "Country", "Now", "Total", "Up", "Down"))})
+		geoRows := max(min(len(stats.Geo), (height-len(lines))/3), 1)
+		for _, g := range sortGeo(stats.Geo)[:min(geoRows, len(stats.Geo))] {
+			lines = append(lines, line{textf(" %-28s %5d %6d %10s %10s",
+				truncate(g.Country, 28), g.Count, g.CountTotal, formatBytes(g.BytesUp), formatBytes(g.BytesDown))})
+		}
+		lines = append(lines, nil)
+	}
+
+	// Recent events fill the remaining rows above the help line
+	lines = append(lines, line{styled(styleBold, " Recent events")})
+	rows := height - len(lines) - 2
+	if rows > 0 {
+		events := u.events[max(len(u.events)-rows, 0):]
+		for _, e := range events {
+			style := ""
+			if strings.Contains(e, "[ERROR]") || strings.Contains(e, "WARNING") || strings.Contains(e, "[WARN]") {
+				style = styleRed
+			}
+			lines = append(lines, line{styled(style, " "+e)})
+		}
+		for i := len(events); i < rows; i++ {
+			lines = append(lines, nil)
+		}
+	}
+
+	help := line{styled(styleDim, " [p] pause/resume  [+/-] max clients  [ [/] ] bandwidth  [u] unlimited  [q] quit")}
+	if u.message != "" {
+		help = append(help, styled(stylePeach, "   "+u.message))
+	}
+	lines = append(lines, help)
+
+	if len(lines) > height {
+		lines = lines[:height]
+	}
+	out := make([]string, len(lines))
+	for i, l := range lines {
+		out[i] = l.format(width)
+	}
+	return out
+}
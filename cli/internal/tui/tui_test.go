@@ -0,0 +1,112 @@
+/*
+ * Copyright (c) 2026, Psiphon Inc.
+ * All rights reserved.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package tui
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Psiphon-Inc/conduit/cli/internal/conduit"
+	"github.com/Psiphon-Inc/conduit/cli/internal/geo"
+)
+
+func TestSparkline(t *testing.T) {
+	tests := []struct {
+		values   []float64
+		width    int
+		expected string
+	}{
+		{nil, 3, "   "},
+		{[]float64{0, 0}, 2, "▁▁"},
+		{[]float64{0, 7}, 4, "  ▁█"},
+		{[]float64{1, 2, 3, 4, 5, 6, 7, 8}, 2, "▇█"},
+	}
+	for _, tt := range tests {
+		if got := sparkline(tt.values, tt.width); got != tt.expected {
+			t.Fatalf("sparkline(%v, %d) = %q, expected %q", tt.values, tt.width, got, tt.expected)
+		}
+	}
+}
+
+func TestKeys(t *testing.T) {
+	u := New(nil, nil, nil, func() {}, 9, 0)
+
+	// Max clients steps by 1 below 10, then by 10
+	u.handleKey('+')
+	u.handleKey('+')
+	if u.maxClients != 20 {
+		t.Fatalf("maxClients = %d, expected 20", u.maxClients)
+	}
+	u.handleKey('-')
+	u.handleKey('-')
+	if u.maxClients != 9 {
+		t.Fatalf("maxClients = %d, expected 9", u.maxClients)
+	}
+
+	// '[' from unlimited switches to the default limit
+	u.handleKey('[')
+	if u.bandwidth != 5000000 {
+		t.Fatalf("bandwidth = %d, expected the 40 Mbps default", u.bandwidth)
+	}
+
+	u.applyLimits()
+	cmd := <-u.Commands()
+	if cmd.Action != ActionSetLimits || cmd.MaxClients != 9 || cmd.BandwidthBytesPerSecond != 5000000 {
+		t.Fatalf("unexpected command %+v", cmd)
+	}
+
+	// Pausing replaces a command the service loop hasn't taken yet
+	u.handleKey('p')
+	u.handleKey('p')
+	if cmd := <-u.Commands(); cmd.Action != ActionResume {
+		t.Fatalf("unexpected command %+v", cmd)
+	}
+	select {
+	case cmd := <-u.Commands():
+		t.Fatalf("unexpected extra command %+v", cmd)
+	default:
+	}
+}
+
+func TestRender(t *testing.T) {
+	u := New(nil, nil, nil, func() {}, 50, 0)
+	u.Log("[OK] Connected to Psiphon network")
+	u.Log("12:00:00 [STATS] Connecting: 1 | Connected: 2")
+
+	state := conduit.DashboardState{Stats: conduit.StatsJSON{
+		StationName:      "berlin-1",
+		ConnectedClients: 2,
+		IsLive:           true,
+		Geo:              []geo.Result{{Code: "IR", Country: "Iran", Count: 2, CountTotal: 5}},
+	}}
+	screen := strings.Join(u.render(state, true, 100, 30), "\n")
+
+	for _, want := range []string{"berlin-1", "Live", "connected 2", "max 50", "unlimited", "Iran", "Connected to Psiphon network"} {
+		if !strings.Contains(screen, want) {
+			t.Fatalf("screen is missing %q:\n%s", want, screen)
+		}
+	}
+	if strings.Contains(screen, "[STATS]") {
+		t.Fatalf("stats log lines should not be shown as events")
+	}
+	if lines := u.render(state, true, 100, 10); len(lines) > 10 {
+		t.Fatalf("rendered %d lines on a 10 line screen", len(lines))
+	}
+}
@@ -0,0 +1,116 @@
+/*
+ * Copyright (c) 2026, Psiphon Inc.
+ * All rights reserved.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package update
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// Executable returns the path of the running binary, with symlinks resolved
+// so the binary itself is replaced
+func Executable() (string, error) {
+	path, err := os.Executable()
+	if err != nil {
+		return "", err
+	}
+	return filepath.EvalSymlinks(path)
+}
+
+// Install atomically replaces the binary at path, keeping its mode. The new
+// binary must run and report the expected version first.
+func Install(binary []byte, path, expectedVersion string) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	pattern := ".conduit-update-*"
+	if runtime.GOOS == "windows" {
+		pattern += ".exe"
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), pattern)
+	if err != nil {
+		return fmt.Errorf("failed to replace %s: %w", path, err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(binary); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), info.Mode().Perm()|0100); err != nil {
+		return err
+	}
+	if err := checkBinary(tmp.Name(), expectedVersion); err != nil {
+		return err
+	}
+
+	// Windows can't replace a running binary, but it can rename it
+	if runtime.GOOS == "windows" {
+		old := path + ".old"
+		os.Remove(old)
+		if err := os.Rename(path, old); err != nil {
+			return fmt.Errorf("failed to replace %s: %w", path, err)
+		}
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to replace %s: %w", path, err)
+	}
+	return nil
+}
+
+// checkBinary runs 'binary version' to catch a broken or wrong binary before
+// it replaces a working one
+func checkBinary(path, expectedVersion string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	out, err := exec.CommandContext(ctx, path, "version").Output()
+	if err != nil {
+		return fmt.Errorf("downloaded binary does not run: %w", err)
+	}
+	if got := strings.TrimSpace(string(out)); got != "conduit "+expectedVersion {
+		return fmt.Errorf("downloaded binary reports %q, expected version %s", got, expectedVersion)
+	}
+	return nil
+}
+
+// Writable checks that the binary at path can be replaced, so auto-update
+// fails at start rather than at every check
+func Writable(path string) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".conduit-update-*")
+	if err != nil {
+		return fmt.Errorf("cannot replace %s: %w", path, err)
+	}
+	tmp.Close()
+	return os.Remove(tmp.Name())
+}
@@ -0,0 +1,237 @@
+/*
+ * Copyright (c) 2026, Psiphon Inc.
+ * All rights reserved.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+// Package update installs new conduit releases. Releases are found in a
+// GitHub-style release feed; the release's checksums.txt must be signed with
+// the pinned Ed25519 key, and the downloaded binary must match its checksum.
+package update
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/Psiphon-Inc/conduit/cli/internal/crypto"
+)
+
+const (
+	// DefaultFeedURL lists the project's releases. CLI and app releases
+	// share the feed, so ask for the largest page GitHub serves.
+	DefaultFeedURL = "https://api.github.com/repos/Psiphon-Inc/conduit/releases?per_page=100"
+
+	// DefaultInterval is how often auto-update checks for a release
+	DefaultInterval = 24 * time.Hour
+
+	// DefaultDrain is how long auto-update waits for clients to disconnect
+	// before restarting
+	DefaultDrain = 10 * time.Minute
+
+	// TagPrefix marks CLI releases; the repository also has app releases
+	TagPrefix = "release-cli-"
+
+	checksumsName   = "checksums.txt"
+	signatureName   = "checksums.txt.sig"
+	maxBinarySize   = 256 * 1024 * 1024
+	maxMetadataSize = 4 * 1024 * 1024
+	maxFeedSize     = 16 * 1024 * 1024
+	downloadTimeout = 10 * time.Minute
+)
+
+// Options configures an Updater
+type Options struct {
+	FeedURL   string
+	PublicKey ed25519.PublicKey
+	GOOS      string       // Default: runtime.GOOS
+	GOARCH    string       // Default: runtime.GOARCH
+	Client    *http.Client // Optional
+}
+
+// Updater finds, downloads and verifies releases
+type Updater struct {
+	opts Options
+}
+
+// Release is a CLI release in the feed
+type Release struct {
+	Version string
+	Tag     string
+	Assets  map[string]string // name -> download URL
+}
+
+// feedRelease is a release in the GitHub releases API
+type feedRelease struct {
+	TagName    string `json:"tag_name"`
+	Draft      bool   `json:"draft"`
+	Prerelease bool   `json:"prerelease"`
+	Assets     []struct {
+		Name string `json:"name"`
+		URL  string `json:"browser_download_url"`
+	} `json:"assets"`
+}
+
+// New returns an Updater for opts
+func New(opts Options) (*Updater, error) {
+	if len(opts.PublicKey) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("no release signing key: this build has none pinned, use --release-key")
+	}
+	if opts.FeedURL == "" {
+		opts.FeedURL = DefaultFeedURL
+	}
+	if opts.GOOS == "" {
+		opts.GOOS = runtime.GOOS
+	}
+	if opts.GOARCH == "" {
+		opts.GOARCH = runtime.GOARCH
+	}
+	if opts.Client == nil {
+		opts.Client = &http.Client{Timeout: downloadTimeout}
+	}
+	return &Updater{opts: opts}, nil
+}
+
+// AssetName returns the release artifact for a platform, as built by the
+// Makefile
+func AssetName(goos, goarch string) string {
+	name := "conduit-" + goos + "-" + goarch
+	if goos == "windows" {
+		name += ".exe"
+	}
+	return name
+}
+
+// Latest returns the newest CLI release in the feed, skipping drafts and
+// prereleases
+func (u *Updater) Latest(ctx context.Context) (*Release, error) {
+	data, err := u.get(ctx, u.opts.FeedURL, maxFeedSize)
+	if err != nil {
+		return nil, err
+	}
+	var feed []feedRelease
+	if err := json.Unmarshal(data, &feed); err != nil {
+		return nil, fmt.Errorf("invalid release feed %s: %w", u.opts.FeedURL, err)
+	}
+
+	var latest *Release
+	for _, r := range feed {
+		if r.Draft || r.Prerelease || !strings.HasPrefix(r.TagName, TagPrefix) {
+			continue
+		}
+		version := strings.TrimPrefix(r.TagName, TagPrefix)
+		if _, ok := parseVersion(version); !ok {
+			continue
+		}
+		if latest != nil && !Newer(version, latest.Version) {
+			continue
+		}
+		latest = &Release{Version: version, Tag: r.TagName, Assets: map[string]string{}}
+		for _, asset := range r.Assets {
+			latest.Assets[asset.Name] = asset.URL
+		}
+	}
+	if latest == nil {
+		return nil, fmt.Errorf("no CLI release in %s", u.opts.FeedURL)
+	}
+	return latest, nil
+}
+
+// Download fetches the release binary for this platform. The release's
+// checksums must verify against the pinned key and include the binary.
+func (u *Updater) Download(ctx context.Context, r *Release) ([]byte, error) {
+	name := AssetName(u.opts.GOOS, u.opts.GOARCH)
+	binaryURL, ok := r.Assets[name]
+	if !ok {
+		return nil, fmt.Errorf("release %s has no %s", r.Version, name)
+	}
+	for _, asset := range []string{checksumsName, signatureName} {
+		if _, ok := r.Assets[asset]; !ok {
+			return nil, fmt.Errorf("release %s has no %s", r.Version, asset)
+		}
+	}
+
+	checksums, err := u.get(ctx, r.Assets[checksumsName], maxMetadataSize)
+	if err != nil {
+		return nil, err
+	}
+	signature, err := u.get(ctx, r.Assets[signatureName], maxMetadataSize)
+	if err != nil {
+		return nil, err
+	}
+	if err := crypto.VerifySignature(u.opts.PublicKey, checksums, signature); err != nil {
+		return nil, fmt.Errorf("release %s: %s: %w", r.Version, checksumsName, err)
+	}
+	expected, ok := parseChecksums(checksums)[name]
+	if !ok {
+		return nil, fmt.Errorf("release %s: %s has no checksum for %s", r.Version, checksumsName, name)
+	}
+
+	binary, err := u.get(ctx, binaryURL, maxBinarySize)
+	if err != nil {
+		return nil, err
+	}
+	sum := sha256.Sum256(binary)
+	if hex.EncodeToString(sum[:]) != expected {
+		return nil, fmt.Errorf("release %s: %s does not match its checksum", r.Version, name)
+	}
+	return binary, nil
+}
+
+// parseChecksums reads sha256sum output
+func parseChecksums(data []byte) map[string]string {
+	sums := map[string]string{}
+	for _, line := range strings.Split(string(data), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			continue
+		}
+		sums[strings.TrimPrefix(fields[1], "*")] = strings.ToLower(fields[0])
+	}
+	return sums
+}
+
+func (u *Updater) get(ctx context.Context, rawURL string, limit int64) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := u.opts.Client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch %s: %w", rawURL, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch %s: %s", rawURL, resp.Status)
+	}
+	var buf bytes.Buffer
+	if _, err := io.Copy(&buf, io.LimitReader(resp.Body, limit+1)); err != nil {
+		return nil, fmt.Errorf("failed to fetch %s: %w", rawURL, err)
+	}
+	if int64(buf.Len()) > limit {
+		return nil, fmt.Errorf("failed to fetch %s: larger than %d bytes", rawURL, limit)
+	}
+	return buf.Bytes(), nil
+}
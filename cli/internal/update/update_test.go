@@ -0,0 +1,238 @@
+/*
+ * Copyright (c) 2026, Psiphon Inc.
+ * All rights reserved.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package update
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// fakeBinary is a script that answers 'version' like conduit does
+func fakeBinary(version string) []byte {
+	return []byte("#!/bin/sh\necho conduit " + version + "\n")
+}
+
+// releaseServer serves a GitHub-style release feed and its assets
+type releaseServer struct {
+	*httptest.Server
+	key    ed25519.PrivateKey
+	files  map[string][]byte
+	tags   []string
+	assets map[string][]string // tag -> asset names
+}
+
+func newReleaseServer(t *testing.T, key ed25519.PrivateKey, version string, binary []byte) *releaseServer {
+	rs := &releaseServer{key: key, files: map[string][]byte{}, assets: map[string][]string{}}
+	rs.Server = httptest.NewServer(rs)
+	t.Cleanup(rs.Close)
+
+	name := AssetName(runtime.GOOS, runtime.GOARCH)
+	tag := TagPrefix + version
+	sum := sha256.Sum256(binary)
+	checksums := []byte(fmt.Sprintf("%s  conduit-other-os\n%s  %s\n", strings.Repeat("0", 64), hex.EncodeToString(sum[:]), name))
+	rs.add(tag, name, binary)
+	rs.add(tag, checksumsName, checksums)
+	rs.add(tag, signatureName, []byte(base64.StdEncoding.EncodeToString(ed25519.Sign(key, checksums))+"\n"))
+	return rs
+}
+
+func (rs *releaseServer) add(tag, name string, data []byte) {
+	if _, ok := rs.assets[tag]; !ok {
+		rs.tags = append(rs.tags, tag)
+	}
+	rs.assets[tag] = append(rs.assets[tag], name)
+	rs.files["/download/"+tag+"/"+name] = data
+}
+
+func (rs *releaseServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path == "/releases" {
+		feed := []map[string]any{
+			{"tag_name": "release-app-9.0.0", "assets": []any{}},
+			{"tag_name": TagPrefix + "9.9.9", "prerelease": true, "assets": []any{}},
+			{"tag_name": TagPrefix + "9.9.8", "draft": true, "assets": []any{}},
+			{"tag_name": TagPrefix + "1.0.0", "assets": []any{}},
+		}
+		for _, tag := range rs.tags {
+			var assets []map[string]string
+			for _, name := range rs.assets[tag] {
+				assets = append(assets, map[string]string{"name": name, "browser_download_url": rs.URL + "/download/" + tag + "/" + name})
+			}
+			feed = append(feed, map[string]any{"tag_name": tag, "assets": assets})
+		}
+		json.NewEncoder(w).Encode(feed)
+		return
+	}
+	data, ok := rs.files[r.URL.Path]
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	w.Write(data)
+}
+
+func newTestUpdater(t *testing.T, rs *releaseServer, key ed25519.PublicKey) *Updater {
+	u, err := New(Options{FeedURL: rs.URL + "/releases", PublicKey: key})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	return u
+}
+
+func TestLatest(t *testing.T) {
+	public, private, _ := ed25519.GenerateKey(nil)
+	rs := newReleaseServer(t, private, "1.3.0", fakeBinary("1.3.0"))
+	rs.add(TagPrefix+"1.2.5", "conduit-linux-amd64", nil)
+
+	release, err := newTestUpdater(t, rs, public).Latest(context.Background())
+	if err != nil || release.Version != "1.3.0" || len(release.Assets) != 3 {
+		t.Fatalf("Latest = %+v, %v", release, err)
+	}
+}
+
+func TestDownload(t *testing.T) {
+	public, private, _ := ed25519.GenerateKey(nil)
+	_, other, _ := ed25519.GenerateKey(nil)
+	binary := fakeBinary("1.3.0")
+	name := AssetName(runtime.GOOS, runtime.GOARCH)
+	tag := TagPrefix + "1.3.0"
+
+	tests := []struct {
+		name   string
+		modify func(rs *releaseServer)
+		valid  bool
+	}{
+		{"valid", func(rs *releaseServer) {}, true},
+		{"signed by another key", func(rs *releaseServer) {
+			rs.files["/download/"+tag+"/"+signatureName] = ed25519.Sign(other, rs.files["/download/"+tag+"/"+checksumsName])
+		}, false},
+		{"tampered binary", func(rs *releaseServer) {
+			rs.files["/download/"+tag+"/"+name] = fakeBinary("6.6.6")
+		}, false},
+		{"tampered checksums", func(rs *releaseServer) {
+			rs.files["/download/"+tag+"/"+checksumsName] = []byte(strings.Repeat("0", 64) + "  " + name + "\n")
+		}, false},
+		{"unsigned", func(rs *releaseServer) {
+			delete(rs.files, "/download/"+tag+"/"+signatureName)
+		}, false},
+	}
+	for _, tt := range tests {
+		rs := newReleaseServer(t, private, "1.3.0", binary)
+		tt.modify(rs)
+		u := newTestUpdater(t, rs, public)
+		release, err := u.Latest(context.Background())
+		if err != nil {
+			t.Fatalf("%s: Latest: %v", tt.name, err)
+		}
+		got, err := u.Download(context.Background(), release)
+		if tt.valid && (err != nil || string(got) != string(binary)) {
+			t.Fatalf("%s: Download = %q, %v", tt.name, got, err)
+		}
+		if !tt.valid && err == nil {
+			t.Fatalf("%s: Download succeeded", tt.name)
+		}
+	}
+
+	// No artifact for this platform
+	u, _ := New(Options{FeedURL: newReleaseServer(t, private, "1.3.0", binary).URL + "/releases", PublicKey: public, GOOS: "plan9"})
+	release, _ := u.Latest(context.Background())
+	if _, err := u.Download(context.Background(), release); err == nil {
+		t.Fatalf("Download for another platform succeeded")
+	}
+}
+
+func TestApply(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the fake binary is a shell script")
+	}
+	public, private, _ := ed25519.GenerateKey(nil)
+	path := filepath.Join(t.TempDir(), "conduit")
+	if err := os.WriteFile(path, fakeBinary("1.2.0"), 0750); err != nil {
+		t.Fatalf("write binary: %v", err)
+	}
+
+	// A binary that doesn't report the release's version is not installed
+	u := newTestUpdater(t, newReleaseServer(t, private, "1.3.0", fakeBinary("1.2.9")), public)
+	if _, err := u.Apply(context.Background(), "1.2.0", path); err == nil {
+		t.Fatalf("Apply installed a binary with the wrong version")
+	}
+	if data, _ := os.ReadFile(path); string(data) != string(fakeBinary("1.2.0")) {
+		t.Fatalf("binary replaced after a failed update")
+	}
+
+	u = newTestUpdater(t, newReleaseServer(t, private, "1.3.0", fakeBinary("1.3.0")), public)
+	if version, err := u.Apply(context.Background(), "1.3.0", path); version != "" || err != nil {
+		t.Fatalf("Apply when up to date = %q, %v", version, err)
+	}
+	version, err := u.Apply(context.Background(), "1.2.0", path)
+	if version != "1.3.0" || err != nil {
+		t.Fatalf("Apply = %q, %v", version, err)
+	}
+	info, err := os.Stat(path)
+	if err != nil || info.Mode().Perm() != 0750 {
+		t.Fatalf("installed binary: %v, %v", info, err)
+	}
+	if data, _ := os.ReadFile(path); string(data) != string(fakeBinary("1.3.0")) {
+		t.Fatalf("binary not replaced")
+	}
+	if entries, _ := os.ReadDir(filepath.Dir(path)); len(entries) != 1 {
+		t.Fatalf("temporary files left behind: %v", entries)
+	}
+}
+
+func TestDrain(t *testing.T) {
+	var clients atomic.Int32
+	clients.Store(2)
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		clients.Store(0)
+	}()
+	start := time.Now()
+	Drain(context.Background(), func() int { return int(clients.Load()) }, time.Minute, time.Millisecond)
+	if time.Since(start) > 10*time.Second {
+		t.Fatalf("Drain didn't return when clients disconnected")
+	}
+
+	clients.Store(1)
+	start = time.Now()
+	Drain(context.Background(), func() int { return int(clients.Load()) }, 20*time.Millisecond, time.Millisecond)
+	if time.Since(start) > 10*time.Second {
+		t.Fatalf("Drain didn't time out")
+	}
+}
+
+func TestNewRequiresKey(t *testing.T) {
+	if _, err := New(Options{}); err == nil {
+		t.Fatalf("New without a key succeeded")
+	}
+}
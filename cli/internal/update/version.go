@@ -0,0 +1,109 @@
+/*
+ * Copyright (c) 2026, Psiphon Inc.
+ * All rights reserved.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package update
+
+import (
+	"strconv"
+	"strings"
+)
+
+// version is a parsed release version: numeric parts and an optional
+// prerelease suffix, e.g. 1.4.0-rc1
+type version struct {
+	parts  []int
+	suffix string
+}
+
+func parseVersion(s string) (version, bool) {
+	s = strings.TrimPrefix(strings.TrimPrefix(s, TagPrefix), "v")
+	core, suffix, _ := strings.Cut(s, "-")
+	if core == "" {
+		return version{}, false
+	}
+	var v version
+	for _, part := range strings.Split(core, ".") {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return version{}, false
+		}
+		v.parts = append(v.parts, n)
+	}
+	v.suffix = suffix
+	return v, true
+}
+
+// IsRelease reports whether a version is a release version. Development
+// builds ("dev", git describe output like 1.2.0-3-gabc1234, or -dirty)
+// are not.
+func IsRelease(s string) bool {
+	v, ok := parseVersion(s)
+	if !ok {
+		return false
+	}
+	if strings.HasSuffix(v.suffix, "dirty") {
+		return false
+	}
+	fields := strings.Split(v.suffix, "-")
+	last := fields[len(fields)-1]
+	return !(len(fields) >= 2 && strings.HasPrefix(last, "g") && isHex(last[1:]))
+}
+
+// Newer reports whether version a is newer than b. A prerelease is older
+// than its release.
+func Newer(a, b string) bool {
+	va, okA := parseVersion(a)
+	vb, okB := parseVersion(b)
+	if !okA || !okB {
+		return okA && !okB
+	}
+	for i := 0; i < len(va.parts) || i < len(vb.parts); i++ {
+		var x, y int
+		if i < len(va.parts) {
+			x = va.parts[i]
+		}
+		if i < len(vb.parts) {
+			y = vb.parts[i]
+		}
+		if x != y {
+			return x > y
+		}
+	}
+	switch {
+	case va.suffix == vb.suffix:
+		return false
+	case va.suffix == "":
+		return true
+	case vb.suffix == "":
+		return false
+	}
+	return va.suffix > vb.suffix
+}
+
+func isHex(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, c := range s {
+		if !strings.ContainsRune("0123456789abcdef", c) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,69 @@
+/*
+ * Copyright (c) 2026, Psiphon Inc.
+ * All rights reserved.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package update
+
+import "testing"
+
+func TestNewer(t *testing.T) {
+	tests := []struct {
+		a, b     string
+		expected bool
+	}{
+		{"1.3.0", "1.2.0", true},
+		{"1.2.0", "1.3.0", false},
+		{"1.10.0", "1.9.0", true},
+		{"1.2.0", "1.2.0", false},
+		{"1.2.1", "1.2", true},
+		{"1.2", "1.2.0", false},
+		{"release-cli-1.3.0", "1.2.0", true},
+		{"v2.0.0", "1.9.9", true},
+		{"1.3.0", "1.3.0-rc1", true},
+		{"1.3.0-rc1", "1.3.0", false},
+		{"1.3.0-rc2", "1.3.0-rc1", true},
+		{"1.0.0", "dev", true},
+		{"dev", "1.0.0", false},
+	}
+	for _, tt := range tests {
+		if got := Newer(tt.a, tt.b); got != tt.expected {
+			t.Fatalf("Newer(%q, %q) = %v, expected %v", tt.a, tt.b, got, tt.expected)
+		}
+	}
+}
+
+func TestIsRelease(t *testing.T) {
+	tests := []struct {
+		version  string
+		expected bool
+	}{
+		{"1.2.0", true},
+		{"1.3.0-rc1", true},
+		{"dev", false},
+		{"", false},
+		{"abc1234", false},
+		{"release-cli-1.2.0-3-gabc1234", false},
+		{"1.2.0-dirty", false},
+		{"experimental-pr42", false},
+	}
+	for _, tt := range tests {
+		if got := IsRelease(tt.version); got != tt.expected {
+			t.Fatalf("IsRelease(%q) = %v, expected %v", tt.version, got, tt.expected)
+		}
+	}
+}
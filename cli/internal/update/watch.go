@@ -0,0 +1,93 @@
+/*
+ * Copyright (c) 2026, Psiphon Inc.
+ * All rights reserved.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package update
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Watch checks for a newer release every interval, and whenever trigger
+// receives, and installs it to path. The installed version is sent on the
+// returned channel, after which Watch stops.
+func (u *Updater) Watch(ctx context.Context, current, path string, interval time.Duration, trigger <-chan struct{}) <-chan string {
+	installed := make(chan string, 1)
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			case <-trigger:
+			}
+			version, err := u.Apply(ctx, current, path)
+			if err != nil {
+				if ctx.Err() == nil {
+					fmt.Printf("[WARN] Auto-update failed: %v\n", err)
+				}
+				continue
+			}
+			if version != "" {
+				installed <- version
+				return
+			}
+		}
+	}()
+	return installed
+}
+
+// Apply installs the latest release to path if it is newer than current,
+// and returns its version, or "" when current is up to date
+func (u *Updater) Apply(ctx context.Context, current, path string) (string, error) {
+	release, err := u.Latest(ctx)
+	if err != nil {
+		return "", err
+	}
+	if !Newer(release.Version, current) {
+		return "", nil
+	}
+	binary, err := u.Download(ctx, release)
+	if err != nil {
+		return "", err
+	}
+	if err := Install(binary, path, release.Version); err != nil {
+		return "", err
+	}
+	return release.Version, nil
+}
+
+// Drain waits until clients returns 0, timeout passes or ctx is cancelled,
+// so a restart doesn't cut off connected clients
+func Drain(ctx context.Context, clients func() int, timeout, poll time.Duration) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	ticker := time.NewTicker(poll)
+	defer ticker.Stop()
+	for clients() > 0 {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}